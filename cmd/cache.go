@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/transfer/cache"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheArgs struct {
+		dir     string
+		maxSize int64
+	}
+
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage local download cache",
+	}
+
+	cacheListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List files in local download cache",
+		Run:   listCache,
+	}
+
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Evict least recently used files from local download cache",
+		Run:   pruneCache,
+	}
+
+	cacheVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify merkle root of files in local download cache and remove the corrupted ones",
+		Run:   verifyCache,
+	}
+)
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheArgs.dir, "dir", "", "Local download cache directory")
+	cacheCmd.MarkPersistentFlagRequired("dir")
+
+	cachePruneCmd.Flags().Int64Var(&cacheArgs.maxSize, "max-size", 0, "Maximum size in bytes to retain, 0 to remove all files")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func mustOpenCache() *cache.Cache {
+	c, err := cache.New(cacheArgs.dir, 0, common.LogOption{Logger: logrus.StandardLogger()})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open local download cache")
+	}
+
+	return c
+}
+
+func printCacheEntries(entries []cache.Entry) {
+	var total int64
+
+	for _, entry := range entries {
+		fmt.Printf("%v\t%v\t%v\n", entry.Root.Hex(), entry.Size, entry.LastAccess.Format(time.RFC3339))
+		total += entry.Size
+	}
+
+	fmt.Printf("%v file(s), %v bytes in total\n", len(entries), total)
+}
+
+func listCache(*cobra.Command, []string) {
+	entries, err := mustOpenCache().List()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to list local download cache")
+	}
+
+	printCacheEntries(entries)
+}
+
+func pruneCache(*cobra.Command, []string) {
+	evicted, err := mustOpenCache().Prune(cacheArgs.maxSize)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to prune local download cache")
+	}
+
+	printCacheEntries(evicted)
+}
+
+func verifyCache(*cobra.Command, []string) {
+	corrupted, err := mustOpenCache().Verify()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to verify local download cache")
+	}
+
+	printCacheEntries(corrupted)
+}
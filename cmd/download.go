@@ -9,6 +9,7 @@ import (
 	"github.com/0glabs/0g-storage-client/indexer"
 	"github.com/0glabs/0g-storage-client/node"
 	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/0glabs/0g-storage-client/transfer/cache"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -26,6 +27,9 @@ type downloadArgument struct {
 
 	routines int
 
+	cacheDir  string
+	cacheSize int64
+
 	timeout time.Duration
 }
 
@@ -46,6 +50,9 @@ func bindDownloadFlags(cmd *cobra.Command, args *downloadArgument) {
 
 	cmd.Flags().IntVar(&args.routines, "routines", runtime.GOMAXPROCS(0), "number of go routines for downloading simultaneously")
 
+	cmd.Flags().StringVar(&args.cacheDir, "cache-dir", "", "Local download cache directory, which is shared across processes and disabled by default")
+	cmd.Flags().Int64Var(&args.cacheSize, "cache-size", 0, "Maximum size in bytes of local download cache, 0 for unlimited")
+
 	cmd.Flags().DurationVar(&args.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
 }
 
@@ -91,10 +98,19 @@ func download(*cobra.Command, []string) {
 }
 
 func newDownloader(args downloadArgument) (transfer.IDownloader, func(), error) {
+	var downloadCache *cache.Cache
+	if args.cacheDir != "" {
+		var err error
+		if downloadCache, err = cache.New(args.cacheDir, args.cacheSize, common.LogOption{Logger: logrus.StandardLogger()}); err != nil {
+			return nil, nil, errors.WithMessage(err, "failed to open local download cache")
+		}
+	}
+
 	if args.indexer != "" {
 		indexerClient, err := indexer.NewClient(args.indexer, indexer.IndexerClientOption{
 			ProviderOption: providerOption,
 			LogOption:      common.LogOption{Logger: logrus.StandardLogger()},
+			Cache:          downloadCache,
 		})
 		if err != nil {
 			return nil, nil, errors.WithMessage(err, "failed to initialize indexer client")
@@ -115,7 +131,7 @@ func newDownloader(args downloadArgument) (transfer.IDownloader, func(), error)
 		closer()
 		return nil, nil, err
 	}
-	downloader.WithRoutines(downloadArgs.routines)
+	downloader.WithRoutines(args.routines).WithCache(downloadCache)
 
 	return downloader, closer, nil
 }
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"io"
+	"os"
 
 	"github.com/0glabs/0g-storage-client/transfer"
 	"github.com/sirupsen/logrus"
@@ -9,7 +11,8 @@ import (
 )
 
 var (
-	downloadDirArgs downloadArgument
+	downloadDirArgs    downloadArgument
+	downloadDirArchive string
 
 	downloadDirCmd = &cobra.Command{
 		Use:   "download-dir",
@@ -20,6 +23,7 @@ var (
 
 func init() {
 	bindDownloadFlags(downloadDirCmd, &downloadDirArgs)
+	downloadDirCmd.Flags().StringVar(&downloadDirArchive, "archive", "", "Export directory as archive (tar|zip) to file instead, and \"-\" for stdout")
 
 	rootCmd.AddCommand(downloadDirCmd)
 }
@@ -38,9 +42,36 @@ func downloadDir(*cobra.Command, []string) {
 	}
 	defer closer()
 
+	if downloadDirArchive != "" {
+		exportDir(ctx, downloader)
+		return
+	}
+
 	// Download the entire directory structure.
 	err = transfer.DownloadDir(ctx, downloader, downloadDirArgs.root, downloadDirArgs.file, downloadDirArgs.proof)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to download folder")
 	}
 }
+
+func exportDir(ctx context.Context, downloader transfer.IDownloader) {
+	format, err := transfer.ParseArchiveFormat(downloadDirArchive)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid archive format")
+	}
+
+	var w io.Writer = os.Stdout
+	if downloadDirArgs.file != "-" {
+		file, err := os.Create(downloadDirArgs.file)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create archive file")
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if err = transfer.ExportDir(ctx, downloader, downloadDirArgs.root, format, w); err != nil {
+		logrus.WithError(err).Fatal("Failed to export folder")
+	}
+}
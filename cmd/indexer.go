@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/0glabs/0g-storage-client/common/rpc"
 	"github.com/0glabs/0g-storage-client/indexer"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 	"github.com/0glabs/0g-storage-client/indexer/gateway"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -15,8 +23,13 @@ var (
 		endpoint            string
 		nodes               indexer.NodeManagerConfig
 		locations           indexer.IPLocationConfig
+		trustedProxies      []string
 		locationCache       indexer.FileLocationCacheConfig
 		maxDownloadFileSize uint64
+		metrics             bool
+
+		catalog     catalog.Config
+		catalogFlow string
 	}
 
 	indexerCmd = &cobra.Command{
@@ -35,15 +48,34 @@ func init() {
 	indexerCmd.Flags().DurationVar(&indexerArgs.nodes.UpdateInterval, "update-interval", 10*time.Minute, "Interval to update shard config of discovered peers")
 
 	indexerCmd.Flags().IntSliceVar(&indexerArgs.nodes.DiscoveryPorts, "discover-ports", []int{5678}, "Ports to try for discovered nodes")
+	indexerCmd.Flags().DurationVar(&indexerArgs.nodes.ProbeInterval, "probe-interval", time.Minute, "Interval to probe trusted nodes")
+	indexerCmd.Flags().DurationVar(&indexerArgs.nodes.QuarantineMin, "quarantine-min", time.Minute, "Min duration to quarantine failed nodes, which is doubled for each consecutive failure")
+	indexerCmd.Flags().DurationVar(&indexerArgs.nodes.QuarantineMax, "quarantine-max", time.Hour, "Max duration to quarantine failed nodes")
+	indexerCmd.Flags().IntVar(&indexerArgs.nodes.MaxFailures, "max-failures", 10, "Max number of consecutive failures before discovered peer removed")
+	indexerCmd.Flags().StringVar(&indexerArgs.nodes.CacheFile, "node-cache-file", ".node-cache.json", "File name to persist discovered peers, empty to disable")
+	indexerCmd.Flags().DurationVar(&indexerArgs.nodes.CacheWriteInterval, "node-cache-interval", 10*time.Minute, "Interval to write discovered peers to cache file")
 
 	indexerCmd.Flags().StringVar(&indexerArgs.locations.CacheFile, "ip-location-cache-file", ".ip-location-cache.json", "File name to cache IP locations")
 	indexerCmd.Flags().DurationVar(&indexerArgs.locations.CacheWriteInterval, "ip-location-cache-interval", 10*time.Minute, "Interval to write ip locations to cache file")
 	indexerCmd.Flags().StringVar(&indexerArgs.locations.AccessToken, "ip-location-token", "", "Access token to retrieve IP location from ipinfo.io")
+	indexerCmd.Flags().DurationVar(&indexerArgs.locations.QueryTimeout, "ip-location-timeout", 5*time.Second, "Timeout to retrieve IP location from ipinfo.io")
+	indexerCmd.Flags().StringSliceVar(&indexerArgs.trustedProxies, "trusted-proxies", nil, "IPs or CIDRs of reverse proxies whose forwarded headers are honoured to locate RPC callers")
 
 	indexerCmd.Flags().DurationVar(&indexerArgs.locationCache.Expiry, "file-location-cache-expiry", 24*time.Hour, "Validity period of location information")
 	indexerCmd.Flags().IntVar(&indexerArgs.locationCache.CacheSize, "file-location-cache-size", 100000, "size of file location cache")
+	indexerCmd.Flags().StringVar(&indexerArgs.locationCache.CacheFile, "file-location-cache-file", ".file-location-cache.json", "File name to persist file locations, empty to disable")
+	indexerCmd.Flags().DurationVar(&indexerArgs.locationCache.CacheWriteInterval, "file-location-cache-interval", 10*time.Minute, "Interval to write file locations to cache file")
 
 	indexerCmd.Flags().Uint64Var(&indexerArgs.maxDownloadFileSize, "max-download-file-size", 100*1024*1024, "Maximum file size in bytes to download")
+	indexerCmd.Flags().BoolVar(&indexerArgs.metrics, "metrics", false, "Whether to serve prometheus metrics at /metrics")
+
+	indexerCmd.Flags().StringVar(&indexerArgs.catalog.DBPath, "catalog-db", "", "Database directory of file catalog that follows files submitted on chain, empty to disable")
+	indexerCmd.Flags().StringVar(&indexerArgs.catalog.BlockchainURL, "catalog-blockchain", "", "Fullnode URL to follow files submitted on chain")
+	indexerCmd.Flags().StringVar(&indexerArgs.catalogFlow, "catalog-flow", "", "Flow contract address to follow files submitted, retrieved from trusted nodes by default")
+	indexerCmd.Flags().Uint64Var(&indexerArgs.catalog.StartBlock, "catalog-start-block", 0, "Block number to follow files submitted on chain, e.g. the flow contract deployed")
+	indexerCmd.Flags().Uint64Var(&indexerArgs.catalog.BatchSize, "catalog-batch-size", 1000, "Max number of blocks to follow files submitted at a time")
+	indexerCmd.Flags().DurationVar(&indexerArgs.catalog.PollInterval, "catalog-poll-interval", 5*time.Second, "Interval to poll new blocks to follow files submitted")
+	indexerCmd.MarkFlagsRequiredTogether("catalog-db", "catalog-blockchain")
 
 	indexerCmd.MarkFlagsOneRequired("trusted", "node")
 
@@ -54,6 +86,12 @@ func startIndexer(*cobra.Command, []string) {
 	indexerArgs.locationCache.DiscoveryNode = indexerArgs.nodes.DiscoveryNode
 	indexerArgs.locationCache.DiscoveryPorts = indexerArgs.nodes.DiscoveryPorts
 
+	trustedProxies, err := parseTrustedProxies()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to parse trusted proxies")
+	}
+	indexerArgs.locations.TrustedProxies = trustedProxies
+
 	indexer.InitDefaultIPLocationManager(indexerArgs.locations)
 
 	nodeManager, err := indexer.InitDefaultNodeManager(indexerArgs.nodes)
@@ -68,6 +106,18 @@ func startIndexer(*cobra.Command, []string) {
 	}
 	defer fileLocationCache.Close()
 
+	var fileCatalog *catalog.Catalog
+	if len(indexerArgs.catalog.DBPath) > 0 {
+		if indexerArgs.catalog.FlowAddress, err = getFlowAddress(nodeManager); err != nil {
+			logrus.WithError(err).Fatal("Failed to retrieve flow contract address")
+		}
+
+		if fileCatalog, err = indexer.InitDefaultCatalog(indexerArgs.catalog); err != nil {
+			logrus.WithError(err).Fatal("Failed to initialize the default file catalog")
+		}
+		defer fileCatalog.Close()
+	}
+
 	api := indexer.NewIndexerApi()
 
 	logrus.WithFields(logrus.Fields{
@@ -75,11 +125,74 @@ func startIndexer(*cobra.Command, []string) {
 		"discover": len(indexerArgs.nodes.DiscoveryNode) > 0,
 	}).Info("Starting indexer service ...")
 
+	var registry *prometheus.Registry
+	if indexerArgs.metrics {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+		if err = indexer.RegisterMetrics(registry); err != nil {
+			logrus.WithError(err).Fatal("Failed to register indexer metrics")
+		}
+	}
+
 	gateway.MustServeWithRPC(nodeManager, fileLocationCache, gateway.Config{
 		Endpoint:            indexerArgs.endpoint,
 		MaxDownloadFileSize: indexerArgs.maxDownloadFileSize,
+		Catalog:             fileCatalog,
+		Metrics:             registry,
 		RPCHandler: rpc.MustNewHandler(map[string]interface{}{
 			api.Namespace: api,
 		}),
 	})
 }
+
+// getFlowAddress returns the flow contract address specified, or retrieved from trusted storage nodes.
+func getFlowAddress(nodeManager *indexer.NodeManager) (common.Address, error) {
+	if len(indexerArgs.catalogFlow) > 0 {
+		if !common.IsHexAddress(indexerArgs.catalogFlow) {
+			return common.Address{}, errors.Errorf("Invalid flow contract address %v", indexerArgs.catalogFlow)
+		}
+
+		return common.HexToAddress(indexerArgs.catalogFlow), nil
+	}
+
+	var lastErr error
+	for _, client := range nodeManager.TrustedClients() {
+		status, err := client.GetStatus(context.Background())
+		if err == nil {
+			return status.NetworkIdentity.FlowContractAddress, nil
+		}
+
+		lastErr = errors.WithMessagef(err, "Failed to retrieve status from storage node %v", client.URL())
+	}
+
+	if lastErr != nil {
+		return common.Address{}, lastErr
+	}
+
+	return common.Address{}, errors.New("Neither flow contract specified nor trusted nodes available")
+}
+
+// parseTrustedProxies parses the trusted proxies in format of IP or CIDR.
+func parseTrustedProxies() ([]net.IPNet, error) {
+	var proxies []net.IPNet
+
+	for _, v := range indexerArgs.trustedProxies {
+		if !strings.Contains(v, "/") {
+			if ip := net.ParseIP(v); ip != nil && ip.To4() != nil {
+				v += "/32"
+			} else {
+				v += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid trusted proxy %v", v)
+		}
+
+		proxies = append(proxies, *ipNet)
+	}
+
+	return proxies, nil
+}
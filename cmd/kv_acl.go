@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/common/blockchain"
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	kvAclRoleAdmin     = "admin"
+	kvAclRoleWriter    = "writer"
+	kvAclRoleKeyWriter = "key-writer"
+)
+
+var (
+	kvAclArgs struct {
+		streamId string
+		role     string
+		accounts []string
+		keys     []string
+
+		// write
+		url             string
+		key             string
+		nodes           []string
+		indexer         string
+		expectedReplica uint
+		taskSize        uint
+		method          string
+
+		// show
+		node    string
+		version uint64
+		json    bool
+
+		timeout time.Duration
+	}
+
+	kvAclCmd = &cobra.Command{
+		Use:   "kv-acl",
+		Short: "manage access control of kv stream",
+	}
+
+	kvAclGrantCmd = &cobra.Command{
+		Use:   "grant",
+		Short: "grant admin, writer or key-writer role to accounts",
+		Run:   kvAclGrant,
+	}
+
+	kvAclRevokeCmd = &cobra.Command{
+		Use:   "revoke",
+		Short: "revoke writer or key-writer role from accounts",
+		Run:   kvAclRevoke,
+	}
+
+	kvAclRenounceCmd = &cobra.Command{
+		Use:   "renounce",
+		Short: "renounce admin, writer or key-writer role of the sender",
+		Run:   kvAclRenounce,
+	}
+
+	kvAclSetSpecialCmd = &cobra.Command{
+		Use:   "set-special",
+		Short: "mark keys as special, which could only be written by key-writers",
+		Run:   kvAclSetSpecial,
+	}
+
+	kvAclSetNormalCmd = &cobra.Command{
+		Use:   "set-normal",
+		Short: "mark special keys as normal, which could be written by stream writers",
+		Run:   kvAclSetNormal,
+	}
+
+	kvAclShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "show access control of an account and/or a key",
+		Run:   kvAclShow,
+	}
+)
+
+func init() {
+	kvAclCmd.PersistentFlags().StringVar(&kvAclArgs.streamId, "stream-id", "0x", "stream to manage access control")
+	kvAclCmd.MarkPersistentFlagRequired("stream-id")
+	kvAclCmd.PersistentFlags().DurationVar(&kvAclArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	for _, cmd := range []*cobra.Command{kvAclGrantCmd, kvAclRevokeCmd, kvAclRenounceCmd} {
+		cmd.Flags().StringVar(&kvAclArgs.role, "role", "", "role of access control, can be admin, writer or key-writer")
+		cmd.MarkFlagRequired("role")
+	}
+
+	for _, cmd := range []*cobra.Command{kvAclGrantCmd, kvAclRevokeCmd} {
+		cmd.Flags().StringSliceVar(&kvAclArgs.accounts, "accounts", []string{}, "accounts to grant or revoke role")
+		cmd.MarkFlagRequired("accounts")
+	}
+
+	for _, cmd := range []*cobra.Command{kvAclGrantCmd, kvAclRevokeCmd, kvAclRenounceCmd, kvAclSetSpecialCmd, kvAclSetNormalCmd} {
+		cmd.Flags().StringSliceVar(&kvAclArgs.keys, "stream-keys", []string{}, "kv keys, required for key-writer role")
+
+		cmd.Flags().StringVar(&kvAclArgs.url, "url", "", "Fullnode URL to interact with ZeroGStorage smart contract")
+		cmd.MarkFlagRequired("url")
+		cmd.Flags().StringVar(&kvAclArgs.key, "key", "", "Private key to interact with smart contract")
+		cmd.MarkFlagRequired("key")
+
+		cmd.Flags().StringSliceVar(&kvAclArgs.nodes, "node", []string{}, "ZeroGStorage storage node URL")
+		cmd.Flags().StringVar(&kvAclArgs.indexer, "indexer", "", "ZeroGStorage indexer URL")
+
+		cmd.Flags().UintVar(&kvAclArgs.expectedReplica, "expected-replica", 1, "expected number of replications to upload")
+		cmd.Flags().UintVar(&kvAclArgs.taskSize, "task-size", 10, "Number of segments to upload in single rpc request")
+		cmd.Flags().StringVar(&kvAclArgs.method, "method", "random", "method for selecting nodes, can be max, min, random, positive number, nearest[:lat,lng] or diverse[:region], if provided a number, will fail if the requirement cannot be met")
+	}
+
+	kvAclSetSpecialCmd.MarkFlagRequired("stream-keys")
+	kvAclSetNormalCmd.MarkFlagRequired("stream-keys")
+
+	kvAclShowCmd.Flags().StringSliceVar(&kvAclArgs.accounts, "accounts", []string{}, "accounts to show access control")
+	kvAclShowCmd.Flags().StringSliceVar(&kvAclArgs.keys, "stream-keys", []string{}, "kv keys to show access control")
+	kvAclShowCmd.Flags().Uint64Var(&kvAclArgs.version, "version", math.MaxUint64, "stream version to query")
+	kvAclShowCmd.Flags().BoolVar(&kvAclArgs.json, "json", false, "output reports in json format")
+	kvAclShowCmd.Flags().StringVar(&kvAclArgs.node, "node", "", "kv node url")
+	kvAclShowCmd.MarkFlagRequired("node")
+
+	kvAclCmd.AddCommand(kvAclGrantCmd)
+	kvAclCmd.AddCommand(kvAclRevokeCmd)
+	kvAclCmd.AddCommand(kvAclRenounceCmd)
+	kvAclCmd.AddCommand(kvAclSetSpecialCmd)
+	kvAclCmd.AddCommand(kvAclSetNormalCmd)
+	kvAclCmd.AddCommand(kvAclShowCmd)
+
+	rootCmd.AddCommand(kvAclCmd)
+}
+
+func kvAclContext() (context.Context, context.CancelFunc) {
+	if kvAclArgs.timeout > 0 {
+		return context.WithTimeout(context.Background(), kvAclArgs.timeout)
+	}
+
+	return context.WithCancel(context.Background())
+}
+
+func mustParseKvAclAccounts() []common.Address {
+	var accounts []common.Address
+
+	for _, account := range kvAclArgs.accounts {
+		if !common.IsHexAddress(account) {
+			logrus.WithField("account", account).Fatal("Invalid account address")
+		}
+
+		accounts = append(accounts, common.HexToAddress(account))
+	}
+
+	return accounts
+}
+
+// mustCheckKvAclRole checks the role is one of allowed roles, and keys are specified for key-writer role.
+func mustCheckKvAclRole(allowed ...string) {
+	if !slices.Contains(allowed, kvAclArgs.role) {
+		logrus.WithField("allowed", allowed).Fatalf("Invalid role %v", kvAclArgs.role)
+	}
+
+	if kvAclArgs.role == kvAclRoleKeyWriter && len(kvAclArgs.keys) == 0 {
+		logrus.Fatal("--stream-keys is required for key-writer role")
+	}
+}
+
+// execKvAcl executes access control operations added by fn in a single batch.
+func execKvAcl(fn func(batcher *kv.Batcher, streamId common.Hash)) {
+	ctx, cancel := kvAclContext()
+	defer cancel()
+
+	w3client := blockchain.MustNewWeb3(kvAclArgs.url, kvAclArgs.key, providerOption)
+	defer w3client.Close()
+
+	opt := transfer.UploadOption{
+		FinalityRequired: transfer.TransactionPacked,
+		TaskSize:         kvAclArgs.taskSize,
+		ExpectedReplica:  kvAclArgs.expectedReplica,
+		Method:           kvAclArgs.method,
+	}
+
+	clients := mustSelectKvWriteClients(ctx, kvAclArgs.nodes, kvAclArgs.indexer, opt)
+	for _, client := range clients {
+		defer client.Close()
+	}
+
+	// access control operations are not versioned
+	batcher := kv.NewBatcher(math.MaxUint64, clients, w3client, zg_common.LogOption{Logger: logrus.StandardLogger()})
+	fn(batcher, common.HexToHash(kvAclArgs.streamId))
+
+	if _, err := batcher.Exec(ctx, opt); err != nil {
+		logrus.WithError(err).Fatal("Failed to execute access control operations")
+	}
+}
+
+func kvAclGrant(*cobra.Command, []string) {
+	mustCheckKvAclRole(kvAclRoleAdmin, kvAclRoleWriter, kvAclRoleKeyWriter)
+	accounts := mustParseKvAclAccounts()
+
+	execKvAcl(func(batcher *kv.Batcher, streamId common.Hash) {
+		for _, account := range accounts {
+			switch kvAclArgs.role {
+			case kvAclRoleAdmin:
+				batcher.GrantAdminRole(streamId, account)
+			case kvAclRoleWriter:
+				batcher.GrantWriteRole(streamId, account)
+			default:
+				for _, key := range kvAclArgs.keys {
+					batcher.GrantSpecialWriteRole(streamId, []byte(key), account)
+				}
+			}
+		}
+	})
+}
+
+func kvAclRevoke(*cobra.Command, []string) {
+	// admin role could only be renounced
+	mustCheckKvAclRole(kvAclRoleWriter, kvAclRoleKeyWriter)
+	accounts := mustParseKvAclAccounts()
+
+	execKvAcl(func(batcher *kv.Batcher, streamId common.Hash) {
+		for _, account := range accounts {
+			switch kvAclArgs.role {
+			case kvAclRoleWriter:
+				batcher.RevokeWriteRole(streamId, account)
+			default:
+				for _, key := range kvAclArgs.keys {
+					batcher.RevokeSpecialWriteRole(streamId, []byte(key), account)
+				}
+			}
+		}
+	})
+}
+
+func kvAclRenounce(*cobra.Command, []string) {
+	mustCheckKvAclRole(kvAclRoleAdmin, kvAclRoleWriter, kvAclRoleKeyWriter)
+
+	execKvAcl(func(batcher *kv.Batcher, streamId common.Hash) {
+		switch kvAclArgs.role {
+		case kvAclRoleAdmin:
+			batcher.RenounceAdminRole(streamId)
+		case kvAclRoleWriter:
+			batcher.RenounceWriteRole(streamId)
+		default:
+			for _, key := range kvAclArgs.keys {
+				batcher.RenounceSpecialWriteRole(streamId, []byte(key))
+			}
+		}
+	})
+}
+
+func kvAclSetSpecial(*cobra.Command, []string) {
+	execKvAcl(func(batcher *kv.Batcher, streamId common.Hash) {
+		for _, key := range kvAclArgs.keys {
+			batcher.SetKeyToSpecial(streamId, []byte(key))
+		}
+	})
+}
+
+func kvAclSetNormal(*cobra.Command, []string) {
+	execKvAcl(func(batcher *kv.Batcher, streamId common.Hash) {
+		for _, key := range kvAclArgs.keys {
+			batcher.SetKeyToNormal(streamId, []byte(key))
+		}
+	})
+}
+
+func kvAclShow(*cobra.Command, []string) {
+	ctx, cancel := kvAclContext()
+	defer cancel()
+
+	accounts := mustParseKvAclAccounts()
+	if len(accounts) == 0 && len(kvAclArgs.keys) == 0 {
+		logrus.Fatal("At least one of --accounts and --stream-keys should not be empty")
+	}
+
+	client := node.MustNewKvClient(kvAclArgs.node, providerOption)
+	defer client.Close()
+	kvClient := kv.NewClient(client)
+	streamId := common.HexToHash(kvAclArgs.streamId)
+
+	// query all combinations of accounts and keys
+	type query struct {
+		account *common.Address
+		key     []byte
+	}
+
+	var queries []query
+	switch {
+	case len(accounts) == 0:
+		for _, key := range kvAclArgs.keys {
+			queries = append(queries, query{nil, []byte(key)})
+		}
+	case len(kvAclArgs.keys) == 0:
+		for i := range accounts {
+			queries = append(queries, query{&accounts[i], nil})
+		}
+	default:
+		for i := range accounts {
+			for _, key := range kvAclArgs.keys {
+				queries = append(queries, query{&accounts[i], []byte(key)})
+			}
+		}
+	}
+
+	for _, q := range queries {
+		report, err := kvClient.QueryACL(ctx, streamId, q.account, q.key, kvAclArgs.version)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to query access control")
+		}
+
+		if kvAclArgs.json {
+			bs, _ := json.Marshal(report)
+			fmt.Println(string(bs))
+		} else {
+			printKvAclReport(report)
+		}
+	}
+}
+
+func printKvAclReport(report *kv.ACLReport) {
+	fmt.Printf("Stream:               %v\n", report.StreamId.Hex())
+	if report.Account != nil {
+		fmt.Printf("Account:              %v\n", report.Account.Hex())
+	}
+	if len(report.Key) > 0 {
+		fmt.Printf("Key:                  %v\n", string(report.Key))
+	}
+
+	printFact := func(name string, fact *bool) {
+		if fact == nil {
+			return
+		}
+
+		answer := "no"
+		if *fact {
+			answer = "yes"
+		}
+
+		fmt.Printf("%-22v%v\n", name+":", answer)
+	}
+
+	printFact("Admin", report.IsAdmin)
+	printFact("Stream writer", report.IsWriterOfStream)
+	printFact("Special key", report.IsSpecialKey)
+	printFact("Special key writer", report.IsWriterOfKey)
+	printFact("Write permission", report.HasWritePermission)
+
+	fmt.Println()
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kvExportArgs struct {
+		streamId string
+		version  uint64
+		accounts []string
+		file     string
+
+		node string
+
+		timeout time.Duration
+	}
+
+	kvExportCmd = &cobra.Command{
+		Use:   "kv-export",
+		Short: "export snapshot of kv stream",
+		Run:   kvExport,
+	}
+)
+
+func init() {
+	kvExportCmd.Flags().StringVar(&kvExportArgs.streamId, "stream-id", "0x", "stream to export")
+	kvExportCmd.MarkFlagRequired("stream-id")
+
+	kvExportCmd.Flags().Uint64Var(&kvExportArgs.version, "version", math.MaxUint64, "stream version to export")
+	kvExportCmd.Flags().StringSliceVar(&kvExportArgs.accounts, "accounts", []string{}, "accounts to export admin and writer roles")
+	kvExportCmd.Flags().StringVar(&kvExportArgs.file, "file", "-", "snapshot file to write, \"-\" for stdout")
+
+	kvExportCmd.Flags().StringVar(&kvExportArgs.node, "node", "", "kv node url")
+	kvExportCmd.MarkFlagRequired("node")
+
+	kvExportCmd.Flags().DurationVar(&kvExportArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	rootCmd.AddCommand(kvExportCmd)
+}
+
+func kvExport(*cobra.Command, []string) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if kvExportArgs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, kvExportArgs.timeout)
+		defer cancel()
+	}
+
+	client := node.MustNewKvClient(kvExportArgs.node, providerOption)
+	defer client.Close()
+	kvClient := kv.NewClient(client)
+	streamId := common.HexToHash(kvExportArgs.streamId)
+
+	var accounts []common.Address
+	for _, account := range kvExportArgs.accounts {
+		if !common.IsHexAddress(account) {
+			logrus.WithField("account", account).Fatal("Invalid account address")
+		}
+		accounts = append(accounts, common.HexToAddress(account))
+	}
+
+	var w io.Writer = os.Stdout
+	if kvExportArgs.file != "-" {
+		file, err := os.Create(kvExportArgs.file)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create snapshot file")
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	lastReportTime := time.Now()
+
+	err := kv.Export(ctx, kvClient, streamId, kvExportArgs.version, buffered, kv.ExportOption{
+		Accounts: accounts,
+		Progress: func(records int) {
+			if time.Since(lastReportTime) > 5*time.Second {
+				logrus.WithField("records", records).Info("Progress update")
+				lastReportTime = time.Now()
+			}
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to export kv stream")
+	}
+
+	if err = buffered.Flush(); err != nil {
+		logrus.WithError(err).Fatal("Failed to write snapshot file")
+	}
+
+	logrus.WithField("stream", streamId).Info("Succeeded to export kv stream")
+}
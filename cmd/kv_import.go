@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/common/blockchain"
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kvImportArgs struct {
+		streamId     string
+		file         string
+		resumeFile   string
+		maxBatchSize int
+
+		url string
+		key string
+
+		node    []string
+		indexer string
+
+		expectedReplica uint
+		taskSize        uint
+		method          string
+
+		timeout time.Duration
+	}
+
+	kvImportCmd = &cobra.Command{
+		Use:   "kv-import",
+		Short: "import snapshot into kv stream",
+		Run:   kvImport,
+	}
+)
+
+func init() {
+	kvImportCmd.Flags().StringVar(&kvImportArgs.streamId, "stream-id", "0x", "stream to import into")
+	kvImportCmd.MarkFlagRequired("stream-id")
+
+	kvImportCmd.Flags().StringVar(&kvImportArgs.file, "file", "", "snapshot file exported by kv-export")
+	kvImportCmd.MarkFlagRequired("file")
+	kvImportCmd.Flags().StringVar(&kvImportArgs.resumeFile, "resume-file", "", "file to record the number of imported records, so as to resume import on failure")
+	kvImportCmd.Flags().IntVar(&kvImportArgs.maxBatchSize, "max-batch-size", 4*1024*1024, "max size of key-values in a single batch")
+
+	kvImportCmd.Flags().StringVar(&kvImportArgs.url, "url", "", "Fullnode URL to interact with ZeroGStorage smart contract")
+	kvImportCmd.MarkFlagRequired("url")
+	kvImportCmd.Flags().StringVar(&kvImportArgs.key, "key", "", "Private key to interact with smart contract")
+	kvImportCmd.MarkFlagRequired("key")
+
+	kvImportCmd.Flags().StringSliceVar(&kvImportArgs.node, "node", []string{}, "ZeroGStorage storage node URL")
+	kvImportCmd.Flags().StringVar(&kvImportArgs.indexer, "indexer", "", "ZeroGStorage indexer URL")
+
+	kvImportCmd.Flags().UintVar(&kvImportArgs.expectedReplica, "expected-replica", 1, "expected number of replications to upload")
+	kvImportCmd.Flags().UintVar(&kvImportArgs.taskSize, "task-size", 10, "Number of segments to upload in single rpc request")
+	kvImportCmd.Flags().StringVar(&kvImportArgs.method, "method", "random", "method for selecting nodes, can be max, min, random, positive number, nearest[:lat,lng] or diverse[:region], if provided a number, will fail if the requirement cannot be met")
+
+	kvImportCmd.Flags().DurationVar(&kvImportArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	rootCmd.AddCommand(kvImportCmd)
+}
+
+func kvImport(*cobra.Command, []string) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if kvImportArgs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, kvImportArgs.timeout)
+		defer cancel()
+	}
+
+	w3client := blockchain.MustNewWeb3(kvImportArgs.url, kvImportArgs.key, providerOption)
+	defer w3client.Close()
+
+	opt := transfer.UploadOption{
+		FinalityRequired: transfer.TransactionPacked,
+		TaskSize:         kvImportArgs.taskSize,
+		ExpectedReplica:  kvImportArgs.expectedReplica,
+		Method:           kvImportArgs.method,
+	}
+
+	clients := mustSelectKvWriteClients(ctx, kvImportArgs.node, kvImportArgs.indexer, opt)
+	for _, client := range clients {
+		defer client.Close()
+	}
+
+	file, err := os.Open(kvImportArgs.file)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open snapshot file")
+	}
+	defer file.Close()
+
+	skip := loadImportedRecords(kvImportArgs.resumeFile)
+	if skip > 0 {
+		logrus.WithField("records", skip).Info("Resume to import snapshot")
+	}
+
+	imported, err := kv.Import(ctx, file, common.HexToHash(kvImportArgs.streamId), clients, w3client, kv.ImportOption{
+		MaxBatchSize: kvImportArgs.maxBatchSize,
+		Skip:         skip,
+		Progress: func(records int) {
+			saveImportedRecords(kvImportArgs.resumeFile, records)
+		},
+		UploadOption: opt,
+		LogOption:    zg_common.LogOption{Logger: logrus.StandardLogger()},
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("imported", imported).Fatal("Failed to import snapshot, please retry with --resume-file to resume")
+	}
+}
+
+// loadImportedRecords loads the number of imported records from resume file, or 0 if not exists.
+func loadImportedRecords(resumeFile string) int {
+	if resumeFile == "" {
+		return 0
+	}
+
+	content, err := os.ReadFile(resumeFile)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to read resume file")
+	}
+
+	records, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid resume file")
+	}
+
+	return records
+}
+
+func saveImportedRecords(resumeFile string, records int) {
+	if resumeFile == "" {
+		return
+	}
+
+	if err := os.WriteFile(resumeFile, []byte(strconv.Itoa(records)), 0644); err != nil {
+		logrus.WithError(err).Warn("Failed to write resume file")
+	}
+}
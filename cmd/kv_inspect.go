@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kvInspectArgs struct {
+		txSeq uint64
+		root  string
+
+		nodes []string
+		proof bool
+
+		json         bool
+		maxValueSize int
+
+		timeout time.Duration
+	}
+
+	kvInspectCmd = &cobra.Command{
+		Use:   "kv-inspect",
+		Short: "inspect reads, writes and access controls of kv transaction",
+		Run:   kvInspect,
+	}
+)
+
+func init() {
+	kvInspectCmd.Flags().Uint64Var(&kvInspectArgs.txSeq, "tx-seq", 0, "sequence number of kv transaction")
+	kvInspectCmd.Flags().StringVar(&kvInspectArgs.root, "root", "", "data merkle root of kv transaction")
+	kvInspectCmd.MarkFlagsOneRequired("tx-seq", "root")
+	kvInspectCmd.MarkFlagsMutuallyExclusive("tx-seq", "root")
+
+	kvInspectCmd.Flags().StringSliceVar(&kvInspectArgs.nodes, "node", []string{}, "ZeroGStorage storage node URL")
+	kvInspectCmd.MarkFlagRequired("node")
+	kvInspectCmd.Flags().BoolVar(&kvInspectArgs.proof, "proof", false, "Whether to download with merkle proof for validation")
+
+	kvInspectCmd.Flags().BoolVar(&kvInspectArgs.json, "json", false, "output in json format")
+	kvInspectCmd.Flags().IntVar(&kvInspectArgs.maxValueSize, "max-value-size", 64, "max number of bytes to output for each value, negative for unlimited")
+
+	kvInspectCmd.Flags().DurationVar(&kvInspectArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	rootCmd.AddCommand(kvInspectCmd)
+}
+
+// kvInspectReport is the json output of kv-inspect.
+type kvInspectReport struct {
+	TxSeq     uint64          `json:"txSeq"`
+	Root      common.Hash     `json:"root"`
+	StreamIds []common.Hash   `json:"streamIds"`
+	Version   uint64          `json:"version"`
+	Reads     []kvInspectItem `json:"reads"`
+	Writes    []kvInspectItem `json:"writes"`
+	Controls  []kvInspectItem `json:"controls"`
+}
+
+type kvInspectItem struct {
+	Type      string          `json:"type,omitempty"`
+	StreamId  common.Hash     `json:"streamId"`
+	Key       hexutil.Bytes   `json:"key,omitempty"`
+	Account   *common.Address `json:"account,omitempty"`
+	Value     hexutil.Bytes   `json:"value,omitempty"`
+	Size      *int            `json:"size,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+func kvInspect(cmd *cobra.Command, _ []string) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if kvInspectArgs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, kvInspectArgs.timeout)
+		defer cancel()
+	}
+
+	clients := node.MustNewZgsClients(kvInspectArgs.nodes, providerOption)
+	for _, client := range clients {
+		defer client.Close()
+	}
+
+	info, err := queryKvInspectFile(ctx, clients, cmd.Flags().Changed("tx-seq"))
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to query file info")
+	}
+
+	// kv node only replays transactions tagged with StreamDomain, of which the stream ids are parsed
+	if len(info.Tx.StreamIds) == 0 {
+		logrus.WithField("txSeq", info.Tx.Seq).Fatal("Not a kv transaction, which is not tagged with stream domain")
+	}
+
+	streamIds := make(map[common.Hash]bool)
+	report := kvInspectReport{
+		TxSeq: info.Tx.Seq,
+		Root:  info.Tx.DataMerkleRoot,
+	}
+	for _, v := range info.Tx.StreamIds {
+		streamId := common.BigToHash(v.ToInt())
+		streamIds[streamId] = true
+		report.StreamIds = append(report.StreamIds, streamId)
+	}
+
+	data, err := downloadKvInspectData(ctx, clients, info.Tx.DataMerkleRoot)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to download kv transaction data")
+	}
+
+	streamData, err := kv.DecodeStreamData(data)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to decode kv transaction data")
+	}
+
+	report.Version = streamData.Version
+
+	for _, v := range streamData.Reads {
+		report.Reads = append(report.Reads, kvInspectItem{StreamId: v.StreamId, Key: v.Key})
+	}
+
+	for _, v := range streamData.Writes {
+		size := len(v.Data)
+		item := kvInspectItem{StreamId: v.StreamId, Key: v.Key, Value: v.Data, Size: &size}
+		if kvInspectArgs.maxValueSize >= 0 && size > kvInspectArgs.maxValueSize {
+			item.Value = v.Data[:kvInspectArgs.maxValueSize]
+			item.Truncated = true
+		}
+		report.Writes = append(report.Writes, item)
+	}
+
+	for _, v := range streamData.Controls {
+		report.Controls = append(report.Controls, kvInspectItem{
+			Type:     v.Type.String(),
+			StreamId: v.StreamId,
+			Key:      v.Key,
+			Account:  v.Account,
+		})
+	}
+
+	for _, items := range [][]kvInspectItem{report.Reads, report.Writes, report.Controls} {
+		for _, item := range items {
+			if !streamIds[item.StreamId] {
+				logrus.WithField("streamId", item.StreamId).Warn("Stream id not found in transaction tags")
+			}
+		}
+	}
+
+	if kvInspectArgs.json {
+		bs, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(bs))
+	} else {
+		printKvInspectReport(&report)
+	}
+}
+
+func queryKvInspectFile(ctx context.Context, clients []*node.ZgsClient, byTxSeq bool) (*node.FileInfo, error) {
+	for _, client := range clients {
+		var info *node.FileInfo
+		var err error
+
+		if byTxSeq {
+			info, err = client.GetFileInfoByTxSeq(ctx, kvInspectArgs.txSeq)
+		} else {
+			info, err = client.GetFileInfo(ctx, common.HexToHash(kvInspectArgs.root), true)
+		}
+
+		if err != nil {
+			logrus.WithError(err).WithField("node", client.URL()).Debug("Failed to query file info")
+			continue
+		}
+
+		if info != nil {
+			return info, nil
+		}
+	}
+
+	return nil, errors.New("file not found on any storage node")
+}
+
+func downloadKvInspectData(ctx context.Context, clients []*node.ZgsClient, root common.Hash) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "kv-inspect-")
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(dir)
+
+	downloader, err := transfer.NewDownloader(clients, zg_common.LogOption{Logger: logrus.StandardLogger()})
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Join(dir, root.Hex())
+	if err = downloader.Download(ctx, root.Hex(), filename, kvInspectArgs.proof); err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filename)
+}
+
+func printKvInspectReport(report *kvInspectReport) {
+	fmt.Printf("Tx seq:   %v\n", report.TxSeq)
+	fmt.Printf("Root:     %v\n", report.Root.Hex())
+	for _, streamId := range report.StreamIds {
+		fmt.Printf("Stream:   %v\n", streamId.Hex())
+	}
+
+	if report.Version == math.MaxUint64 {
+		fmt.Println("Version:  latest")
+	} else {
+		fmt.Printf("Version:  %v\n", report.Version)
+	}
+
+	fmt.Printf("\nReads (%v):\n", len(report.Reads))
+	for _, v := range report.Reads {
+		fmt.Printf("  %v %v\n", v.StreamId.Hex(), formatKvInspectData(v.Key, false))
+	}
+
+	fmt.Printf("\nWrites (%v):\n", len(report.Writes))
+	for _, v := range report.Writes {
+		fmt.Printf("  %v %v = %v (%v bytes)\n", v.StreamId.Hex(), formatKvInspectData(v.Key, false), formatKvInspectData(v.Value, v.Truncated), *v.Size)
+	}
+
+	fmt.Printf("\nAccess controls (%v):\n", len(report.Controls))
+	for _, v := range report.Controls {
+		line := fmt.Sprintf("  %v %v", v.Type, v.StreamId.Hex())
+		if len(v.Key) > 0 {
+			line += " key=" + formatKvInspectData(v.Key, false)
+		}
+		if v.Account != nil {
+			line += " account=" + v.Account.Hex()
+		}
+		fmt.Println(line)
+	}
+}
+
+// formatKvInspectData formats data in hex along with the printable text if any.
+func formatKvInspectData(data []byte, truncated bool) string {
+	formatted := hexutil.Encode(data)
+	if truncated {
+		formatted += "..."
+	}
+
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return formatted
+		}
+	}
+
+	if len(data) == 0 {
+		return formatted
+	}
+
+	if truncated {
+		return fmt.Sprintf("%v (%q...)", formatted, data)
+	}
+
+	return fmt.Sprintf("%v (%q)", formatted, data)
+}
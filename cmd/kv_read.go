@@ -20,7 +20,9 @@ var (
 		keys     []string
 		version  uint64
 
-		node string
+		nodes       []string
+		consistency string
+		minVersion  uint64
 
 		timeout time.Duration
 	}
@@ -41,8 +43,10 @@ func init() {
 
 	kvReadCmd.Flags().Uint64Var(&kvReadArgs.version, "version", math.MaxUint64, "key version")
 
-	kvReadCmd.Flags().StringVar(&kvReadArgs.node, "node", "", "kv node url")
+	kvReadCmd.Flags().StringSliceVar(&kvReadArgs.nodes, "node", []string{}, "kv node urls")
 	kvReadCmd.MarkFlagRequired("node")
+	kvReadCmd.Flags().StringVar(&kvReadArgs.consistency, "consistency", "any", "read consistency across kv nodes, can be any, quorum or min-version")
+	kvReadCmd.Flags().Uint64Var(&kvReadArgs.minVersion, "min-version", 0, "min version that kv nodes have replayed, e.g. tx seq of previous write, for min-version consistency")
 
 	kvReadCmd.Flags().DurationVar(&kvReadArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
 
@@ -57,9 +61,25 @@ func kvRead(*cobra.Command, []string) {
 		defer cancel()
 	}
 
-	client := node.MustNewKvClient(kvReadArgs.node, providerOption)
-	defer client.Close()
-	kvClient := kv.NewClient(client)
+	opt := kv.ClientOption{MinVersion: kvReadArgs.minVersion}
+	switch kvReadArgs.consistency {
+	case "any":
+		opt.Consistency = kv.ConsistencyAny
+	case "quorum":
+		opt.Consistency = kv.ConsistencyQuorum
+	case "min-version":
+		opt.Consistency = kv.ConsistencyMinVersion
+	default:
+		logrus.WithField("consistency", kvReadArgs.consistency).Fatal("Invalid read consistency")
+	}
+
+	var clients []*node.KvClient
+	for _, url := range kvReadArgs.nodes {
+		client := node.MustNewKvClient(url, providerOption)
+		defer client.Close()
+		clients = append(clients, client)
+	}
+	kvClient := kv.NewClientWithOption(clients, opt)
 	streamId := common.HexToHash(kvReadArgs.streamId)
 
 	m := make(map[string]string)
@@ -68,7 +88,11 @@ func kvRead(*cobra.Command, []string) {
 		if err != nil {
 			logrus.WithError(err).Fatalf("failed to read key %v", key)
 		}
-		m[key] = string(val.Data)
+		if val != nil {
+			m[key] = string(val.Data)
+		} else {
+			m[key] = ""
+		}
 	}
 	bs, _ := json.Marshal(m)
 	fmt.Println(string(bs))
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kvScanArgs struct {
+		streamId string
+
+		prefix   string
+		start    string
+		end      string
+		limit    int
+		reverse  bool
+		keysOnly bool
+		version  uint64
+
+		hex    bool
+		format string
+
+		node string
+
+		timeout time.Duration
+	}
+
+	kvScanCmd = &cobra.Command{
+		Use:   "kv-scan",
+		Short: "scan key-values in kv stream",
+		Run:   kvScan,
+	}
+)
+
+func init() {
+	kvScanCmd.Flags().StringVar(&kvScanArgs.streamId, "stream-id", "0x", "stream to scan")
+	kvScanCmd.MarkFlagRequired("stream-id")
+
+	kvScanCmd.Flags().StringVar(&kvScanArgs.prefix, "prefix", "", "only scan keys with prefix")
+	kvScanCmd.Flags().StringVar(&kvScanArgs.start, "start", "", "scan keys from start key (inclusive)")
+	kvScanCmd.Flags().StringVar(&kvScanArgs.end, "end", "", "scan keys until end key (exclusive)")
+	kvScanCmd.Flags().IntVar(&kvScanArgs.limit, "limit", 0, "max number of keys to scan, 0 for unlimited")
+	kvScanCmd.Flags().BoolVar(&kvScanArgs.reverse, "reverse", false, "scan keys in descending order")
+	kvScanCmd.Flags().BoolVar(&kvScanArgs.keysOnly, "keys-only", false, "only output keys without values")
+	kvScanCmd.Flags().Uint64Var(&kvScanArgs.version, "version", 0, "version to read at, 0 for latest version")
+
+	kvScanCmd.Flags().BoolVar(&kvScanArgs.hex, "hex", false, "keys and values in hex format, including prefix, start and end keys")
+	kvScanCmd.Flags().StringVar(&kvScanArgs.format, "format", "json", "output format, json or csv")
+
+	kvScanCmd.Flags().StringVar(&kvScanArgs.node, "node", "", "kv node url")
+	kvScanCmd.MarkFlagRequired("node")
+
+	kvScanCmd.Flags().DurationVar(&kvScanArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	rootCmd.AddCommand(kvScanCmd)
+}
+
+// kvScanRecord is the output record of kv-scan.
+type kvScanRecord struct {
+	Key     string `json:"key"`
+	Value   string `json:"value,omitempty"`
+	Version uint64 `json:"version"`
+	Size    uint64 `json:"size"`
+}
+
+func kvScan(*cobra.Command, []string) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if kvScanArgs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, kvScanArgs.timeout)
+		defer cancel()
+	}
+
+	if kvScanArgs.format != "json" && kvScanArgs.format != "csv" {
+		logrus.WithField("format", kvScanArgs.format).Fatal("Invalid output format")
+	}
+
+	opt := kv.ScanOptions{
+		Prefix:   decodeScanKey(kvScanArgs.prefix),
+		Start:    decodeScanKey(kvScanArgs.start),
+		End:      decodeScanKey(kvScanArgs.end),
+		Limit:    kvScanArgs.limit,
+		Reverse:  kvScanArgs.reverse,
+		KeysOnly: kvScanArgs.keysOnly,
+		Version:  kvScanArgs.version,
+	}
+
+	client := node.MustNewKvClient(kvScanArgs.node, providerOption)
+	defer client.Close()
+	kvClient := kv.NewClient(client)
+	streamId := common.HexToHash(kvScanArgs.streamId)
+
+	var jsonEncoder *json.Encoder
+	var csvWriter *csv.Writer
+	if kvScanArgs.format == "json" {
+		jsonEncoder = json.NewEncoder(os.Stdout)
+	} else {
+		csvWriter = csv.NewWriter(os.Stdout)
+		csvWriter.Write([]string{"key", "value", "version", "size"})
+	}
+
+	var scanErr error
+	kvClient.Scan(ctx, streamId, opt)(func(pair *node.KeyValue, err error) bool {
+		if err != nil {
+			scanErr = err
+			return false
+		}
+
+		record := kvScanRecord{
+			Key:     encodeScanData(pair.Key),
+			Version: pair.Version,
+			Size:    pair.Size,
+		}
+		if !kvScanArgs.keysOnly {
+			record.Value = encodeScanData(pair.Data)
+		}
+
+		// output one record per line, so that it could be processed in stream
+		if jsonEncoder != nil {
+			err = jsonEncoder.Encode(record)
+		} else {
+			err = csvWriter.Write([]string{record.Key, record.Value, strconv.FormatUint(record.Version, 10), strconv.FormatUint(record.Size, 10)})
+		}
+
+		if err != nil {
+			scanErr = err
+			return false
+		}
+
+		return true
+	})
+
+	// flush the records scanned before exit on error
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil && scanErr == nil {
+			scanErr = err
+		}
+	}
+
+	if scanErr != nil {
+		logrus.WithError(scanErr).Fatal("Failed to scan kv stream")
+	}
+}
+
+func decodeScanKey(key string) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+
+	if !kvScanArgs.hex {
+		return []byte(key)
+	}
+
+	decoded, err := hexutil.Decode(key)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Fatal("Failed to decode hex key")
+	}
+
+	return decoded
+}
+
+func encodeScanData(data []byte) string {
+	if kvScanArgs.hex {
+		return hexutil.Encode(data)
+	}
+
+	return string(data)
+}
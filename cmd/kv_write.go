@@ -41,6 +41,9 @@ var (
 
 		method  string
 		timeout time.Duration
+
+		kvNode      string
+		waitTimeout time.Duration
 	}
 
 	kvWriteCmd = &cobra.Command{
@@ -80,7 +83,10 @@ func init() {
 
 	kvWriteCmd.Flags().Float64Var(&kvWriteArgs.fee, "fee", 0, "fee paid in a0gi")
 	kvWriteCmd.Flags().UintVar(&kvWriteArgs.nonce, "nonce", 0, "nonce of upload transaction")
-	kvWriteCmd.Flags().StringVar(&kvWriteArgs.method, "method", "random", "method for selecting nodes, can be max, min, random, or positive number, if provided a number, will fail if the requirement cannot be met")
+	kvWriteCmd.Flags().StringVar(&kvWriteArgs.method, "method", "random", "method for selecting nodes, can be max, min, random, positive number, nearest[:lat,lng] or diverse[:region], if provided a number, will fail if the requirement cannot be met")
+
+	kvWriteCmd.Flags().StringVar(&kvWriteArgs.kvNode, "kv-node", "", "kv node url to wait for the written keys applied")
+	kvWriteCmd.Flags().DurationVar(&kvWriteArgs.waitTimeout, "wait-timeout", 0, "timeout to wait for the written keys applied on kv node, 0 for no timeout")
 
 	rootCmd.AddCommand(kvWriteCmd)
 }
@@ -119,27 +125,9 @@ func kvWrite(*cobra.Command, []string) {
 		Method:           kvWriteArgs.method,
 	}
 
-	var clients []*node.ZgsClient
-	if kvWriteArgs.indexer != "" {
-		indexerClient, err := indexer.NewClient(kvWriteArgs.indexer, indexer.IndexerClientOption{
-			ProviderOption: providerOption,
-			LogOption:      zg_common.LogOption{Logger: logrus.StandardLogger()},
-		})
-		if err != nil {
-			logrus.WithError(err).Fatal("Failed to initialize indexer client")
-		}
-		if clients, err = indexerClient.SelectNodes(ctx, 0, max(1, opt.ExpectedReplica), []string{}, opt.Method); err != nil {
-			logrus.WithError(err).Fatal("failed to select nodes from indexer")
-		}
-	}
-	if len(clients) == 0 {
-		if len(kvWriteArgs.node) == 0 {
-			logrus.Fatal("At least one of --node and --indexer should not be empty")
-		}
-		clients = node.MustNewZgsClients(kvWriteArgs.node, providerOption)
-		for _, client := range clients {
-			defer client.Close()
-		}
+	clients := mustSelectKvWriteClients(ctx, kvWriteArgs.node, kvWriteArgs.indexer, opt)
+	for _, client := range clients {
+		defer client.Close()
 	}
 
 	batcher := kv.NewBatcher(kvWriteArgs.version, clients, w3client, zg_common.LogOption{Logger: logrus.StandardLogger()})
@@ -158,8 +146,49 @@ func kvWrite(*cobra.Command, []string) {
 		)
 	}
 
-	_, err := batcher.Exec(ctx, opt)
+	if kvWriteArgs.kvNode == "" {
+		if _, err := batcher.Exec(ctx, opt); err != nil {
+			logrus.WithError(err).Fatal("fail to execute kv batch")
+		}
+
+		return
+	}
+
+	kvClient := node.MustNewKvClient(kvWriteArgs.kvNode, providerOption)
+	defer kvClient.Close()
+
+	result, err := batcher.ExecWithOption(ctx, kv.ExecOption{
+		UploadOption: opt,
+		KvClient:     kvClient,
+		WaitTimeout:  kvWriteArgs.waitTimeout,
+	})
 	if err != nil {
 		logrus.WithError(err).Fatal("fail to execute kv batch")
 	}
+
+	logrus.WithField("txSeq", result.TxSeqs[0]).Info("Succeeded to apply kv batch")
+}
+
+// mustSelectKvWriteClients selects storage nodes from indexer if specified, otherwise uses the specified nodes.
+func mustSelectKvWriteClients(ctx context.Context, nodes []string, indexerURL string, opt transfer.UploadOption) []*node.ZgsClient {
+	var clients []*node.ZgsClient
+	if indexerURL != "" {
+		indexerClient, err := indexer.NewClient(indexerURL, indexer.IndexerClientOption{
+			ProviderOption: providerOption,
+			LogOption:      zg_common.LogOption{Logger: logrus.StandardLogger()},
+		})
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to initialize indexer client")
+		}
+		if clients, err = indexerClient.SelectNodes(ctx, 0, max(1, opt.ExpectedReplica), []string{}, opt.Method); err != nil {
+			logrus.WithError(err).Fatal("failed to select nodes from indexer")
+		}
+	}
+	if len(clients) == 0 {
+		if len(nodes) == 0 {
+			logrus.Fatal("At least one of --node and --indexer should not be empty")
+		}
+		clients = node.MustNewZgsClients(nodes, providerOption)
+	}
+	return clients
 }
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/0glabs/0g-storage-client/core"
+	"github.com/0glabs/0g-storage-client/core/merkle"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// rangeProof is the output of prove command.
+type rangeProof struct {
+	Root   common.Hash `json:"root"`
+	Size   int64       `json:"size"`
+	Offset int64       `json:"offset"`
+	Length int64       `json:"length"`
+
+	// Chunks that cover the byte range, and the last chunk is zero padded if any.
+	// Requested data starts at (offset % 256) of chunks.
+	Chunks hexutil.Bytes      `json:"chunks"`
+	Proof  *merkle.MultiProof `json:"proof"`
+}
+
+var (
+	proveArgs struct {
+		downloadArgument
+
+		offset int64
+		length int64
+	}
+
+	proveCmd = &cobra.Command{
+		Use:   "prove",
+		Short: "Generate merkle proof for a byte range of file",
+		Run:   prove,
+	}
+)
+
+func init() {
+	proveCmd.Flags().StringVar(&proveArgs.root, "root", "", "Merkle root of file")
+	proveCmd.MarkFlagRequired("root")
+	proveCmd.Flags().Int64Var(&proveArgs.offset, "offset", 0, "Start offset of byte range to prove")
+	proveCmd.Flags().Int64Var(&proveArgs.length, "length", 0, "Length of byte range to prove")
+	proveCmd.MarkFlagRequired("length")
+
+	proveCmd.Flags().StringVar(&proveArgs.file, "file", "", "Local file to generate proof, otherwise download from ZeroGStorage network")
+	proveCmd.Flags().StringSliceVar(&proveArgs.nodes, "node", []string{}, "ZeroGStorage storage node URL. Multiple nodes could be specified and separated by comma, e.g. url1,url2,url3")
+	proveCmd.Flags().StringVar(&proveArgs.indexer, "indexer", "", "ZeroGStorage indexer URL")
+	proveCmd.MarkFlagsOneRequired("file", "indexer", "node")
+	proveCmd.Flags().StringVar(&proveArgs.cacheDir, "cache-dir", "", "Local download cache directory, which is shared across processes and disabled by default")
+	proveCmd.Flags().Int64Var(&proveArgs.cacheSize, "cache-size", 0, "Maximum size in bytes of local download cache, 0 for unlimited")
+	proveCmd.Flags().DurationVar(&proveArgs.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
+
+	rootCmd.AddCommand(proveCmd)
+}
+
+func prove(*cobra.Command, []string) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if proveArgs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, proveArgs.timeout)
+		defer cancel()
+	}
+
+	root := common.HexToHash(proveArgs.root)
+
+	filename := proveArgs.file
+	if filename == "" {
+		filename = downloadToProve(ctx, root)
+		defer os.RemoveAll(filepath.Dir(filename))
+	}
+
+	file, err := core.Open(filename)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open file")
+	}
+	defer file.Close()
+
+	proof, chunks, err := core.ChunkRangeProof(file, proveArgs.offset, proveArgs.length)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to generate merkle proof")
+	}
+
+	// validate against the expected root, especially for local file
+	if err = core.ValidateChunkRangeProof(root, proof, chunks, proveArgs.offset, proveArgs.length, file.Size()); err != nil {
+		logrus.WithError(err).Fatal("Failed to validate merkle proof")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(rangeProof{
+		Root:   root,
+		Size:   file.Size(),
+		Offset: proveArgs.offset,
+		Length: proveArgs.length,
+		Chunks: chunks,
+		Proof:  proof,
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to encode merkle proof")
+	}
+}
+
+// downloadToProve downloads file into a temp folder, which should be removed by caller.
+func downloadToProve(ctx context.Context, root common.Hash) string {
+	proveArgs.routines = runtime.GOMAXPROCS(0)
+
+	downloader, closer, err := newDownloader(proveArgs.downloadArgument)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to initialize downloader")
+	}
+	defer closer()
+
+	tmpDir, err := os.MkdirTemp("", "zg_prove_*")
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create temp folder")
+	}
+
+	filename := filepath.Join(tmpDir, root.Hex())
+	if err = downloader.Download(ctx, root.Hex(), filename, false); err != nil {
+		os.RemoveAll(tmpDir)
+		logrus.WithError(err).Fatal("Failed to download file")
+	}
+
+	return filename
+}
@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"time"
 
 	"github.com/0glabs/0g-storage-client/common/blockchain"
+	"github.com/0glabs/0g-storage-client/core"
 	"github.com/mcuadros/go-defaults"
 	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
 	"github.com/sirupsen/logrus"
@@ -15,6 +17,7 @@ import (
 var (
 	logLevel         string
 	logColorDisabled bool
+	hashRoutines     int
 
 	providerOption providers.Option
 
@@ -24,6 +27,7 @@ var (
 		PersistentPreRun: func(*cobra.Command, []string) {
 			initLog()
 			defaults.SetDefaults(&providerOption)
+			core.SetHashRoutines(hashRoutines)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
@@ -40,6 +44,7 @@ func init() {
 	rootCmd.PersistentFlags().IntVar(&providerOption.RetryCount, "rpc-retry-count", 5, "Retry count for rpc request")
 	rootCmd.PersistentFlags().DurationVar(&providerOption.RetryInterval, "rpc-retry-interval", 5*time.Second, "Retry interval for rpc request")
 	rootCmd.PersistentFlags().DurationVar(&providerOption.RequestTimeout, "rpc-timeout", 30*time.Second, "Timeout for single rpc request")
+	rootCmd.PersistentFlags().IntVar(&hashRoutines, "hash-routines", runtime.GOMAXPROCS(0), "Number of go routines shared by all merkle tree computations")
 }
 
 func initLog() {
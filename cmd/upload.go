@@ -66,7 +66,6 @@ type uploadArgument struct {
 
 func bindUploadFlags(cmd *cobra.Command, args *uploadArgument) {
 	cmd.Flags().StringVar(&args.file, "file", "", "File name to upload")
-	cmd.MarkFlagRequired("file")
 	cmd.Flags().StringVar(&args.tags, "tags", "0x", "Tags of the file")
 
 	cmd.Flags().StringSliceVar(&args.node, "node", []string{}, "ZeroGStorage storage node URL")
@@ -86,7 +85,7 @@ func bindUploadFlags(cmd *cobra.Command, args *uploadArgument) {
 	cmd.Flags().UintVar(&args.maxGasPrice, "max-gas-price", 0, "max gas price to send transaction")
 	cmd.Flags().IntVar(&args.nRetries, "n-retries", 0, "number of retries for uploading when it's not gas price issue")
 	cmd.Flags().Int64Var(&args.step, "step", 15, "step of gas price increasing, step / 10 (for 15, the new gas price is 1.5 * last gas price)")
-	cmd.Flags().StringVar(&args.method, "method", "min", "method for selecting nodes, can be max, min, random, or positive number, if provided a number, will fail if the requirement cannot be met")
+	cmd.Flags().StringVar(&args.method, "method", "min", "method for selecting nodes, can be max, min, random, positive number, nearest[:lat,lng] or diverse[:region], if provided a number, will fail if the requirement cannot be met")
 
 	cmd.Flags().DurationVar(&args.timeout, "timeout", 0, "cli task timeout, 0 for no timeout")
 }
@@ -103,6 +102,8 @@ var (
 
 func init() {
 	bindUploadFlags(uploadCmd, &uploadArgs)
+	uploadCmd.Flags().Lookup("file").Usage = "File name or HTTP(S) URL to upload, the server of URL should support range requests"
+	uploadCmd.MarkFlagRequired("file")
 	bindTransactionFlags(uploadCmd, &uploadArgs.transactionArgument)
 
 	rootCmd.AddCommand(uploadCmd)
@@ -151,11 +152,11 @@ func upload(*cobra.Command, []string) {
 		Method:           uploadArgs.method,
 	}
 
-	file, err := core.Open(uploadArgs.file)
+	file, closeFile, err := openUploadData(uploadArgs.file)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to open file")
 	}
-	defer file.Close()
+	defer closeFile()
 
 	uploader, closer, err := newUploader(ctx, file.NumSegments(), uploadArgs, w3client, opt)
 	if err != nil {
@@ -179,6 +180,25 @@ func upload(*cobra.Command, []string) {
 	}
 }
 
+// openUploadData opens the local file, or remote file via range requests if HTTP(S) URL specified.
+func openUploadData(name string) (core.IterableData, func(), error) {
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		data, err := core.NewHTTPRangeData(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return data, func() {}, nil
+	}
+
+	file, err := core.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, func() { file.Close() }, nil
+}
+
 func newUploader(ctx context.Context, segNum uint64, args uploadArgument, w3client *web3go.Client, opt transfer.UploadOption) (*transfer.Uploader, func(), error) {
 	if args.indexer != "" {
 		indexerClient, err := indexer.NewClient(args.indexer, indexer.IndexerClientOption{
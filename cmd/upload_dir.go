@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"context"
+	"io"
+	"os"
 
 	"github.com/0glabs/0g-storage-client/common/blockchain"
 	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -12,6 +15,7 @@ import (
 
 var (
 	uploadDirArgs uploadArgument
+	uploadDirTar  string
 
 	uploadDirCmd = &cobra.Command{
 		Use:   "upload-dir",
@@ -27,6 +31,11 @@ func init() {
 	uploadDirCmd.Flags().StringVar(&uploadDirArgs.key, "key", "", "Private key to interact with smart contract")
 	uploadDirCmd.MarkFlagRequired("key")
 
+	// Either directory or tar archive is required
+	uploadDirCmd.Flags().StringVar(&uploadDirTar, "tar", "", "Tar archive of directory to upload, and \"-\" for stdin")
+	uploadDirCmd.MarkFlagsOneRequired("file", "tar")
+	uploadDirCmd.MarkFlagsMutuallyExclusive("file", "tar")
+
 	rootCmd.AddCommand(uploadDirCmd)
 }
 
@@ -61,7 +70,12 @@ func uploadDir(*cobra.Command, []string) {
 	defer closer()
 	uploader.WithRoutines(uploadArgs.routines)
 
-	txnHash, rootHash, err := uploader.UploadDir(ctx, uploadDirArgs.file, opt)
+	var txnHash, rootHash common.Hash
+	if uploadDirTar != "" {
+		txnHash, rootHash, err = uploadTar(ctx, uploader, opt)
+	} else {
+		txnHash, rootHash, err = uploader.UploadDir(ctx, uploadDirArgs.file, opt)
+	}
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to upload directory")
 	}
@@ -71,3 +85,18 @@ func uploadDir(*cobra.Command, []string) {
 		"rootHash": rootHash,
 	}).Info("Directory uploaded done")
 }
+
+func uploadTar(ctx context.Context, uploader *transfer.Uploader, opt transfer.UploadOption) (common.Hash, common.Hash, error) {
+	var r io.Reader = os.Stdin
+	if uploadDirTar != "-" {
+		file, err := os.Open(uploadDirTar)
+		if err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	return uploader.UploadTar(ctx, r, opt)
+}
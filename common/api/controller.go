@@ -23,13 +23,16 @@ func Wrap(controller func(c *gin.Context) (interface{}, error)) gin.HandlerFunc
 			case *BusinessError:
 				// custom business error
 				if e != ErrHandled {
+					c.Set(contextKeyCode, e.Code)
 					c.JSON(http.StatusOK, e)
 				}
 			case validator.ValidationErrors:
 				// binding error
+				c.Set(contextKeyCode, ErrValidation.Code)
 				c.JSON(http.StatusOK, ErrValidation.WithData(e.Error()))
 			default:
 				// internal server error
+				c.Set(contextKeyCode, ErrInternal.Code)
 				c.JSON(httpStatusCodeInternalError, ErrInternal.WithData(e.Error()))
 			}
 		} else if result == nil {
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsPath        = "/metrics"
+	contextKeyCode     = "api.code"
+	routeNotFoundLabel = "<not found>"
+)
+
+// routeMetrics collects the latency and business error codes of REST routes.
+type routeMetrics struct {
+	latency *prometheus.HistogramVec // labels: method, route, status
+	errors  *prometheus.CounterVec   // labels: method, route, code
+}
+
+func newRouteMetrics(registerer prometheus.Registerer) *routeMetrics {
+	metrics := routeMetrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "api_request_duration_seconds",
+			Help:    "Latency of REST API requests in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "api_request_errors_total",
+			Help: "Number of REST API requests failed with business error code.",
+		}, []string{"method", "route", "code"}),
+	}
+
+	registerer.MustRegister(metrics.latency, metrics.errors)
+
+	return &metrics
+}
+
+// middleware observes the latency and error code of every request.
+func (m *routeMetrics) middleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	route := c.FullPath()
+	if len(route) == 0 {
+		route = routeNotFoundLabel
+	}
+
+	status := strconv.Itoa(c.Writer.Status())
+	m.latency.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+
+	if code := c.GetInt(contextKeyCode); code != ErrNil.Code {
+		m.errors.WithLabelValues(c.Request.Method, route, strconv.Itoa(code)).Inc()
+	}
+}
+
+// registerMetrics serves metrics of the specified registry at `/metrics`, and observes all REST routes.
+func registerMetrics(router *gin.Engine, registry *prometheus.Registry) {
+	metrics := newRouteMetrics(registry)
+	router.Use(metrics.middleware)
+
+	router.GET(metricsPath, gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+}
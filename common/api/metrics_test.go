@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	router := newRouter(func(router *gin.Engine) {
+		router.GET("/ok", Wrap(func(c *gin.Context) (interface{}, error) { return "ok", nil }))
+		router.GET("/item/:id", Wrap(func(c *gin.Context) (interface{}, error) { return nil, ErrValidation }))
+	}, RouterOption{Metrics: registry})
+
+	for _, path := range []string{"/ok", "/item/1", "/item/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 3, testutil.CollectAndCount(registry, "api_request_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "api_request_errors_total"))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `api_request_errors_total{code="1",method="GET",route="/item/:id"} 2`)
+	assert.Contains(t, recorder.Body.String(), `route="<not found>",status="404"`)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,6 +15,9 @@ type RouterOption struct {
 	RecoveryDisabled bool
 	LoggerForced     bool
 	OriginsAllowed   []string
+
+	// Metrics enables to serve metrics at `/metrics`, including the latency and error codes of REST routes.
+	Metrics *prometheus.Registry
 }
 
 func MustServe(endpoint string, factory RouteFactory, option ...RouterOption) {
@@ -51,6 +55,10 @@ func newRouter(factory RouteFactory, option ...RouterOption) *gin.Engine {
 		router.Use(gin.Logger())
 	}
 
+	if opt.Metrics != nil {
+		registerMetrics(router, opt.Metrics)
+	}
+
 	factory(router)
 
 	return router
@@ -0,0 +1,207 @@
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Pool is a set of worker routines shared by multiple jobs, e.g. merkle tree computations of many files,
+// so as to bound the total CPU usage in process.
+//
+// Tasks of different jobs are dispatched in a round-robin way, so that a small job will not be starved
+// by a large one. Besides, each job has a bounded window of tasks in flight, which requires bounded memory
+// to collect results in sequence.
+type Pool struct {
+	mu       sync.Mutex
+	cond     *sync.Cond // signaled when any task could be dispatched
+	drained  *sync.Cond // signaled when all tasks in execution of a removed job completed
+	routines int        // expected number of worker routines
+	workers  []bool     // whether worker routine of index is running
+	jobs     []*poolJob
+	cursor   int // index of job to dispatch task in round-robin way
+}
+
+type poolJob struct {
+	ctx            context.Context
+	parallelizable Interface
+	tasks          int
+	window         int
+	next           int // next task to dispatch
+	collected      int // number of tasks collected
+	running        int // number of tasks in execution
+	removed        bool
+	resultCh       chan *Result
+}
+
+// ready indicates whether any task could be dispatched for execution.
+func (job *poolJob) ready() bool {
+	return job.next < job.tasks && job.next-job.collected < job.window && job.ctx.Err() == nil
+}
+
+// NewPool creates a new pool with specified number of worker routines, GOMAXPROCS by default.
+func NewPool(routines int) *Pool {
+	pool := &Pool{}
+	pool.cond = sync.NewCond(&pool.mu)
+	pool.drained = sync.NewCond(&pool.mu)
+	pool.SetRoutines(routines)
+	return pool
+}
+
+// Routines returns the number of worker routines.
+func (pool *Pool) Routines() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.routines
+}
+
+// SetRoutines changes the number of worker routines, GOMAXPROCS if routines <= 0. Note, worker
+// routines whose indices exceed the new limit will terminate after the current task completed, so
+// that the routine indices of running workers are always unique and less than the limit.
+func (pool *Pool) SetRoutines(routines int) {
+	if routines <= 0 {
+		routines = runtime.GOMAXPROCS(0)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.routines = routines
+
+	for routine := 0; routine < pool.routines; routine++ {
+		if routine == len(pool.workers) {
+			pool.workers = append(pool.workers, false)
+		}
+
+		// worker may be still running if not terminated yet
+		if !pool.workers[routine] {
+			pool.workers[routine] = true
+			go pool.work(routine)
+		}
+	}
+
+	// wake up idle routines to terminate if necessary
+	pool.cond.Broadcast()
+}
+
+// Serial executes tasks with worker routines of pool, and collects results in sequence as the
+// package function Serial does. It returns once all tasks are collected, any error occurred or
+// the context is done.
+func (pool *Pool) Serial(ctx context.Context, parallelizable Interface, tasks int) error {
+	if tasks <= 0 {
+		return nil
+	}
+
+	pool.mu.Lock()
+	job := &poolJob{
+		ctx:            ctx,
+		parallelizable: parallelizable,
+		tasks:          tasks,
+		window:         min(2*pool.routines, tasks),
+	}
+	job.resultCh = make(chan *Result, job.window)
+	pool.jobs = append(pool.jobs, job)
+	pool.cond.Broadcast()
+	pool.mu.Unlock()
+
+	defer pool.remove(job)
+
+	cache := map[int]*Result{}
+
+	for next := 0; next < tasks; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-job.resultCh:
+			if result.err != nil {
+				return result.err
+			}
+
+			cache[result.Task] = result
+
+			// handle task in sequence
+			for ; cache[next] != nil; next++ {
+				if err := parallelizable.ParallelCollect(cache[next]); err != nil {
+					return err
+				}
+
+				delete(cache, next)
+
+				// move window forward
+				pool.mu.Lock()
+				job.collected++
+				pool.cond.Signal()
+				pool.mu.Unlock()
+			}
+		}
+	}
+
+	return nil
+}
+
+// remove removes the job from pool, and waits for the tasks in execution to complete.
+func (pool *Pool) remove(job *poolJob) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for i := range pool.jobs {
+		if pool.jobs[i] == job {
+			pool.jobs = append(pool.jobs[:i], pool.jobs[i+1:]...)
+			break
+		}
+	}
+
+	job.removed = true
+
+	for job.running > 0 {
+		pool.drained.Wait()
+	}
+}
+
+// dispatch selects the next task to execute in round-robin way, and should be called with lock held.
+func (pool *Pool) dispatch() (*poolJob, int, bool) {
+	for i := 0; i < len(pool.jobs); i++ {
+		index := (pool.cursor + i) % len(pool.jobs)
+		if job := pool.jobs[index]; job.ready() {
+			pool.cursor = index + 1
+			job.next++
+			job.running++
+			return job, job.next - 1, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+func (pool *Pool) work(routine int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for {
+		// terminate if routines decreased
+		if routine >= pool.routines {
+			pool.workers[routine] = false
+			return
+		}
+
+		job, task, ok := pool.dispatch()
+		if !ok {
+			pool.cond.Wait()
+			continue
+		}
+
+		pool.mu.Unlock()
+		val, err := job.parallelizable.ParallelDo(job.ctx, routine, task)
+		// never blocked, since the number of tasks in flight is limited by window
+		job.resultCh <- &Result{routine, task, val, err}
+		pool.mu.Lock()
+
+		job.running--
+
+		// wake up the job that waits for removal
+		if job.removed && job.running == 0 {
+			pool.drained.Broadcast()
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowFoo struct {
+	foo
+	delay time.Duration
+}
+
+func (f *slowFoo) ParallelDo(ctx context.Context, routine, task int) (interface{}, error) {
+	time.Sleep(f.delay)
+	return f.foo.ParallelDo(ctx, routine, task)
+}
+
+func TestPoolSerial(t *testing.T) {
+	pool := NewPool(4)
+
+	var wg sync.WaitGroup
+
+	// run jobs of different sizes concurrently
+	for _, tasks := range []int{1, 10, 100, 1000} {
+		wg.Add(1)
+		go func(tasks int) {
+			defer wg.Done()
+
+			f := foo{t, nil}
+			assert.Nil(t, pool.Serial(context.Background(), &f, tasks))
+			assert.Equal(t, tasks, len(f.result))
+		}(tasks)
+	}
+
+	wg.Wait()
+}
+
+func TestPoolFairness(t *testing.T) {
+	pool := NewPool(2)
+
+	large := slowFoo{foo{t, nil}, time.Millisecond}
+	largeDone := make(chan error, 1)
+	go func() {
+		largeDone <- pool.Serial(context.Background(), &large, 1000)
+	}()
+
+	// small job should not wait for the large one to complete
+	time.Sleep(10 * time.Millisecond)
+	small := slowFoo{foo{t, nil}, time.Millisecond}
+	assert.Nil(t, pool.Serial(context.Background(), &small, 10))
+	assert.Equal(t, 10, len(small.result))
+
+	select {
+	case <-largeDone:
+		assert.Fail(t, "large job should not complete before the small one")
+	default:
+	}
+
+	assert.Nil(t, <-largeDone)
+	assert.Equal(t, 1000, len(large.result))
+}
+
+func TestPoolCancel(t *testing.T) {
+	pool := NewPool(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	f := slowFoo{foo{t, nil}, time.Millisecond}
+	err := pool.Serial(ctx, &f, 10000)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, len(f.result), 10000)
+
+	// pool is still available after cancellation
+	f = slowFoo{foo{t, nil}, 0}
+	assert.Nil(t, pool.Serial(context.Background(), &f, 100))
+	assert.Equal(t, 100, len(f.result))
+}
+
+func TestPoolSetRoutines(t *testing.T) {
+	pool := NewPool(8)
+	pool.SetRoutines(1)
+	assert.Equal(t, 1, pool.Routines())
+
+	f := foo{t, nil}
+	assert.Nil(t, pool.Serial(context.Background(), &f, 100))
+	assert.Equal(t, 100, len(f.result))
+
+	pool.SetRoutines(4)
+	assert.Equal(t, 4, pool.Routines())
+
+	f = foo{t, nil}
+	assert.Nil(t, pool.Serial(context.Background(), &f, 100))
+	assert.Equal(t, 100, len(f.result))
+}
+
+// routineFoo fails if any routine index is out of range or used by multiple tasks concurrently.
+type routineFoo struct {
+	foo
+	mu       sync.Mutex
+	routines int
+	running  map[int]bool
+}
+
+func (f *routineFoo) ParallelDo(ctx context.Context, routine, task int) (interface{}, error) {
+	f.mu.Lock()
+	if routine < 0 || routine >= f.routines || f.running[routine] {
+		f.mu.Unlock()
+		return nil, errors.New("invalid or duplicate routine index")
+	}
+	f.running[routine] = true
+	f.mu.Unlock()
+
+	time.Sleep(100 * time.Microsecond)
+
+	f.mu.Lock()
+	delete(f.running, routine)
+	f.mu.Unlock()
+
+	return f.foo.ParallelDo(ctx, routine, task)
+}
+
+func TestPoolRoutineIndex(t *testing.T) {
+	pool := NewPool(4)
+
+	for i := 0; i < 10; i++ {
+		// let the idle routines terminate before grow
+		pool.SetRoutines(1)
+		time.Sleep(time.Millisecond)
+		pool.SetRoutines(4)
+
+		f := routineFoo{foo: foo{t, nil}, routines: 4, running: make(map[int]bool)}
+		assert.Nil(t, pool.Serial(context.Background(), &f, 100))
+		assert.Equal(t, 100, len(f.result))
+	}
+}
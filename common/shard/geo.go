@@ -0,0 +1,153 @@
+package shard
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Geo-aware methods to select nodes.
+const (
+	// MethodNearest selects nodes nearest to the specified coordinates, e.g. "nearest:31.23,121.47". If
+	// coordinates not specified, nodes are selected in ascending order of latency.
+	MethodNearest = "nearest"
+
+	// MethodDiverse selects nodes across distinct countries as much as possible, or distinct regions if
+	// specified as "diverse:region".
+	MethodDiverse = "diverse"
+)
+
+const earthRadiusKm = 6371
+
+// Location is the geographic location of storage node.
+type Location struct {
+	Country   string  `json:"country"`
+	Region    string  `json:"region"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ParseCoordinates parses coordinates in format of "latitude,longitude".
+func ParseCoordinates(coordinates string) (*Location, error) {
+	fields := strings.Split(coordinates, ",")
+	if len(fields) != 2 {
+		return nil, errors.Errorf("Invalid coordinates %v, latitude,longitude expected", coordinates)
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, errors.Errorf("Invalid latitude %v", fields[0])
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, errors.Errorf("Invalid longitude %v", fields[1])
+	}
+
+	return &Location{Latitude: lat, Longitude: lng}, nil
+}
+
+// Distance returns the great-circle distance in kilometers between two locations.
+func (loc *Location) Distance(other *Location) float64 {
+	lat1, lat2 := loc.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude - loc.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// prepareGeoSelectionNodes sorts nodes for geo-aware methods, and returns false if method is not geo-aware.
+// As other invalid methods, empty slice is returned if the argument of method is invalid.
+func prepareGeoSelectionNodes(nodes []*ShardedNode, method string) ([]*ShardedNode, bool) {
+	kind, arg, hasArg := strings.Cut(method, ":")
+
+	switch kind {
+	case MethodNearest:
+		if !hasArg {
+			sortByLatency(nodes)
+			break
+		}
+
+		origin, err := ParseCoordinates(arg)
+		if err != nil {
+			return []*ShardedNode{}, true
+		}
+
+		sortByDistance(nodes, origin)
+	case MethodDiverse:
+		if hasArg && arg != "region" {
+			return []*ShardedNode{}, true
+		}
+
+		nodes = interleaveByArea(nodes, hasArg)
+	default:
+		return nil, false
+	}
+
+	return nodes, true
+}
+
+func sortByLatency(nodes []*ShardedNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Latency < nodes[j].Latency
+	})
+}
+
+// sortByDistance sorts nodes in ascending order of distance to origin, and nodes without location at last.
+func sortByDistance(nodes []*ShardedNode, origin *Location) {
+	distance := func(node *ShardedNode) float64 {
+		if node.Location == nil {
+			return math.MaxFloat64
+		}
+
+		return origin.Distance(node.Location)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return distance(nodes[i]) < distance(nodes[j])
+	})
+}
+
+// interleaveByArea picks nodes from distinct countries or regions in turn, so that replicas are spread across
+// areas. Nodes in the same area keep the original order, and nodes without location are placed at last.
+func interleaveByArea(nodes []*ShardedNode, byRegion bool) []*ShardedNode {
+	var areas []string
+	groups := make(map[string][]*ShardedNode)
+	var unknown []*ShardedNode
+
+	for _, v := range nodes {
+		if v.Location == nil {
+			unknown = append(unknown, v)
+			continue
+		}
+
+		area := v.Location.Country
+		if byRegion {
+			area += "/" + v.Location.Region
+		}
+
+		if _, ok := groups[area]; !ok {
+			areas = append(areas, area)
+		}
+
+		groups[area] = append(groups[area], v)
+	}
+
+	result := make([]*ShardedNode, 0, len(nodes))
+
+	for len(result)+len(unknown) < len(nodes) {
+		for _, area := range areas {
+			if group := groups[area]; len(group) > 0 {
+				result = append(result, group[0])
+				groups[area] = group[1:]
+			}
+		}
+	}
+
+	return append(result, unknown...)
+}
@@ -0,0 +1,78 @@
+package shard
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func makeGeoShardNode(url string, numShard uint, country, region string, lat, lng float64) *ShardedNode {
+	node := makeShardNode(numShard, 0)
+	node.URL = url
+	node.Location = &Location{country, region, lat, lng}
+	return node
+}
+
+func selectedURLs(nodes []*ShardedNode) []string {
+	var urls []string
+	for _, v := range nodes {
+		urls = append(urls, v.URL)
+	}
+	return urls
+}
+
+func TestSelectNearest(t *testing.T) {
+	nodes := []*ShardedNode{
+		makeGeoShardNode("tokyo", 1, "JP", "Tokyo", 35.68, 139.69),
+		makeGeoShardNode("frankfurt", 1, "DE", "Hesse", 50.11, 8.68),
+		makeGeoShardNode("shanghai", 1, "CN", "Shanghai", 31.23, 121.47),
+		{URL: "unknown", Config: ShardConfig{NumShard: 1}},
+	}
+
+	selected, ok := Select(append([]*ShardedNode{}, nodes...), 2, "nearest:39.90,116.40")
+	assert.Equal(t, ok, true)
+	assert.DeepEqual(t, selectedURLs(selected), []string{"shanghai", "tokyo"})
+
+	_, err := ParseCoordinates("91,0")
+	assert.ErrorContains(t, err, "Invalid latitude")
+
+	// invalid coordinates
+	_, ok = Select(append([]*ShardedNode{}, nodes...), 2, "nearest:39.90")
+	assert.Equal(t, ok, false)
+
+	// select by latency if coordinates not specified
+	nodes[0].Latency, nodes[1].Latency, nodes[2].Latency, nodes[3].Latency = 30, 10, 40, 20
+	selected, ok = Select(nodes, 2, MethodNearest)
+	assert.Equal(t, ok, true)
+	assert.DeepEqual(t, selectedURLs(selected), []string{"frankfurt", "unknown"})
+}
+
+func TestSelectDiverse(t *testing.T) {
+	nodes := []*ShardedNode{
+		makeGeoShardNode("us-1", 1, "US", "California", 0, 0),
+		makeGeoShardNode("us-2", 1, "US", "Virginia", 0, 0),
+		makeGeoShardNode("us-3", 1, "US", "California", 0, 0),
+		makeGeoShardNode("de-1", 1, "DE", "Hesse", 0, 0),
+		{URL: "unknown", Config: ShardConfig{NumShard: 1}},
+		makeGeoShardNode("sg-1", 1, "SG", "Singapore", 0, 0),
+	}
+
+	selected, ok := Select(append([]*ShardedNode{}, nodes...), 3, MethodDiverse)
+	assert.Equal(t, ok, true)
+	assert.DeepEqual(t, selectedURLs(selected), []string{"us-1", "de-1", "sg-1"})
+
+	selected, ok = Select(append([]*ShardedNode{}, nodes...), 5, MethodDiverse)
+	assert.Equal(t, ok, true)
+	assert.DeepEqual(t, selectedURLs(selected), []string{"us-1", "de-1", "sg-1", "us-2", "us-3"})
+
+	selected, ok = Select(append([]*ShardedNode{}, nodes...), 2, MethodDiverse+":region")
+	assert.Equal(t, ok, true)
+	assert.DeepEqual(t, selectedURLs(selected), []string{"us-1", "us-2"})
+
+	// unknown area
+	_, ok = Select(append([]*ShardedNode{}, nodes...), 2, MethodDiverse+":city")
+	assert.Equal(t, ok, false)
+
+	// shard configs without location are acceptable
+	assert.Equal(t, CheckReplica([]*ShardConfig{{NumShard: 2, ShardId: 0}, {NumShard: 2, ShardId: 1}}, 1, MethodDiverse), true)
+}
@@ -39,6 +39,8 @@ type ShardedNode struct {
 	Latency int64 `json:"latency"`
 	// Since last updated timestamp.
 	Since int64 `json:"since"`
+	// Location geographic location of node if any, which is used for geo-aware selection.
+	Location *Location `json:"location,omitempty"`
 }
 
 func NewShardNodesFromConfig(configs []*ShardConfig) []*ShardedNode {
@@ -129,6 +131,10 @@ func CheckReplica(shardConfigs []*ShardConfig, expectedReplica uint, method stri
 
 // Helper function to pre-process (sort or shuffle) the nodes before selection
 func prepareSelectionNodes(nodes []*ShardedNode, method string) []*ShardedNode {
+	if geoNodes, ok := prepareGeoSelectionNodes(nodes, method); ok {
+		return geoNodes
+	}
+
 	if method == "random" {
 		util.Shuffle(nodes)
 	} else if method == "max" {
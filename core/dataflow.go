@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"errors"
+	"runtime"
 
 	"github.com/0glabs/0g-storage-client/common/parallel"
 	"github.com/0glabs/0g-storage-client/core/merkle"
@@ -19,6 +20,10 @@ const (
 
 	// DefaultSegmentSize represents the default segment size in bytes.
 	DefaultSegmentSize = DefaultChunkSize * DefaultSegmentMaxChunks
+
+	// streamingTreeMinSegments is the minimum number of segments to build merkle tree in a streaming way,
+	// which only retains segment roots in memory.
+	streamingTreeMinSegments = 1024
 )
 
 var (
@@ -26,6 +31,16 @@ var (
 	EmptyChunkHash = crypto.Keccak256Hash(EmptyChunk)
 )
 
+// hashPool is shared by all merkle tree computations in process to avoid oversubscribing CPUs,
+// e.g. when hashing thousands of files concurrently.
+var hashPool = parallel.NewPool(runtime.GOMAXPROCS(0))
+
+// SetHashRoutines sets the number of routines shared by all merkle tree computations in process,
+// which is GOMAXPROCS by default.
+func SetHashRoutines(routines int) {
+	hashPool.SetRoutines(routines)
+}
+
 // IterableData defines the data interface to upload to 0g storage network.
 type IterableData interface {
 	NumChunks() uint64
@@ -37,24 +52,61 @@ type IterableData interface {
 	Split(fragmentSize int64) []IterableData
 }
 
-// MerkleTree create merkle tree of the data.
+// MerkleTree create merkle tree of the data. For large data, only segment roots are retained in memory.
 func MerkleTree(data IterableData) (*merkle.Tree, error) {
-	var builder merkle.TreeBuilder
-	initializer := &TreeBuilderInitializer{
-		data:    data,
-		offset:  0,
-		batch:   DefaultSegmentSize,
-		builder: &builder,
+	return MerkleTreeContext(context.Background(), data)
+}
+
+// MerkleTreeContext is the same as MerkleTree, but could be cancelled by context.
+func MerkleTreeContext(ctx context.Context, data IterableData) (*merkle.Tree, error) {
+	return merkleTree(ctx, data, NumSegmentsPadded(data) >= streamingTreeMinSegments)
+}
+
+func merkleTree(ctx context.Context, data IterableData, streaming bool) (*merkle.Tree, error) {
+	if !streaming {
+		var builder merkle.TreeBuilder
+		if err := appendSegmentRoots(ctx, data, &builder); err != nil {
+			return nil, err
+		}
+
+		return builder.Build(), nil
 	}
 
-	err := parallel.Serial(context.Background(), initializer, NumSegmentsPadded(data))
-	if err != nil {
+	builder := merkle.NewStreamTreeBuilder(true)
+	if err := appendSegmentRoots(ctx, data, builder); err != nil {
 		return nil, err
 	}
 
 	return builder.Build(), nil
 }
 
+// MerkleRootOf computes the merkle root of the data in a streaming way, which requires bounded memory only.
+func MerkleRootOf(data IterableData) (common.Hash, error) {
+	return MerkleRootOfContext(context.Background(), data)
+}
+
+// MerkleRootOfContext is the same as MerkleRootOf, but could be cancelled by context.
+func MerkleRootOfContext(ctx context.Context, data IterableData) (common.Hash, error) {
+	builder := merkle.NewStreamTreeBuilder(false)
+	if err := appendSegmentRoots(ctx, data, builder); err != nil {
+		return common.Hash{}, err
+	}
+
+	return builder.Root(), nil
+}
+
+// appendSegmentRoots computes segment roots of the padded data with the shared hash pool, and appends to builder in sequence.
+func appendSegmentRoots(ctx context.Context, data IterableData, builder hashAppender) error {
+	initializer := &TreeBuilderInitializer{
+		data:    data,
+		offset:  0,
+		batch:   DefaultSegmentSize,
+		builder: builder,
+	}
+
+	return hashPool.Serial(ctx, initializer, NumSegmentsPadded(data))
+}
+
 func NumSplits(total int64, unit int) uint64 {
 	return uint64((total-1)/int64(unit) + 1)
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"math/rand"
 	"os"
 	"testing"
@@ -38,3 +39,34 @@ func TestFileAndInMemoryData(t *testing.T) {
 
 	assert.Equal(t, fileTree.Root(), inMemTree.Root())
 }
+
+func TestStreamingMerkleTree(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for _, size := range []int{1, DefaultChunkSize + 1, DefaultSegmentSize*10 + 10, DefaultSegmentSize * 33} {
+		data := make([]byte, size)
+		_, err := r.Read(data)
+		assert.NoError(t, err)
+
+		inMem, err := NewDataInMemory(data)
+		assert.NoError(t, err)
+
+		expected, err := merkleTree(context.Background(), inMem, false)
+		assert.NoError(t, err)
+
+		actual, err := merkleTree(context.Background(), inMem, true)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Root(), actual.Root())
+
+		root, err := MerkleRootOf(inMem)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Root(), root)
+
+		numSegments := NumSegmentsPadded(inMem)
+		assert.Equal(t, numSegments, actual.NumLeafNodes())
+
+		for i := 0; i < numSegments; i++ {
+			assert.Equal(t, expected.ProofAt(i), actual.ProofAt(i))
+		}
+	}
+}
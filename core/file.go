@@ -88,14 +88,13 @@ func MerkleRoot(filename string) (common.Hash, error) {
 	}
 	defer file.Close()
 
-	// Generate the Merkle tree from the file content
-	tree, err := MerkleTree(file)
+	// Compute the Merkle root from the file content
+	root, err := MerkleRootOf(file)
 	if err != nil {
-		return common.Hash{}, errors.WithMessage(err, "failed to create merkle tree")
+		return common.Hash{}, errors.WithMessage(err, "failed to compute merkle root")
 	}
 
-	// Return the root hash of the Merkle tree
-	return tree.Root(), nil
+	return root, nil
 }
 
 func (file *File) Close() error {
@@ -6,7 +6,6 @@ import (
 	"math/big"
 
 	"github.com/0glabs/0g-storage-client/common"
-	"github.com/0glabs/0g-storage-client/common/parallel"
 	"github.com/0glabs/0g-storage-client/contract"
 	"github.com/0glabs/0g-storage-client/core/merkle"
 	"github.com/sirupsen/logrus"
@@ -106,15 +105,16 @@ func (flow *Flow) createNode(offset, chunks int64) (*contract.SubmissionNode, er
 }
 
 func (flow *Flow) createSegmentNode(offset, batch, size int64) (*contract.SubmissionNode, error) {
-	var builder merkle.TreeBuilder
+	// only root required, so build in a streaming way
+	builder := merkle.NewStreamTreeBuilder(false)
 	initializer := &TreeBuilderInitializer{
 		data:    flow.data,
 		offset:  offset,
 		batch:   batch,
-		builder: &builder,
+		builder: builder,
 	}
 
-	err := parallel.Serial(context.Background(), initializer, int((size-1)/batch+1))
+	err := hashPool.Serial(context.Background(), initializer, int((size-1)/batch+1))
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +123,7 @@ func (flow *Flow) createSegmentNode(offset, batch, size int64) (*contract.Submis
 	height := int64(math.Log2(float64(numChunks)))
 
 	return &contract.SubmissionNode{
-		Root:   builder.Build().Root(),
+		Root:   builder.Root(),
 		Height: big.NewInt(height),
 	}, nil
 }
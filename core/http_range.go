@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/pkg/errors"
+)
+
+// HTTPRangeOption is the option to read data from HTTP(S) URL.
+type HTTPRangeOption struct {
+	Client        *http.Client  // HTTP client, default client with 30 seconds timeout if not specified
+	Retries       int           // number of retries for a failed range request, default 3
+	RetryInterval time.Duration // interval between retries, default 1 second
+	BlockSize     int64         // size of each range request, default to segment size
+	CacheBlocks   int           // number of blocks to cache, default 32
+}
+
+// httpRangeSource is the remote data shared by HTTPRangeData and its fragments.
+type httpRangeSource struct {
+	url    string
+	size   int64
+	option HTTPRangeOption
+	cache  *lru.Cache[int64, []byte] // block index -> block data
+}
+
+// HTTPRangeData implement of IterableData, the underlying is a file served via HTTP(S), which is
+// read by range requests on demand.
+type HTTPRangeData struct {
+	source     *httpRangeSource
+	offset     int64
+	size       int64
+	paddedSize uint64
+}
+
+var _ IterableData = (*HTTPRangeData)(nil)
+
+// NewHTTPRangeData creates HTTPRangeData from given URL, which requires the server supports range requests.
+func NewHTTPRangeData(url string, option ...HTTPRangeOption) (*HTTPRangeData, error) {
+	var opt HTTPRangeOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if opt.Retries <= 0 {
+		opt.Retries = 3
+	}
+
+	if opt.RetryInterval <= 0 {
+		opt.RetryInterval = time.Second
+	}
+
+	if opt.BlockSize <= 0 {
+		opt.BlockSize = DefaultSegmentSize
+	}
+
+	if opt.CacheBlocks <= 0 {
+		opt.CacheBlocks = 32
+	}
+
+	cache, err := lru.New[int64, []byte](opt.CacheBlocks)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create cache")
+	}
+
+	source := &httpRangeSource{
+		url:    url,
+		option: opt,
+		cache:  cache,
+	}
+
+	// query the total size by requesting the first byte
+	if err = source.retry(func() error {
+		_, total, err := source.get(0, 0)
+		source.size = total
+		return err
+	}); err != nil {
+		return nil, errors.WithMessage(err, "failed to query data size")
+	}
+
+	if source.size == 0 {
+		return nil, errors.New("data is empty")
+	}
+
+	return &HTTPRangeData{
+		source:     source,
+		offset:     0,
+		size:       source.size,
+		paddedSize: IteratorPaddedSize(source.size, true),
+	}, nil
+}
+
+// get requests data in range [start, end], and returns the data along with total size.
+func (source *httpRangeSource) get(start, end int64) ([]byte, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, source.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%v-%v", start, end))
+
+	resp, err := source.option.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, 0, errors.Errorf("unexpected status %v, range request may be not supported", resp.Status)
+	}
+
+	// e.g. bytes 0-1023/4096
+	contentRange := resp.Header.Get("Content-Range")
+	index := strings.LastIndex(contentRange, "/")
+	if index < 0 {
+		return nil, 0, errors.Errorf("invalid content range %v", contentRange)
+	}
+
+	total, err := strconv.ParseInt(contentRange[index+1:], 10, 64)
+	if err != nil {
+		return nil, 0, errors.Errorf("unknown total size from content range %v", contentRange)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if expected := min(end, total-1) - start + 1; int64(len(data)) != expected {
+		return nil, 0, errors.Errorf("data length mismatch, expected = %v, actual = %v", expected, len(data))
+	}
+
+	return data, total, nil
+}
+
+func (source *httpRangeSource) retry(action func() error) (err error) {
+	for i := 0; i <= source.option.Retries; i++ {
+		if i > 0 {
+			time.Sleep(source.option.RetryInterval)
+		}
+
+		if err = action(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// block returns the data block of specified index from cache, or requests from server if not cached.
+func (source *httpRangeSource) block(index int64) ([]byte, error) {
+	if data, ok := source.cache.Get(index); ok {
+		return data, nil
+	}
+
+	start := index * source.option.BlockSize
+	end := min(start+source.option.BlockSize, source.size) - 1
+
+	var data []byte
+	if err := source.retry(func() (err error) {
+		data, _, err = source.get(start, end)
+		return err
+	}); err != nil {
+		return nil, errors.WithMessagef(err, "failed to request data in range [%v, %v]", start, end)
+	}
+
+	source.cache.Add(index, data)
+
+	return data, nil
+}
+
+func (data *HTTPRangeData) Read(buf []byte, offset int64) (int, error) {
+	start := data.offset + offset
+	end := min(start+int64(len(buf)), data.offset+data.size)
+	blockSize := data.source.option.BlockSize
+
+	var n int
+
+	for pos := start; pos < end; {
+		block, err := data.source.block(pos / blockSize)
+		if err != nil {
+			return 0, err
+		}
+
+		copied := copy(buf[n:end-start], block[pos%blockSize:])
+		n += copied
+		pos += int64(copied)
+	}
+
+	return n, nil
+}
+
+func (data *HTTPRangeData) NumChunks() uint64 {
+	return NumSplits(data.size, DefaultChunkSize)
+}
+
+func (data *HTTPRangeData) NumSegments() uint64 {
+	return NumSplits(data.size, DefaultSegmentSize)
+}
+
+func (data *HTTPRangeData) PaddedSize() uint64 {
+	return data.paddedSize
+}
+
+func (data *HTTPRangeData) Size() int64 {
+	return data.size
+}
+
+func (data *HTTPRangeData) Offset() int64 {
+	return data.offset
+}
+
+func (data *HTTPRangeData) Split(fragmentSize int64) []IterableData {
+	fragments := make([]IterableData, 0)
+	for offset := int64(0); offset < data.size; offset += fragmentSize {
+		size := min(data.size-offset, fragmentSize)
+		fragment := &HTTPRangeData{
+			source:     data.source,
+			offset:     data.offset + offset,
+			size:       size,
+			paddedSize: IteratorPaddedSize(size, true),
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
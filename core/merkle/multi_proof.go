@@ -0,0 +1,264 @@
+package merkle
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var errProofPositionsInvalid = errors.New("merkle proof positions should be in ascending order and less than the number of leaf nodes")
+
+// HashSource provides node hashes of a binary merkle tree by level, where level 0 is the leaf level,
+// and the last single node of each level is moved up directly as TreeBuilder does.
+type HashSource interface {
+	HashAt(level int, index uint64) (common.Hash, error)
+}
+
+// MultiProof represents a merkle tree proof of multiple target contents, e.g. a range of chunks of file,
+// in which sibling nodes shared by the target contents are deduplicated.
+type MultiProof struct {
+	// Lemma is made up of 3 parts to keep consistent with Proof:
+	// 1. Target content hashes (leaf nodes) in ascending order of positions.
+	// 2. Hashes of sibling nodes that could not be computed from target contents, from bottom to top,
+	//    and from left to right in the same level.
+	// 3. Root hash.
+	Lemma []common.Hash `json:"lemma"`
+
+	// Positions of the target contents in ascending order.
+	Positions []uint64 `json:"positions"`
+
+	// NumLeafNodes is the total number of leaf nodes of merkle tree.
+	NumLeafNodes uint64 `json:"numLeafNodes"`
+}
+
+// NewMultiProof generates proof for the specified leaf nodes, which should be in ascending order.
+func NewMultiProof(source HashSource, numLeafNodes uint64, positions []uint64) (*MultiProof, error) {
+	if !validatePositions(positions, numLeafNodes) {
+		return nil, errProofPositionsInvalid
+	}
+
+	proof := MultiProof{
+		Positions:    append([]uint64{}, positions...),
+		NumLeafNodes: numLeafNodes,
+	}
+
+	// append the target leaf node hashes
+	for _, pos := range positions {
+		hash, err := source.HashAt(0, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		proof.Lemma = append(proof.Lemma, hash)
+	}
+
+	var level int
+
+	// append sibling node hashes that could not be computed from target nodes
+	for known, size := positions, numLeafNodes; size > 1; known, size = parentPositions(known), (size+1)/2 {
+		for i := 0; i < len(known); i++ {
+			sibling, ok := siblingPosition(known, i, size)
+			if !ok {
+				// both nodes are known, or the last single node is moved up directly
+				if known[i]%2 == 0 && known[i]+1 < size {
+					i++
+				}
+				continue
+			}
+
+			hash, err := source.HashAt(level, sibling)
+			if err != nil {
+				return nil, err
+			}
+
+			proof.Lemma = append(proof.Lemma, hash)
+		}
+
+		level++
+	}
+
+	// append the root node hash
+	root, err := source.HashAt(level, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	proof.Lemma = append(proof.Lemma, root)
+
+	return &proof, nil
+}
+
+// NewRangeProof generates proof for the contiguous leaf nodes in range [start, end).
+func NewRangeProof(source HashSource, numLeafNodes, start, end uint64) (*MultiProof, error) {
+	if start >= end {
+		return nil, errProofPositionsInvalid
+	}
+
+	positions := make([]uint64, 0, end-start)
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+
+	return NewMultiProof(source, numLeafNodes, positions)
+}
+
+// siblingPosition returns the position of sibling node of known[i] if it is required in proof.
+func siblingPosition(known []uint64, i int, size uint64) (uint64, bool) {
+	pos := known[i]
+
+	if pos%2 == 1 {
+		// left sibling is known already
+		if i > 0 && known[i-1] == pos-1 {
+			return 0, false
+		}
+
+		return pos - 1, true
+	}
+
+	// last single node
+	if pos+1 >= size {
+		return 0, false
+	}
+
+	// right sibling is known already
+	if i+1 < len(known) && known[i+1] == pos+1 {
+		return 0, false
+	}
+
+	return pos + 1, true
+}
+
+// parentPositions returns the deduplicated positions of parent nodes.
+func parentPositions(known []uint64) []uint64 {
+	var parents []uint64
+
+	for _, pos := range known {
+		if n := len(parents); n == 0 || parents[n-1] != pos/2 {
+			parents = append(parents, pos/2)
+		}
+	}
+
+	return parents
+}
+
+func validatePositions(positions []uint64, numLeafNodes uint64) bool {
+	if len(positions) == 0 {
+		return false
+	}
+
+	for i, pos := range positions {
+		if pos >= numLeafNodes || (i > 0 && pos <= positions[i-1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (proof *MultiProof) validateFormat() error {
+	if !validatePositions(proof.Positions, proof.NumLeafNodes) {
+		return errProofPositionsInvalid
+	}
+
+	if len(proof.Lemma) < len(proof.Positions)+1 {
+		return errProofWrongFormat
+	}
+
+	return nil
+}
+
+// Validate validates the proof with target contents at the expected positions in ascending order, and the
+// expected number of leaf nodes of merkle tree.
+func (proof *MultiProof) Validate(root common.Hash, contents [][]byte, positions []uint64, numLeafNodes uint64) error {
+	contentHashes := make([]common.Hash, 0, len(contents))
+	for _, content := range contents {
+		contentHashes = append(contentHashes, crypto.Keccak256Hash(content))
+	}
+
+	return proof.ValidateHashes(root, contentHashes, positions, numLeafNodes)
+}
+
+// ValidateHashes validates the proof with target content hashes at the expected positions in ascending order,
+// and the expected number of leaf nodes of merkle tree.
+func (proof *MultiProof) ValidateHashes(root common.Hash, contentHashes []common.Hash, positions []uint64, numLeafNodes uint64) error {
+	if err := proof.validateFormat(); err != nil {
+		return err
+	}
+
+	// position mismatch, e.g. the same lemma is valid for different positions in trees of different sizes
+	if proof.NumLeafNodes != numLeafNodes || len(proof.Positions) != len(positions) {
+		return errProofPositionMismatch
+	}
+
+	for i, pos := range positions {
+		if proof.Positions[i] != pos {
+			return errProofPositionMismatch
+		}
+	}
+
+	// content hash mismatch
+	if len(contentHashes) != len(proof.Positions) {
+		return errProofContentMismatch
+	}
+
+	for i, hash := range contentHashes {
+		if hash != proof.Lemma[i] {
+			return errProofContentMismatch
+		}
+	}
+
+	// root mismatch
+	if root != proof.Lemma[len(proof.Lemma)-1] {
+		return errProofRootMismatch
+	}
+
+	// validate root by proof
+	if !proof.validateRoot() {
+		return errProofValidationFailure
+	}
+
+	return nil
+}
+
+func (proof *MultiProof) validateRoot() bool {
+	numTargets := len(proof.Positions)
+	siblings := proof.Lemma[numTargets : len(proof.Lemma)-1]
+	hashes := proof.Lemma[:numTargets]
+
+	for known, size := proof.Positions, proof.NumLeafNodes; size > 1; known, size = parentPositions(known), (size+1)/2 {
+		var parents []common.Hash
+
+		for i := 0; i < len(known); i++ {
+			pos := known[i]
+
+			if pos%2 == 0 && pos+1 >= size {
+				// the last single node is moved up directly
+				parents = append(parents, hashes[i])
+				continue
+			}
+
+			sibling, ok := siblingPosition(known, i, size)
+			if ok && len(siblings) == 0 {
+				return false
+			}
+
+			switch {
+			case ok && sibling < pos:
+				parents = append(parents, hashPair(siblings[0], hashes[i]))
+				siblings = siblings[1:]
+			case ok:
+				parents = append(parents, hashPair(hashes[i], siblings[0]))
+				siblings = siblings[1:]
+			default:
+				// right sibling is known
+				parents = append(parents, hashPair(hashes[i], hashes[i+1]))
+				i++
+			}
+		}
+
+		hashes = parents
+	}
+
+	return len(siblings) == 0 && len(hashes) == 1 && hashes[0] == proof.Lemma[len(proof.Lemma)-1]
+}
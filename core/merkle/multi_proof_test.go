@@ -0,0 +1,106 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func createChunkDataRange(positions []uint64) [][]byte {
+	var contents [][]byte
+	for _, pos := range positions {
+		contents = append(contents, createChunkData(int(pos)))
+	}
+
+	return contents
+}
+
+func TestRangeProof(t *testing.T) {
+	for numChunks := 1; numChunks <= 20; numChunks++ {
+		tree := createTreeByChunks(numChunks)
+		compactTree := createStreamBuilderByChunks(numChunks, true).Build()
+
+		for start := 0; start < numChunks; start++ {
+			for end := start + 1; end <= numChunks; end++ {
+				proof, err := tree.RangeProof(uint64(start), uint64(end))
+				assert.NoError(t, err)
+				assert.NoError(t, proof.Validate(tree.Root(), createChunkDataRange(proof.Positions), proof.Positions, uint64(numChunks)))
+
+				compactProof, err := compactTree.RangeProof(uint64(start), uint64(end))
+				assert.NoError(t, err)
+				assert.Equal(t, proof, compactProof)
+			}
+		}
+	}
+}
+
+func TestMultiProof(t *testing.T) {
+	tree := createTreeByChunks(13)
+
+	positions := []uint64{0, 1, 5, 7, 8, 12}
+	proof, err := tree.MultiProofAt(positions...)
+	assert.NoError(t, err)
+	assert.NoError(t, proof.Validate(tree.Root(), createChunkDataRange(positions), positions, 13))
+
+	// single position is identical to single proof except the duplicated leaf node
+	for i := 0; i < 13; i++ {
+		proof, err := tree.MultiProofAt(uint64(i))
+		assert.NoError(t, err)
+
+		single := tree.ProofAt(i)
+		assert.Equal(t, single.Lemma, proof.Lemma)
+	}
+
+	// shared siblings deduplicated
+	rangeProof, err := tree.RangeProof(0, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, 8+2, len(rangeProof.Lemma))
+
+	// invalid positions
+	_, err = tree.MultiProofAt(3, 2)
+	assert.Error(t, err)
+	_, err = tree.MultiProofAt(13)
+	assert.Error(t, err)
+
+	// JSON round trip
+	encoded, err := json.Marshal(proof)
+	assert.NoError(t, err)
+
+	var decoded MultiProof
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.NoError(t, decoded.Validate(tree.Root(), createChunkDataRange(positions), positions, 13))
+
+	// tampered proof
+	assert.Error(t, decoded.Validate(common.Hash{}, createChunkDataRange(positions), positions, 13))
+	assert.Error(t, decoded.Validate(tree.Root(), createChunkDataRange([]uint64{0, 1, 5, 7, 8, 11}), positions, 13))
+
+	decoded.Lemma[len(positions)] = common.Hash{}
+	assert.Error(t, decoded.Validate(tree.Root(), createChunkDataRange(positions), positions, 13))
+}
+
+func TestMultiProofPositionMismatch(t *testing.T) {
+	// lemma for leaf 4 of 5 leaves is the same as leaf 1 of 2 leaves
+	tree := createTreeByChunks(5)
+	proof, err := tree.MultiProofAt(4)
+	assert.NoError(t, err)
+
+	relocated := MultiProof{Lemma: proof.Lemma, Positions: []uint64{1}, NumLeafNodes: 2}
+	assert.True(t, relocated.validateRoot())
+
+	content := createChunkDataRange([]uint64{4})
+	assert.NoError(t, proof.Validate(tree.Root(), content, []uint64{4}, 5))
+	assert.Equal(t, errProofPositionMismatch, relocated.Validate(tree.Root(), content, []uint64{4}, 5))
+	assert.Equal(t, errProofPositionMismatch, proof.Validate(tree.Root(), content, []uint64{1}, 2))
+
+	// tampered positions
+	rangeProof, err := createTreeByChunks(13).RangeProof(2, 5)
+	assert.NoError(t, err)
+	rangeProof.Positions = []uint64{3, 4, 5}
+	assert.Equal(t, errProofPositionMismatch, rangeProof.Validate(tree.Root(), createChunkDataRange([]uint64{2, 3, 4}), []uint64{2, 3, 4}, 13))
+
+	// tampered number of leaf nodes
+	proof.NumLeafNodes = 6
+	assert.Equal(t, errProofPositionMismatch, proof.Validate(tree.Root(), content, []uint64{4}, 5))
+}
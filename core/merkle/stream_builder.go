@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// frontierNode is the root of a perfect subtree that not paired yet.
+type frontierNode struct {
+	hash   common.Hash
+	height int
+}
+
+// StreamTreeBuilder is used to build binary merkle tree in a streaming way, which is identical to
+// the tree built by TreeBuilder.
+//
+// Leaf nodes are merged into perfect subtrees once appended, so only O(log n) frontier hashes are
+// kept in memory to compute the root. Optionally, the leaf hashes could be retained, so that proofs
+// could be generated from the built tree without keeping any interior node in memory.
+type StreamTreeBuilder struct {
+	frontier []frontierNode // heights are strictly decreasing
+
+	retainLeaves bool
+	leaves       []common.Hash
+	numLeaves    int
+}
+
+// NewStreamTreeBuilder creates a new streaming tree builder. If retainLeaves is false, only the root
+// is available, and Build will return nil.
+func NewStreamTreeBuilder(retainLeaves bool) *StreamTreeBuilder {
+	return &StreamTreeBuilder{
+		retainLeaves: retainLeaves,
+	}
+}
+
+func (builder *StreamTreeBuilder) Append(content []byte) {
+	builder.AppendHash(crypto.Keccak256Hash(content))
+}
+
+func (builder *StreamTreeBuilder) AppendHash(hash common.Hash) {
+	if builder.retainLeaves {
+		builder.leaves = append(builder.leaves, hash)
+	}
+
+	builder.numLeaves++
+
+	current := frontierNode{hash, 0}
+
+	// merge perfect subtrees of the same height
+	for n := len(builder.frontier); n > 0 && builder.frontier[n-1].height == current.height; n-- {
+		left := builder.frontier[n-1]
+		builder.frontier = builder.frontier[:n-1]
+		current = frontierNode{hashPair(left.hash, current.hash), current.height + 1}
+	}
+
+	builder.frontier = append(builder.frontier, current)
+}
+
+// NumLeaves returns the number of appended leaf nodes.
+func (builder *StreamTreeBuilder) NumLeaves() int {
+	return builder.numLeaves
+}
+
+// Root returns the merkle root of appended leaf nodes, or empty hash if no leaf node appended.
+func (builder *StreamTreeBuilder) Root() common.Hash {
+	n := len(builder.frontier)
+	if n == 0 {
+		return common.Hash{}
+	}
+
+	// The last single node of each level is moved up directly in TreeBuilder, which equals to
+	// merge the perfect subtrees from right to left.
+	root := builder.frontier[n-1].hash
+	for i := n - 2; i >= 0; i-- {
+		root = hashPair(builder.frontier[i].hash, root)
+	}
+
+	return root
+}
+
+// Build returns the merkle tree that retains leaf hashes only. It returns nil if no leaf node
+// appended or leaf nodes not retained.
+func (builder *StreamTreeBuilder) Build() *Tree {
+	if builder.numLeaves == 0 || !builder.retainLeaves {
+		return nil
+	}
+
+	return &Tree{
+		root:   newNode(builder.Root()),
+		leaves: builder.leaves,
+	}
+}
+
+func hashPair(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left.Bytes(), right.Bytes())
+}
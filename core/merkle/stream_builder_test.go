@@ -0,0 +1,49 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func createStreamBuilderByChunks(chunks int, retainLeaves bool) *StreamTreeBuilder {
+	builder := NewStreamTreeBuilder(retainLeaves)
+
+	for i := 0; i < chunks; i++ {
+		builder.Append(createChunkData(i))
+	}
+
+	return builder
+}
+
+func TestStreamTreeRoot(t *testing.T) {
+	assert.Equal(t, common.Hash{}, NewStreamTreeBuilder(false).Root())
+	assert.Nil(t, NewStreamTreeBuilder(true).Build())
+	assert.Nil(t, createStreamBuilderByChunks(5, false).Build())
+
+	for _, numChunks := range []int{1, 2, 3, 5, 6, 7, 100, 1023, 1024, 1025, 3000} {
+		expected := createTreeByChunks(numChunks).Root()
+
+		builder := createStreamBuilderByChunks(numChunks, false)
+		assert.Equal(t, numChunks, builder.NumLeaves())
+		assert.Equal(t, expected, builder.Root(), "chunks = %v", numChunks)
+
+		tree := createStreamBuilderByChunks(numChunks, true).Build()
+		assert.Equal(t, numChunks, tree.NumLeafNodes())
+		assert.Equal(t, expected, tree.Root(), "chunks = %v", numChunks)
+	}
+}
+
+func TestStreamTreeProof(t *testing.T) {
+	for numChunks := 1; numChunks <= 100; numChunks++ {
+		expected := createTreeByChunks(numChunks)
+		tree := createStreamBuilderByChunks(numChunks, true).Build()
+
+		for i := 0; i < numChunks; i++ {
+			proof := tree.ProofAt(i)
+			assert.Equal(t, expected.ProofAt(i), proof)
+			assert.NoError(t, proof.Validate(tree.Root(), createChunkData(i), uint64(i), uint64(numChunks)))
+		}
+	}
+}
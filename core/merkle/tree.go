@@ -1,6 +1,9 @@
 package merkle
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -8,25 +11,46 @@ import (
 type Tree struct {
 	root      *node // always not nil
 	leafNodes []*node
+
+	// Compact tree built by StreamTreeBuilder only retains leaf hashes, and
+	// hashes of interior nodes are computed on demand to generate proofs.
+	leaves []common.Hash
+
+	// hashes of each level from bottom to top, computed on demand
+	levels     [][]common.Hash
+	levelsOnce sync.Once
 }
 
 func (tree *Tree) Root() common.Hash {
 	return tree.root.hash
 }
 
+// NumLeafNodes returns the number of leaf nodes.
+func (tree *Tree) NumLeafNodes() int {
+	if tree.leafNodes != nil {
+		return len(tree.leafNodes)
+	}
+
+	return len(tree.leaves)
+}
+
 func (tree *Tree) ProofAt(i int) Proof {
-	if i < 0 || i >= len(tree.leafNodes) {
+	if i < 0 || i >= tree.NumLeafNodes() {
 		panic("index out of bound")
 	}
 
 	// only single root node
-	if len(tree.leafNodes) == 1 {
+	if tree.NumLeafNodes() == 1 {
 		return Proof{
 			Lemma: []common.Hash{tree.root.hash},
 			Path:  []bool{},
 		}
 	}
 
+	if tree.leafNodes == nil {
+		return tree.compactProofAt(i)
+	}
+
 	var proof Proof
 
 	// append the target leaf node hash
@@ -47,3 +71,86 @@ func (tree *Tree) ProofAt(i int) Proof {
 
 	return proof
 }
+
+// compactProofAt generates proof from hashes of each level for compact tree.
+func (tree *Tree) compactProofAt(i int) Proof {
+	levels := tree.hashLevels()
+
+	var proof Proof
+
+	// append the target leaf node hash
+	proof.Lemma = append(proof.Lemma, levels[0][i])
+
+	for _, level := range levels[:len(levels)-1] {
+		if i%2 == 1 {
+			proof.Lemma = append(proof.Lemma, level[i-1])
+			proof.Path = append(proof.Path, false)
+		} else if i+1 < len(level) {
+			proof.Lemma = append(proof.Lemma, level[i+1])
+			proof.Path = append(proof.Path, true)
+		}
+
+		// otherwise, the last single node is moved up directly
+
+		i /= 2
+	}
+
+	// append the root node hash
+	proof.Lemma = append(proof.Lemma, tree.root.hash)
+
+	return proof
+}
+
+// HashAt implements the HashSource interface.
+func (tree *Tree) HashAt(level int, index uint64) (common.Hash, error) {
+	levels := tree.hashLevels()
+
+	if level < 0 || level >= len(levels) || index >= uint64(len(levels[level])) {
+		return common.Hash{}, errors.New("index out of bound")
+	}
+
+	return levels[level][index], nil
+}
+
+// MultiProofAt generates proof for the specified leaf nodes, which should be in ascending order.
+func (tree *Tree) MultiProofAt(positions ...uint64) (*MultiProof, error) {
+	return NewMultiProof(tree, uint64(tree.NumLeafNodes()), positions)
+}
+
+// RangeProof generates proof for the contiguous leaf nodes in range [start, end).
+func (tree *Tree) RangeProof(start, end uint64) (*MultiProof, error) {
+	return NewRangeProof(tree, uint64(tree.NumLeafNodes()), start, end)
+}
+
+// hashLevels returns node hashes of each level from bottom to top, which will be computed only once.
+func (tree *Tree) hashLevels() [][]common.Hash {
+	tree.levelsOnce.Do(func() {
+		level := tree.leaves
+		if tree.leafNodes != nil {
+			level = make([]common.Hash, 0, len(tree.leafNodes))
+			for _, leaf := range tree.leafNodes {
+				level = append(level, leaf.hash)
+			}
+		}
+
+		tree.levels = append(tree.levels, level)
+
+		for len(level) > 1 {
+			next := make([]common.Hash, 0, (len(level)+1)/2)
+
+			for i := 0; i+1 < len(level); i += 2 {
+				next = append(next, hashPair(level[i], level[i+1]))
+			}
+
+			// last single node
+			if len(level)%2 > 0 {
+				next = append(next, level[len(level)-1])
+			}
+
+			tree.levels = append(tree.levels, next)
+			level = next
+		}
+	})
+
+	return tree.levels
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// FileRange represents a byte range of file on disk.
+type FileRange struct {
+	Name   string
+	Offset int64
+	Size   int64 // 0 means till the end of file
+}
+
+// ReaderRange represents a byte range of data that supports random access, e.g. bytes.Reader.
+type ReaderRange struct {
+	Reader io.ReaderAt
+	Offset int64
+	Size   int64
+}
+
+// multiFilePart is a file range located in the logical stream of MultiFile.
+type multiFilePart struct {
+	reader io.ReaderAt
+	closer io.Closer // nil if not opened by MultiFile
+	start  int64     // start offset in the logical stream
+	offset int64     // start offset in file
+	size   int64
+}
+
+// MultiFile implement of IterableData, the underlying is a list of files or byte ranges of files on disk,
+// which are concatenated as one logical stream.
+type MultiFile struct {
+	parts      []*multiFilePart
+	offset     int64
+	size       int64
+	paddedSize uint64
+}
+
+var _ IterableData = (*MultiFile)(nil)
+
+// OpenFiles create a MultiFile that concatenates the given files on disk.
+func OpenFiles(names ...string) (*MultiFile, error) {
+	ranges := make([]FileRange, 0, len(names))
+	for _, name := range names {
+		ranges = append(ranges, FileRange{Name: name})
+	}
+
+	return OpenMultiFile(ranges...)
+}
+
+// OpenMultiFile create a MultiFile that concatenates the given byte ranges of files on disk.
+func OpenMultiFile(ranges ...FileRange) (*MultiFile, error) {
+	data := &MultiFile{}
+
+	for _, r := range ranges {
+		part, err := openMultiFilePart(r, data.size)
+		if err != nil {
+			data.Close()
+			return nil, errors.WithMessagef(err, "failed to open file %v", r.Name)
+		}
+
+		if part.size == 0 {
+			part.closer.Close()
+			continue
+		}
+
+		data.parts = append(data.parts, part)
+		data.size += part.size
+	}
+
+	if data.size == 0 {
+		return nil, ErrFileEmpty
+	}
+
+	data.paddedSize = IteratorPaddedSize(data.size, true)
+
+	return data, nil
+}
+
+func openMultiFilePart(r FileRange, start int64) (*multiFilePart, error) {
+	file, err := os.Open(r.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrFileRequired
+	}
+
+	size := r.Size
+	if size == 0 {
+		size = info.Size() - r.Offset
+	}
+
+	if r.Offset < 0 || size < 0 || r.Offset+size > info.Size() {
+		file.Close()
+		return nil, errors.Errorf("invalid range, offset = %v, size = %v, file size = %v", r.Offset, r.Size, info.Size())
+	}
+
+	return &multiFilePart{file, file, start, r.Offset, size}, nil
+}
+
+// NewMultiReaderData create a MultiFile that concatenates the given byte ranges of readers, which
+// will not be closed by MultiFile.
+func NewMultiReaderData(ranges ...ReaderRange) (*MultiFile, error) {
+	data := &MultiFile{}
+
+	for _, r := range ranges {
+		if r.Offset < 0 || r.Size < 0 {
+			return nil, errors.Errorf("invalid range, offset = %v, size = %v", r.Offset, r.Size)
+		}
+
+		if r.Size == 0 {
+			continue
+		}
+
+		data.parts = append(data.parts, &multiFilePart{r.Reader, nil, data.size, r.Offset, r.Size})
+		data.size += r.Size
+	}
+
+	if data.size == 0 {
+		return nil, ErrFileEmpty
+	}
+
+	data.paddedSize = IteratorPaddedSize(data.size, true)
+
+	return data, nil
+}
+
+func (data *MultiFile) Read(buf []byte, offset int64) (int, error) {
+	start := data.offset + offset
+	end := min(start+int64(len(buf)), data.offset+data.size)
+
+	var n int
+
+	for _, part := range data.parts {
+		if start >= end {
+			break
+		}
+
+		// skip parts before the read position
+		if part.start+part.size <= start {
+			continue
+		}
+
+		readSize := min(end, part.start+part.size) - start
+		read, err := part.reader.ReadAt(buf[n:n+int(readSize)], part.offset+start-part.start)
+		n += read
+
+		// file changed on disk
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if int64(read) < readSize {
+			return n, nil
+		}
+
+		start += readSize
+	}
+
+	return n, nil
+}
+
+// Close closes all the underlying files opened by MultiFile.
+func (data *MultiFile) Close() error {
+	var err error
+
+	for _, part := range data.parts {
+		if part.closer == nil {
+			continue
+		}
+
+		if closeErr := part.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+func (data *MultiFile) NumChunks() uint64 {
+	return NumSplits(data.size, DefaultChunkSize)
+}
+
+func (data *MultiFile) NumSegments() uint64 {
+	return NumSplits(data.size, DefaultSegmentSize)
+}
+
+func (data *MultiFile) PaddedSize() uint64 {
+	return data.paddedSize
+}
+
+func (data *MultiFile) Size() int64 {
+	return data.size
+}
+
+func (data *MultiFile) Offset() int64 {
+	return data.offset
+}
+
+func (data *MultiFile) Split(fragmentSize int64) []IterableData {
+	fragments := make([]IterableData, 0)
+	for offset := int64(0); offset < data.size; offset += fragmentSize {
+		size := min(data.size-offset, fragmentSize)
+		fragment := &MultiFile{
+			parts:      data.parts,
+			offset:     data.offset + offset,
+			size:       size,
+			paddedSize: IteratorPaddedSize(size, true),
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTempFile(t *testing.T, data []byte) string {
+	tmpFile, err := os.CreateTemp("", "0g-storage-client-*")
+	assert.NoError(t, err)
+	defer tmpFile.Close()
+
+	_, err = tmpFile.Write(data)
+	assert.NoError(t, err)
+
+	return tmpFile.Name()
+}
+
+func assertSameMerkleTree(t *testing.T, expected, actual IterableData, fragmentSize int64) {
+	assert.Equal(t, expected.Size(), actual.Size())
+	assert.Equal(t, expected.PaddedSize(), actual.PaddedSize())
+
+	expectedRoot, err := MerkleRootOf(expected)
+	assert.NoError(t, err)
+
+	actualRoot, err := MerkleRootOf(actual)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedRoot, actualRoot)
+
+	expectedFragments := expected.Split(fragmentSize)
+	actualFragments := actual.Split(fragmentSize)
+	assert.Equal(t, len(expectedFragments), len(actualFragments))
+
+	for i := range expectedFragments {
+		expectedRoot, err := MerkleRootOf(expectedFragments[i])
+		assert.NoError(t, err)
+
+		actualRoot, err := MerkleRootOf(actualFragments[i])
+		assert.NoError(t, err)
+		assert.Equal(t, expectedRoot, actualRoot)
+	}
+}
+
+func TestMultiFile(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	data := make([]byte, DefaultSegmentSize*5+100)
+	_, err := r.Read(data)
+	assert.NoError(t, err)
+
+	inMem, err := NewDataInMemory(data)
+	assert.NoError(t, err)
+
+	// split data into 2 files at unaligned position
+	name1 := createTempFile(t, data[:DefaultSegmentSize+7])
+	defer os.Remove(name1)
+	name2 := createTempFile(t, data[DefaultSegmentSize+7:])
+	defer os.Remove(name2)
+
+	files, err := OpenFiles(name1, name2)
+	assert.NoError(t, err)
+	defer files.Close()
+
+	assertSameMerkleTree(t, inMem, files, DefaultSegmentSize*2)
+
+	// byte ranges of a single file
+	name := createTempFile(t, data)
+	defer os.Remove(name)
+
+	ranges, err := OpenMultiFile(
+		FileRange{Name: name, Offset: 0, Size: 1000},
+		FileRange{Name: name, Offset: 1000, Size: DefaultSegmentSize * 3},
+		FileRange{Name: name, Offset: 1000 + DefaultSegmentSize*3},
+	)
+	assert.NoError(t, err)
+	defer ranges.Close()
+
+	assertSameMerkleTree(t, inMem, ranges, DefaultSegmentSize)
+
+	buf := make([]byte, 2000)
+	n, err := ranges.Read(buf, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, n)
+	assert.Equal(t, data[500:2500], buf)
+
+	_, err = OpenMultiFile(FileRange{Name: name, Offset: int64(len(data)), Size: 1})
+	assert.Error(t, err)
+}
+
+func TestHTTPRangeData(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	data := make([]byte, DefaultSegmentSize*5+100)
+	_, err := r.Read(data)
+	assert.NoError(t, err)
+
+	inMem, err := NewDataInMemory(data)
+	assert.NoError(t, err)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// fail every 3rd request to test retries
+		if requests.Add(1)%3 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		http.ServeContent(w, req, "data", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	remote, err := NewHTTPRangeData(server.URL, HTTPRangeOption{
+		RetryInterval: time.Millisecond,
+		BlockSize:     DefaultSegmentSize / 2,
+		CacheBlocks:   4,
+	})
+	assert.NoError(t, err)
+
+	assertSameMerkleTree(t, inMem, remote, DefaultSegmentSize*2)
+
+	buf := make([]byte, DefaultSegmentSize)
+	n, err := remote.Read(buf, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSegmentSize, n)
+	assert.Equal(t, data[100:100+DefaultSegmentSize], buf)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"math/bits"
+
+	"github.com/0glabs/0g-storage-client/core/merkle"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// segmentTreeHeight is the height of merkle tree of a full segment in chunks.
+var segmentTreeHeight = bits.TrailingZeros(DefaultSegmentMaxChunks)
+
+// ChunkRangeProof generates merkle proof for chunks that cover the byte range [offset, offset+length) of data,
+// and returns the proof along with the chunks (zero padded), which could be validated against the data
+// merkle root by ValidateChunkRangeProof.
+func ChunkRangeProof(data IterableData, offset, length int64) (*merkle.MultiProof, []byte, error) {
+	if offset < 0 || length <= 0 || offset+length > data.Size() {
+		return nil, nil, errors.Errorf("invalid range, offset = %v, length = %v, size = %v", offset, length, data.Size())
+	}
+
+	segments, err := MerkleTree(data)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to create merkle tree")
+	}
+
+	startChunk := uint64(offset / DefaultChunkSize)
+	endChunk := NumSplits(offset+length, DefaultChunkSize)
+
+	source := &chunkHashSource{
+		data:     data,
+		segments: segments,
+		cache:    make(map[uint64]*merkle.Tree),
+	}
+
+	proof, err := merkle.NewRangeProof(source, data.PaddedSize()/DefaultChunkSize, startChunk, endChunk)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to generate merkle proof")
+	}
+
+	chunks, err := ReadAt(data, int((endChunk-startChunk)*DefaultChunkSize), int64(startChunk*DefaultChunkSize), data.PaddedSize())
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to read chunks")
+	}
+
+	return proof, chunks, nil
+}
+
+// ValidateChunkRangeProof validates the proof with chunks returned by ChunkRangeProof, which should cover the
+// byte range [offset, offset+length) of data in the specified size.
+func ValidateChunkRangeProof(root common.Hash, proof *merkle.MultiProof, chunks []byte, offset, length, size int64) error {
+	if offset < 0 || length <= 0 || offset+length > size {
+		return errors.Errorf("invalid range, offset = %v, length = %v, size = %v", offset, length, size)
+	}
+
+	startChunk := uint64(offset / DefaultChunkSize)
+	endChunk := NumSplits(offset+length, DefaultChunkSize)
+
+	if uint64(len(chunks)) != (endChunk-startChunk)*DefaultChunkSize {
+		return errors.Errorf("invalid chunks length %v", len(chunks))
+	}
+
+	positions := make([]uint64, 0, endChunk-startChunk)
+	contents := make([][]byte, 0, endChunk-startChunk)
+	for i := startChunk; i < endChunk; i++ {
+		positions = append(positions, i)
+		contents = append(contents, chunks[(i-startChunk)*DefaultChunkSize:(i-startChunk+1)*DefaultChunkSize])
+	}
+
+	numLeafNodes := IteratorPaddedSize(size, true) / DefaultChunkSize
+
+	return proof.Validate(root, contents, positions, numLeafNodes)
+}
+
+// chunkHashSource provides node hashes of merkle tree in chunks. Nodes above segment level are
+// retrieved from the merkle tree in segments, and others are computed from segment data on demand.
+type chunkHashSource struct {
+	data     IterableData
+	segments *merkle.Tree
+	cache    map[uint64]*merkle.Tree // segment index -> merkle tree in chunks
+}
+
+// HashAt implements the merkle.HashSource interface.
+func (source *chunkHashSource) HashAt(level int, index uint64) (common.Hash, error) {
+	if level >= segmentTreeHeight {
+		return source.segments.HashAt(level-segmentTreeHeight, index)
+	}
+
+	segmentIndex := index >> (segmentTreeHeight - level)
+
+	tree, err := source.segmentTree(segmentIndex)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// The last segment may be not full, whose root locates in lower level.
+	localIndex := index - segmentIndex<<(segmentTreeHeight-level)
+	if hash, err := tree.HashAt(level, localIndex); err == nil || localIndex > 0 {
+		return hash, err
+	}
+
+	return tree.Root(), nil
+}
+
+func (source *chunkHashSource) segmentTree(segmentIndex uint64) (*merkle.Tree, error) {
+	if tree, ok := source.cache[segmentIndex]; ok {
+		return tree, nil
+	}
+
+	buf, err := ReadAt(source.data, DefaultSegmentSize, int64(segmentIndex*DefaultSegmentSize), source.data.PaddedSize())
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read segment %v", segmentIndex)
+	}
+
+	builder := merkle.NewStreamTreeBuilder(true)
+	for offset := 0; offset < len(buf); offset += DefaultChunkSize {
+		builder.Append(buf[offset : offset+DefaultChunkSize])
+	}
+
+	tree := builder.Build()
+	source.cache[segmentIndex] = tree
+
+	return tree, nil
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkRangeProof(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for _, size := range []int{1, 1000, DefaultSegmentSize, DefaultSegmentSize*3 + 1000, DefaultSegmentSize*17 + 10} {
+		data := make([]byte, size)
+		_, err := r.Read(data)
+		assert.NoError(t, err)
+
+		inMem, err := NewDataInMemory(data)
+		assert.NoError(t, err)
+
+		tree, err := MerkleTree(inMem)
+		assert.NoError(t, err)
+
+		ranges := [][2]int{{0, 1}, {0, size}, {size - 1, 1}}
+		for i := 0; i < 10; i++ {
+			offset := r.Intn(size)
+			ranges = append(ranges, [2]int{offset, r.Intn(size-offset) + 1})
+		}
+
+		for _, v := range ranges {
+			offset, length := v[0], v[1]
+
+			proof, chunks, err := ChunkRangeProof(inMem, int64(offset), int64(length))
+			assert.NoError(t, err)
+			assert.NoError(t, ValidateChunkRangeProof(tree.Root(), proof, chunks, int64(offset), int64(length), int64(size)), "size = %v, offset = %v, length = %v", size, offset, length)
+
+			start := offset / DefaultChunkSize * DefaultChunkSize
+			assert.Equal(t, data[offset:offset+length], chunks[offset-start:offset-start+length])
+		}
+	}
+
+	inMem, _ := NewDataInMemory(make([]byte, 100))
+	_, _, err := ChunkRangeProof(inMem, 50, 51)
+	assert.Error(t, err)
+}
+
+func TestChunkRangeProofRelocated(t *testing.T) {
+	size := DefaultChunkSize * 5
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i / DefaultChunkSize)
+	}
+
+	inMem, err := NewDataInMemory(data)
+	assert.NoError(t, err)
+
+	tree, err := MerkleTree(inMem)
+	assert.NoError(t, err)
+
+	proof, chunks, err := ChunkRangeProof(inMem, int64(DefaultChunkSize*4), DefaultChunkSize)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateChunkRangeProof(tree.Root(), proof, chunks, int64(DefaultChunkSize*4), DefaultChunkSize, int64(size)))
+
+	// chunk is not at another offset
+	assert.Error(t, ValidateChunkRangeProof(tree.Root(), proof, chunks, int64(DefaultChunkSize*3), DefaultChunkSize, int64(size)))
+
+	// chunk is not in file of another size
+	assert.Error(t, ValidateChunkRangeProof(tree.Root(), proof, chunks, int64(DefaultChunkSize*4), DefaultChunkSize, int64(size+DefaultChunkSize*4)))
+
+	// tampered positions and number of leaf nodes, with which the lemma is valid for chunk 1 of 2 chunks
+	proof.Positions = []uint64{1}
+	proof.NumLeafNodes = 2
+	assert.Error(t, ValidateChunkRangeProof(tree.Root(), proof, chunks, int64(DefaultChunkSize*4), DefaultChunkSize, int64(size)))
+}
@@ -8,11 +8,21 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// hashAppender is implemented by both merkle.TreeBuilder and merkle.StreamTreeBuilder.
+type hashAppender interface {
+	AppendHash(hash common.Hash)
+}
+
+var (
+	_ hashAppender = (*merkle.TreeBuilder)(nil)
+	_ hashAppender = (*merkle.StreamTreeBuilder)(nil)
+)
+
 type TreeBuilderInitializer struct {
 	data    IterableData
 	offset  int64
 	batch   int64
-	builder *merkle.TreeBuilder
+	builder hashAppender
 }
 
 var _ parallel.Interface = (*TreeBuilderInitializer)(nil)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 	eth_common "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 )
@@ -22,7 +23,7 @@ func NewIndexerApi() *IndexerApi {
 	return &IndexerApi{"indexer"}
 }
 
-// GetShardedNodes return storage node list
+// GetShardedNodes return storage node list, which are ranked by health score
 func (api *IndexerApi) GetShardedNodes(ctx context.Context) (ShardedNodes, error) {
 	trusted, err := defaultNodeManager.Trusted()
 	if err != nil {
@@ -30,11 +31,38 @@ func (api *IndexerApi) GetShardedNodes(ctx context.Context) (ShardedNodes, error
 	}
 
 	return ShardedNodes{
-		Trusted:    trusted,
-		Discovered: defaultNodeManager.Discovered(),
+		Trusted:    withLocations(trusted),
+		Discovered: withLocations(defaultNodeManager.Discovered()),
 	}, nil
 }
 
+// SelectShardedNodes return a subset of trusted nodes to store expected number of replications, in which method could
+// be max, min, random, positive number or geo-aware methods. Note, nodes are selected nearest to the caller IP
+// if method is `nearest` without coordinates.
+func (api *IndexerApi) SelectShardedNodes(ctx context.Context, expectedReplica uint, method string) ([]*shard.ShardedNode, error) {
+	trusted, err := defaultNodeManager.Trusted()
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to retrieve trusted nodes")
+	}
+
+	selected, ok := shard.Select(withLocations(trusted), expectedReplica, resolveMethod(ctx, method))
+	if !ok {
+		return nil, errors.New("Trusted nodes could not meet the replication requirement")
+	}
+
+	return selected, nil
+}
+
+// GetClientLocation return IP location of the caller.
+func (api *IndexerApi) GetClientLocation(ctx context.Context) (*IPLocation, error) {
+	return locateCaller(ctx)
+}
+
+// GetNodeHealth return health status of all trusted and discovered nodes in descending order of score.
+func (api *IndexerApi) GetNodeHealth(ctx context.Context) ([]*NodeHealth, error) {
+	return defaultNodeManager.NodeHealth(), nil
+}
+
 // GetNodeLocations return IP locations of all nodes.
 func (api *IndexerApi) GetNodeLocations(ctx context.Context) (map[string]*IPLocation, error) {
 	result := make(map[string]*IPLocation)
@@ -79,3 +107,21 @@ func (api *IndexerApi) GetFileLocations(ctx context.Context, root string) (locat
 	}
 	return defaultFileLocationCache.GetFileLocations(ctx, txSeq)
 }
+
+// GetCatalogFiles return files submitted on chain that match the query in descending order of tx seq.
+func (api *IndexerApi) GetCatalogFiles(ctx context.Context, query catalog.Query) (*catalog.QueryResult, error) {
+	if defaultCatalog == nil {
+		return nil, ErrCatalogDisabled
+	}
+
+	return defaultCatalog.Query(query)
+}
+
+// GetCatalogFile return file submitted on chain by tx seq, or nil if not found.
+func (api *IndexerApi) GetCatalogFile(ctx context.Context, txSeq uint64) (*catalog.File, error) {
+	if defaultCatalog == nil {
+		return nil, ErrCatalogDisabled
+	}
+
+	return defaultCatalog.GetFile(txSeq)
+}
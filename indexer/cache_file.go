@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultCacheWriteInterval is the default interval to write cached data to file.
+const defaultCacheWriteInterval = 10 * time.Minute
+
+// readCacheFile reads the cache file in JSON format, and returns false if file not exists.
+func readCacheFile(file string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errors.WithMessagef(err, "Failed to read file '%v'", file)
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return false, errors.WithMessage(err, "Failed to unmarshal data")
+	}
+
+	return true, nil
+}
+
+// writeCacheFile writes data to the cache file in JSON format, which is written to a temp file in the same
+// directory at first, and then renamed, so that the cache file will not be corrupted if process crashed.
+func writeCacheFile(file string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return errors.WithMessage(err, "Failed to marshal data")
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp*")
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create temp file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return errors.WithMessage(err, "Failed to write temp file")
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return errors.WithMessage(err, "Failed to close temp file")
+	}
+
+	if err = os.Rename(tmpFile.Name(), file); err != nil {
+		return errors.WithMessagef(err, "Failed to rename temp file to '%v'", file)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/contract"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBatchSize    = 1000
+	defaultPollInterval = 5 * time.Second
+	defaultRpcTimeout   = 30 * time.Second
+)
+
+var ErrChainReorged = errors.New("Chain reorganized during sync")
+
+type Config struct {
+	// DBPath is the directory of embedded store to persist files.
+	DBPath string
+
+	BlockchainURL string
+	FlowAddress   common.Address
+
+	// StartBlock is the block number to sync from, e.g. the block that flow contract deployed.
+	StartBlock uint64
+
+	// BatchSize is the max number of blocks to sync at a time, default 1000.
+	BatchSize uint64
+
+	// PollInterval is the interval to poll new blocks, default 5 seconds.
+	PollInterval time.Duration
+}
+
+// Catalog follows the `Submit` events of flow contract on chain, and stores the submitted files to query by
+// submitter, root, tags and time. Chain reorg is detected by the hashes of recent synced blocks, and files of
+// the reorganized blocks are removed and synced again.
+type Catalog struct {
+	store *Store
+	chain Chain
+
+	startBlock   uint64
+	batchSize    uint64
+	pollInterval time.Duration
+}
+
+// New creates a catalog to follow events of specified flow contract on chain.
+func New(config Config) (*Catalog, error) {
+	chain, err := NewWeb3Chain(config.BlockchainURL, config.FlowAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := OpenStore(config.DBPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithChain(store, chain, config), nil
+}
+
+// NewWithChain creates a catalog with specified store and chain, e.g. for test purpose.
+func NewWithChain(store *Store, chain Chain, config Config) *Catalog {
+	catalog := Catalog{
+		store:        store,
+		chain:        chain,
+		startBlock:   config.StartBlock,
+		batchSize:    config.BatchSize,
+		pollInterval: config.PollInterval,
+	}
+
+	if catalog.batchSize == 0 {
+		catalog.batchSize = defaultBatchSize
+	}
+
+	if catalog.pollInterval <= 0 {
+		catalog.pollInterval = defaultPollInterval
+	}
+
+	return &catalog
+}
+
+// PollInterval returns the interval to poll new blocks.
+func (c *Catalog) PollInterval() time.Duration {
+	return c.pollInterval
+}
+
+func (c *Catalog) Close() {
+	if err := c.store.Close(); err != nil {
+		logrus.WithError(err).Warn("Failed to close catalog store")
+	}
+}
+
+// GetFile returns the file of specified tx seq, or nil if not found.
+func (c *Catalog) GetFile(txSeq uint64) (*File, error) {
+	return c.store.Get(txSeq)
+}
+
+// Query returns the files that match the query in descending order of tx seq.
+func (c *Catalog) Query(query Query) (*QueryResult, error) {
+	return c.store.Query(query)
+}
+
+// Sync syncs files from chain until the latest block.
+func (c *Catalog) Sync() error {
+	for {
+		completed, err := c.syncOnce()
+		if err != nil || completed {
+			return err
+		}
+	}
+}
+
+// syncOnce syncs a batch of blocks, and returns true if synced to the latest block.
+func (c *Catalog) syncOnce() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRpcTimeout)
+	defer cancel()
+
+	synced, err := c.checkReorg(ctx)
+	if err != nil {
+		return false, errors.WithMessage(err, "Failed to check chain reorg")
+	}
+
+	from := c.startBlock
+	if synced != nil {
+		from = synced.Number + 1
+	}
+
+	latest, err := c.chain.BlockNumber(ctx)
+	if err != nil {
+		return false, errors.WithMessage(err, "Failed to retrieve the latest block number")
+	}
+
+	if from > latest {
+		return true, nil
+	}
+
+	to := min(from+c.batchSize-1, latest)
+
+	toBlock, err := c.chain.BlockByNumber(ctx, to)
+	if err != nil {
+		return false, errors.WithMessagef(err, "Failed to retrieve block %v", to)
+	}
+
+	events, err := c.chain.FilterSubmit(ctx, from, to)
+	if err != nil {
+		return false, errors.WithMessagef(err, "Failed to filter Submit events in blocks [%v, %v]", from, to)
+	}
+
+	blocks := map[uint64]*Block{to: toBlock}
+
+	var files []*File
+	for _, v := range events {
+		block, ok := blocks[v.Raw.BlockNumber]
+		if !ok {
+			if block, err = c.chain.BlockByNumber(ctx, v.Raw.BlockNumber); err != nil {
+				return false, errors.WithMessagef(err, "Failed to retrieve block %v", v.Raw.BlockNumber)
+			}
+
+			blocks[v.Raw.BlockNumber] = block
+		}
+
+		if block.Hash != v.Raw.BlockHash {
+			return false, ErrChainReorged
+		}
+
+		files = append(files, newFile(v, block))
+	}
+
+	// ensure events retrieved from the same chain
+	if block, err := c.chain.BlockByNumber(ctx, to); err != nil {
+		return false, errors.WithMessagef(err, "Failed to retrieve block %v", to)
+	} else if block.Hash != toBlock.Hash {
+		return false, ErrChainReorged
+	}
+
+	if err = c.store.Commit(files, Checkpoint{to, toBlock.Hash}); err != nil {
+		return false, errors.WithMessage(err, "Failed to write files into store")
+	}
+
+	if len(files) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"from":  from,
+			"to":    to,
+			"files": len(files),
+		}).Debug("Succeeded to sync files from chain")
+	}
+
+	return to == latest, nil
+}
+
+// checkReorg checks whether the synced blocks reorganized on chain, and rollbacks to the latest synced block
+// that not reorganized. It returns the latest synced block, or nil if not synced yet.
+func (c *Catalog) checkReorg(ctx context.Context) (*Checkpoint, error) {
+	checkpoints, err := c.store.Checkpoints()
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to read checkpoints")
+	}
+
+	// checkpoints are in descending order of block number
+	for i, v := range checkpoints {
+		block, err := c.chain.BlockByNumber(ctx, v.Number)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Failed to retrieve block %v", v.Number)
+		}
+
+		if block.Hash != v.Hash {
+			continue
+		}
+
+		if i > 0 {
+			if err = c.rollback(v.Number + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		return &checkpoints[i], nil
+	}
+
+	// all checkpoints reorganized, which should never occur
+	if len(checkpoints) > 0 {
+		if err = c.rollback(c.startBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *Catalog) rollback(from uint64) error {
+	numFiles, err := c.store.Rollback(from)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to rollback files since block %v", from)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"from":  from,
+		"files": numFiles,
+	}).Warn("Chain reorg detected, rollback files")
+
+	return nil
+}
+
+func newFile(event *contract.FlowSubmit, block *Block) *File {
+	return &File{
+		TxSeq:       event.SubmissionIndex.Uint64(),
+		Root:        event.Submission.Root(),
+		Submitter:   event.Sender,
+		Size:        event.Submission.Length.Uint64(),
+		Tags:        event.Submission.Tags,
+		TxHash:      event.Raw.TxHash,
+		BlockNumber: event.Raw.BlockNumber,
+		BlockHash:   event.Raw.BlockHash,
+		Timestamp:   int64(block.Timestamp),
+	}
+}
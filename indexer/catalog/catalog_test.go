@@ -0,0 +1,194 @@
+package catalog
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/contract"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChain struct {
+	blocks []*Block
+	events map[uint64][]*contract.FlowSubmit // block number -> events
+}
+
+func newFakeChain(numBlocks int) *fakeChain {
+	chain := fakeChain{events: make(map[uint64][]*contract.FlowSubmit)}
+	for i := 0; i < numBlocks; i++ {
+		chain.mine(0)
+	}
+	return &chain
+}
+
+// mine appends a new block, and the fork id is used to distinguish block hash after reorg.
+func (c *fakeChain) mine(fork byte) *Block {
+	number := uint64(len(c.blocks))
+	block := Block{number, common.BytesToHash([]byte{fork, byte(number)}), 1000 + number*10}
+	c.blocks = append(c.blocks, &block)
+	return &block
+}
+
+func (c *fakeChain) submit(block *Block, txSeq uint64, submitter common.Address, tags []byte) {
+	c.events[block.Number] = append(c.events[block.Number], &contract.FlowSubmit{
+		Sender:          submitter,
+		SubmissionIndex: new(big.Int).SetUint64(txSeq),
+		Submission: contract.Submission{
+			Length: big.NewInt(int64(txSeq * 100)),
+			Tags:   tags,
+			Nodes:  []contract.SubmissionNode{{Root: common.BigToHash(new(big.Int).SetUint64(txSeq)), Height: big.NewInt(0)}},
+		},
+		Raw: types.Log{BlockNumber: block.Number, BlockHash: block.Hash},
+	})
+}
+
+// reorg replaces blocks since the specified block number.
+func (c *fakeChain) reorg(from uint64, fork byte) {
+	for i := from; i < uint64(len(c.blocks)); i++ {
+		delete(c.events, i)
+	}
+
+	num := len(c.blocks)
+	c.blocks = c.blocks[:from]
+	for len(c.blocks) < num {
+		c.mine(fork)
+	}
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *fakeChain) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	return c.blocks[number], nil
+}
+
+func (c *fakeChain) FilterSubmit(ctx context.Context, from, to uint64) ([]*contract.FlowSubmit, error) {
+	var events []*contract.FlowSubmit
+	for i := from; i <= to; i++ {
+		events = append(events, c.events[i]...)
+	}
+	return events, nil
+}
+
+func txSeqs(result *QueryResult) []uint64 {
+	var seqs []uint64
+	for _, v := range result.Files {
+		seqs = append(seqs, v.TxSeq)
+	}
+	return seqs
+}
+
+func TestCatalogSyncAndQuery(t *testing.T) {
+	alice := common.HexToAddress("0x1")
+	bob := common.HexToAddress("0x2")
+
+	chain := newFakeChain(10)
+	chain.submit(chain.blocks[1], 0, alice, nil)
+	chain.submit(chain.blocks[3], 1, bob, []byte("tag"))
+	chain.submit(chain.blocks[3], 2, alice, []byte("tag"))
+	chain.submit(chain.blocks[8], 3, alice, nil)
+
+	// kv streams
+	stream1, stream2 := common.HexToHash("0x1"), common.HexToHash("0x2")
+	chain.submit(chain.blocks[9], 4, bob, append(stream1.Bytes(), stream2.Bytes()...))
+	chain.submit(chain.blocks[9], 5, bob, stream2.Bytes())
+
+	catalog := NewWithChain(NewMemoryStore(), chain, Config{BatchSize: 4})
+	defer catalog.Close()
+	assert.Nil(t, catalog.Sync())
+
+	file, err := catalog.GetFile(1)
+	assert.Nil(t, err)
+	assert.Equal(t, bob, file.Submitter)
+	assert.Equal(t, uint64(100), file.Size)
+	assert.Equal(t, []byte("tag"), []byte(file.Tags))
+	assert.Equal(t, int64(1030), file.Timestamp)
+
+	result, err := catalog.Query(Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{5, 4, 3, 2, 1, 0}, txSeqs(result))
+	assert.Nil(t, result.Next)
+
+	// filters
+	result, _ = catalog.Query(Query{Tags: stream1.Bytes()})
+	assert.Equal(t, []uint64{4}, txSeqs(result))
+	result, _ = catalog.Query(Query{Tags: stream2.Bytes()})
+	assert.Equal(t, []uint64{5, 4}, txSeqs(result))
+	result, _ = catalog.Query(Query{Tags: append(stream2.Bytes(), stream1.Bytes()...)})
+	assert.Equal(t, []uint64{4}, txSeqs(result))
+	result, _ = catalog.Query(Query{Submitter: &bob, Tags: stream1.Bytes()})
+	assert.Equal(t, []uint64{4}, txSeqs(result))
+	result, _ = catalog.Query(Query{Submitter: &alice})
+	assert.Equal(t, []uint64{3, 2, 0}, txSeqs(result))
+	result, _ = catalog.Query(Query{Tags: []byte("tag")})
+	assert.Equal(t, []uint64{2, 1}, txSeqs(result))
+	result, _ = catalog.Query(Query{Submitter: &alice, Tags: []byte{}})
+	assert.Equal(t, []uint64{3, 0}, txSeqs(result))
+	result, _ = catalog.Query(Query{FromTime: 1030, ToTime: 1080})
+	assert.Equal(t, []uint64{2, 1}, txSeqs(result))
+	root := common.BigToHash(big.NewInt(2))
+	result, _ = catalog.Query(Query{Root: &root})
+	assert.Equal(t, []uint64{2}, txSeqs(result))
+
+	// pagination
+	result, _ = catalog.Query(Query{Submitter: &alice, Limit: 2})
+	assert.Equal(t, []uint64{3, 2}, txSeqs(result))
+	assert.Equal(t, uint64(2), *result.Next)
+	result, _ = catalog.Query(Query{Submitter: &alice, Limit: 2, Cursor: result.Next})
+	assert.Equal(t, []uint64{0}, txSeqs(result))
+	assert.Nil(t, result.Next)
+
+	// scanned files limited for selective query
+	defer func(max int) { maxQueryScan = max }(maxQueryScan)
+	maxQueryScan = 2
+
+	query := Query{ToTime: 1040}
+	var pages [][]uint64
+	for {
+		result, err = catalog.Query(query)
+		assert.Nil(t, err)
+		pages = append(pages, txSeqs(result))
+
+		if result.Next == nil {
+			break
+		}
+
+		query.Cursor = result.Next
+	}
+	assert.Equal(t, [][]uint64{nil, {2}, {1, 0}}, pages)
+}
+
+func TestCatalogReorg(t *testing.T) {
+	submitter := common.HexToAddress("0x1")
+
+	chain := newFakeChain(10)
+	chain.submit(chain.blocks[2], 0, submitter, nil)
+	chain.submit(chain.blocks[6], 1, submitter, nil)
+
+	catalog := NewWithChain(NewMemoryStore(), chain, Config{BatchSize: 3})
+	defer catalog.Close()
+	assert.Nil(t, catalog.Sync())
+
+	// blocks since 5 reorganized, and tx seq 1 submitted in another block
+	chain.reorg(5, 1)
+	chain.submit(chain.blocks[7], 1, submitter, []byte("new"))
+	chain.mine(1)
+	assert.Nil(t, catalog.Sync())
+
+	file, err := catalog.GetFile(1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), file.BlockNumber)
+	assert.Equal(t, chain.blocks[7].Hash, file.BlockHash)
+	assert.Equal(t, []byte("new"), []byte(file.Tags))
+
+	result, _ := catalog.Query(Query{Tags: []byte{}})
+	assert.Equal(t, []uint64{0}, txSeqs(result))
+
+	checkpoints, _ := catalog.store.Checkpoints()
+	assert.Equal(t, uint64(10), checkpoints[0].Number)
+	assert.Equal(t, chain.blocks[10].Hash, checkpoints[0].Hash)
+}
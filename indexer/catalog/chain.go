@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/0glabs/0g-storage-client/contract"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/openweb3/web3go"
+	"github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
+)
+
+// Block is the block info required to follow events on chain.
+type Block struct {
+	Number    uint64
+	Hash      common.Hash
+	Timestamp uint64
+}
+
+// Chain is the blockchain to follow `Submit` events of flow contract.
+type Chain interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number uint64) (*Block, error)
+	FilterSubmit(ctx context.Context, from, to uint64) ([]*contract.FlowSubmit, error)
+}
+
+// web3Chain follows events on chain via JSON-RPC of blockchain fullnode.
+type web3Chain struct {
+	client *web3go.Client
+	flow   *contract.Flow
+}
+
+// NewWeb3Chain creates a chain to follow `Submit` events of specified flow contract.
+func NewWeb3Chain(url string, flowAddress common.Address) (Chain, error) {
+	client, err := web3go.NewClient(url)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to connect to blockchain fullnode")
+	}
+
+	backend, _ := client.ToClientForContract()
+
+	flow, err := contract.NewFlow(flowAddress, backend)
+	if err != nil {
+		client.Close()
+		return nil, errors.WithMessage(err, "Failed to create flow contract")
+	}
+
+	return &web3Chain{client, flow}, nil
+}
+
+func (c *web3Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	number, err := c.client.WithContext(ctx).Eth.BlockNumber()
+	if err != nil {
+		return 0, err
+	}
+
+	return number.Uint64(), nil
+}
+
+func (c *web3Chain) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	block, err := c.client.WithContext(ctx).Eth.BlockByNumber(types.NewBlockNumber(int64(number)), false)
+	if err != nil {
+		return nil, err
+	}
+
+	if block == nil {
+		return nil, errors.Errorf("Block %v not found", number)
+	}
+
+	return &Block{number, block.Hash, block.Timestamp}, nil
+}
+
+func (c *web3Chain) FilterSubmit(ctx context.Context, from, to uint64) ([]*contract.FlowSubmit, error) {
+	iter, err := c.flow.FilterSubmit(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var events []*contract.FlowSubmit
+	for iter.Next() {
+		events = append(events, iter.Event)
+	}
+
+	return events, iter.Error()
+}
@@ -0,0 +1,293 @@
+package catalog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Key prefixes of the embedded store, in which tx seq and block number are encoded in big endian, so that
+// keys of the same prefix are ordered by tx seq or block number.
+var (
+	prefixFile       = []byte("f") // f | tx seq => file
+	prefixSubmitter  = []byte("s") // s | submitter | tx seq => nil
+	prefixRoot       = []byte("r") // r | root | tx seq => nil
+	prefixTags       = []byte("t") // t | len(tag) | tag | tx seq => nil, for each tag split by SplitTags
+	prefixBlock      = []byte("b") // b | block number | tx seq => nil
+	prefixCheckpoint = []byte("c") // c | block number => block hash
+)
+
+// maxCheckpoints is the max number of recent synced blocks kept to detect chain reorg.
+const maxCheckpoints = 1000
+
+// Checkpoint is the synced block, which is used to detect chain reorg.
+type Checkpoint struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// Store is the embedded store of files submitted on chain, which is indexed by submitter, root and tags.
+type Store struct {
+	db *leveldb.DB
+}
+
+// OpenStore opens the store of specified directory, or creates a new one if not exists.
+func OpenStore(path string) (*Store, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Failed to open database %v", path)
+	}
+
+	return &Store{db}, nil
+}
+
+// NewMemoryStore creates a new store in memory, e.g. for test purpose.
+func NewMemoryStore() *Store {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		panic(err) // never occur for memory storage
+	}
+
+	return &Store{db}
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func uint64Key(prefix []byte, v uint64, suffix ...[]byte) []byte {
+	key := append([]byte{}, prefix...)
+	key = binary.BigEndian.AppendUint64(key, v)
+
+	for _, v := range suffix {
+		key = append(key, v...)
+	}
+
+	return key
+}
+
+func tagPrefix(tag []byte) []byte {
+	prefix := binary.BigEndian.AppendUint32(append([]byte{}, prefixTags...), uint32(len(tag)))
+	return append(prefix, tag...)
+}
+
+func indexKeys(file *File) [][]byte {
+	keys := [][]byte{
+		uint64Key(append(append([]byte{}, prefixSubmitter...), file.Submitter.Bytes()...), file.TxSeq),
+		uint64Key(append(append([]byte{}, prefixRoot...), file.Root.Bytes()...), file.TxSeq),
+		uint64Key(prefixBlock, file.BlockNumber, uint64Key(nil, file.TxSeq)),
+	}
+
+	for _, tag := range SplitTags(file.Tags) {
+		keys = append(keys, uint64Key(tagPrefix(tag), file.TxSeq))
+	}
+
+	return keys
+}
+
+// Commit writes files along with the synced checkpoint atomically.
+func (s *Store) Commit(files []*File, checkpoint Checkpoint) error {
+	batch := new(leveldb.Batch)
+
+	for _, v := range files {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return errors.WithMessage(err, "Failed to marshal file")
+		}
+
+		batch.Put(uint64Key(prefixFile, v.TxSeq), data)
+
+		for _, key := range indexKeys(v) {
+			batch.Put(key, nil)
+		}
+	}
+
+	batch.Put(uint64Key(prefixCheckpoint, checkpoint.Number), checkpoint.Hash.Bytes())
+
+	// prune the oldest checkpoints
+	checkpoints, err := s.Checkpoints()
+	if err != nil {
+		return err
+	}
+
+	for i := maxCheckpoints - 1; i < len(checkpoints); i++ {
+		batch.Delete(uint64Key(prefixCheckpoint, checkpoints[i].Number))
+	}
+
+	return s.db.Write(batch, nil)
+}
+
+// Checkpoints returns the recent synced blocks in descending order of block number.
+func (s *Store) Checkpoints() ([]Checkpoint, error) {
+	var checkpoints []Checkpoint
+
+	iter := s.db.NewIterator(util.BytesPrefix(prefixCheckpoint), nil)
+	defer iter.Release()
+
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		checkpoints = append(checkpoints, Checkpoint{
+			Number: binary.BigEndian.Uint64(iter.Key()[len(prefixCheckpoint):]),
+			Hash:   common.BytesToHash(iter.Value()),
+		})
+	}
+
+	return checkpoints, iter.Error()
+}
+
+// Rollback removes files and checkpoints since the specified block number due to chain reorg, and returns
+// the number of removed files.
+func (s *Store) Rollback(from uint64) (int, error) {
+	batch := new(leveldb.Batch)
+
+	iter := s.db.NewIterator(&util.Range{
+		Start: uint64Key(prefixBlock, from),
+		Limit: util.BytesPrefix(prefixBlock).Limit,
+	}, nil)
+	defer iter.Release()
+
+	var numFiles int
+
+	for iter.Next() {
+		txSeq := binary.BigEndian.Uint64(iter.Key()[len(prefixBlock)+8:])
+
+		file, err := s.Get(txSeq)
+		if err != nil {
+			return 0, err
+		}
+
+		if file != nil {
+			batch.Delete(uint64Key(prefixFile, txSeq))
+
+			for _, key := range indexKeys(file) {
+				batch.Delete(key)
+			}
+
+			numFiles++
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+
+	checkpoints, err := s.Checkpoints()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range checkpoints {
+		if v.Number >= from {
+			batch.Delete(uint64Key(prefixCheckpoint, v.Number))
+		}
+	}
+
+	if err = s.db.Write(batch, nil); err != nil {
+		return 0, err
+	}
+
+	return numFiles, nil
+}
+
+// Get returns the file of specified tx seq, or nil if not found.
+func (s *Store) Get(txSeq uint64) (*File, error) {
+	data, err := s.db.Get(uint64Key(prefixFile, txSeq), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, errors.WithMessage(err, "Failed to unmarshal file")
+	}
+
+	return &file, nil
+}
+
+// maxQueryScan is the max number of files scanned in a single query, so that a selective query will not
+// scan the whole index. It is a variable to change in tests.
+var maxQueryScan = 10_000
+
+// Query returns files that match the query in descending order of tx seq. Note, less files than the limit may
+// be returned along with Next, if too many files scanned, and the remaining files could be queried with Next.
+func (s *Store) Query(query Query) (*QueryResult, error) {
+	// select index to iterate
+	var prefix []byte
+	switch {
+	case query.Root != nil:
+		prefix = append(append([]byte{}, prefixRoot...), query.Root.Bytes()...)
+	case query.Submitter != nil:
+		prefix = append(append([]byte{}, prefixSubmitter...), query.Submitter.Bytes()...)
+	case query.Tags != nil:
+		// other tags of query are matched against files
+		prefix = tagPrefix(SplitTags(query.Tags)[0])
+	default:
+		prefix = prefixFile
+	}
+
+	keyRange := util.BytesPrefix(prefix)
+	if query.Cursor != nil {
+		keyRange.Limit = uint64Key(prefix, *query.Cursor)
+	}
+
+	iter := s.db.NewIterator(keyRange, nil)
+	defer iter.Release()
+
+	limit := query.limit()
+	result := QueryResult{Files: []*File{}}
+
+	var scanned int
+	var lastScanned uint64
+
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		txSeq := binary.BigEndian.Uint64(iter.Key()[len(prefix):])
+
+		// more files available in next page
+		if len(result.Files) == limit {
+			next := result.Files[limit-1].TxSeq
+			result.Next = &next
+			break
+		}
+
+		// too many files scanned, and continue from the current one in next page
+		if scanned == maxQueryScan {
+			result.Next = &lastScanned
+			break
+		}
+
+		scanned++
+		lastScanned = txSeq
+
+		file, err := s.Get(txSeq)
+		if err != nil {
+			return nil, err
+		}
+
+		if file == nil {
+			continue
+		}
+
+		matched, more := query.matches(file)
+		if !more {
+			break
+		}
+
+		if matched {
+			result.Files = append(result.Files, file)
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
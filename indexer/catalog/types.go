@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+
+	// tagSize is the size of a single tag, e.g. stream id of kv, if tags are composed of multiple tags.
+	tagSize = common.HashLength
+)
+
+// File is the file submitted to flow contract on chain.
+type File struct {
+	TxSeq       uint64         `json:"txSeq"`
+	Root        common.Hash    `json:"root"`
+	Submitter   common.Address `json:"submitter"`
+	Size        uint64         `json:"size"`
+	Tags        hexutil.Bytes  `json:"tags"`
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Timestamp   int64          `json:"timestamp"` // block timestamp in seconds
+}
+
+// Query is the filter to query files in catalog, in which all the specified conditions are required. Files
+// are returned in descending order of tx seq, i.e. from the newest to oldest.
+type Query struct {
+	Submitter *common.Address `json:"submitter,omitempty"`
+	Root      *common.Hash    `json:"root,omitempty"`
+	Tags      hexutil.Bytes   `json:"tags,omitempty"` // tags contained by files, see SplitTags
+
+	FromTime int64 `json:"fromTime,omitempty"` // inclusive timestamp in seconds
+	ToTime   int64 `json:"toTime,omitempty"`   // exclusive timestamp in seconds
+
+	// Cursor is the exclusive upper bound of tx seq to paginate, which is the `Next` of previous query result.
+	Cursor *uint64 `json:"cursor,omitempty"`
+
+	// Limit is the max number of files to return, default 100 and max 1000.
+	Limit int `json:"limit,omitempty"`
+}
+
+// QueryResult is the result of files query.
+type QueryResult struct {
+	Files []*File `json:"files"`
+
+	// Next is the cursor to query the next page, which is nil if no more files.
+	Next *uint64 `json:"next,omitempty"`
+}
+
+// SplitTags splits tags of file into individual tags to index and query. Tags whose size is a multiple of 32
+// bytes are regarded as concatenated 32-byte tags, e.g. stream ids of kv, and otherwise a single tag. Note,
+// empty tags is regarded as a single empty tag, so as to query files without tags.
+//
+// A file matches the tags of query if the file contains all the individual tags of query in any order.
+func SplitTags(tags []byte) [][]byte {
+	if len(tags) == 0 || len(tags)%tagSize != 0 {
+		return [][]byte{tags}
+	}
+
+	var result [][]byte
+	for i := 0; i < len(tags); i += tagSize {
+		result = append(result, tags[i:i+tagSize])
+	}
+
+	return result
+}
+
+// containsTags returns whether all the individual tags of query are contained by the file tags.
+func containsTags(fileTags, queryTags []byte) bool {
+	tags := SplitTags(fileTags)
+
+	for _, v := range SplitTags(queryTags) {
+		found := false
+
+		for _, tag := range tags {
+			if bytes.Equal(v, tag) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *Query) limit() int {
+	if q.Limit <= 0 {
+		return defaultQueryLimit
+	}
+
+	return min(q.Limit, maxQueryLimit)
+}
+
+// matches returns whether the file matches the query. Besides, it returns false in `more` if no more files
+// matched in descending order of tx seq.
+func (q *Query) matches(file *File) (matched bool, more bool) {
+	if q.FromTime > 0 && file.Timestamp < q.FromTime {
+		return false, false
+	}
+
+	if q.ToTime > 0 && file.Timestamp >= q.ToTime {
+		return false, true
+	}
+
+	if q.Submitter != nil && *q.Submitter != file.Submitter {
+		return false, true
+	}
+
+	if q.Root != nil && *q.Root != file.Root {
+		return false, true
+	}
+
+	if q.Tags != nil && !containsTags(file.Tags, q.Tags) {
+		return false, true
+	}
+
+	return true, true
+}
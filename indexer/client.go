@@ -12,8 +12,10 @@ import (
 	"github.com/0glabs/0g-storage-client/common/rpc"
 	"github.com/0glabs/0g-storage-client/common/shard"
 	"github.com/0glabs/0g-storage-client/core"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 	"github.com/0glabs/0g-storage-client/node"
 	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/0glabs/0g-storage-client/transfer/cache"
 	eth_common "github.com/ethereum/go-ethereum/common"
 	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
 	"github.com/openweb3/web3go"
@@ -38,7 +40,9 @@ type Client struct {
 // IndexerClientOption indexer client option
 type IndexerClientOption struct {
 	ProviderOption providers.Option
-	LogOption      common.LogOption // log option when uploading data
+	LogOption      common.LogOption  // log option when uploading data
+	Cache          *cache.Cache      // optional local cache consulted before downloading from storage nodes
+	Metrics        *transfer.Metrics // optional metrics of uploading and downloading
 }
 
 // NewClient create new indexer client, url is indexer service url
@@ -75,12 +79,41 @@ func (c *Client) GetFileLocations(ctx context.Context, root string) ([]*shard.Sh
 	return providers.CallContext[[]*shard.ShardedNode](c, ctx, "indexer_getFileLocations", root)
 }
 
+// GetNodeHealth return health status of storage nodes in descending order of score.
+func (c *Client) GetNodeHealth(ctx context.Context) ([]*NodeHealth, error) {
+	return providers.CallContext[[]*NodeHealth](c, ctx, "indexer_getNodeHealth")
+}
+
+// GetCatalogFiles get files submitted on chain that match the query in descending order of tx seq.
+func (c *Client) GetCatalogFiles(ctx context.Context, query catalog.Query) (*catalog.QueryResult, error) {
+	return providers.CallContext[*catalog.QueryResult](c, ctx, "indexer_getCatalogFiles", query)
+}
+
+// GetCatalogFile get file submitted on chain by tx seq, or nil if not found.
+func (c *Client) GetCatalogFile(ctx context.Context, txSeq uint64) (*catalog.File, error) {
+	return providers.CallContext[*catalog.File](c, ctx, "indexer_getCatalogFile", txSeq)
+}
+
+// SelectShardedNodes select a subset of trusted nodes by indexer service, which is sufficient to store expected number of replications.
+func (c *Client) SelectShardedNodes(ctx context.Context, expectedReplica uint, method string) ([]*shard.ShardedNode, error) {
+	return providers.CallContext[[]*shard.ShardedNode](c, ctx, "indexer_selectShardedNodes", expectedReplica, method)
+}
+
+// GetClientLocation return IP location of client that detected by indexer service.
+func (c *Client) GetClientLocation(ctx context.Context) (*IPLocation, error) {
+	return providers.CallContext[*IPLocation](c, ctx, "indexer_getClientLocation")
+}
+
 // SelectNodes get node list from indexer service and select a subset of it, which is sufficient to store expected number of replications.
+// Note, nodes are selected nearest to the client IP if method is `nearest` without coordinates.
 func (c *Client) SelectNodes(ctx context.Context, segNum uint64, expectedReplica uint, dropped []string, method string) ([]*node.ZgsClient, error) {
 	allNodes, err := c.GetShardedNodes(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if method == shard.MethodNearest {
+		method = c.resolveNearestMethod(ctx)
+	}
 	// filter out nodes unable to connect
 	nodes := make([]*shard.ShardedNode, 0)
 	for _, shardedNode := range allNodes.Trusted {
@@ -101,9 +134,10 @@ func (c *Client) SelectNodes(ctx context.Context, segNum uint64, expectedReplica
 		}
 
 		nodes = append(nodes, &shard.ShardedNode{
-			URL:     shardedNode.URL,
-			Config:  config,
-			Latency: time.Since(start).Milliseconds(),
+			URL:      shardedNode.URL,
+			Config:   config,
+			Latency:  time.Since(start).Milliseconds(),
+			Location: shardedNode.Location,
 		})
 	}
 	// randomly select proper subset
@@ -121,6 +155,23 @@ func (c *Client) SelectNodes(ctx context.Context, segNum uint64, expectedReplica
 	return clients, nil
 }
 
+// resolveNearestMethod resolves the client coordinates to select nearest nodes, or falls back to select nodes
+// by latency if client location unavailable.
+func (c *Client) resolveNearestMethod(ctx context.Context) string {
+	loc, err := c.GetClientLocation(ctx)
+	if err != nil {
+		c.logger.WithError(err).Debug("Failed to get client location, select nearest nodes by latency")
+		return shard.MethodNearest
+	}
+
+	if _, err = shard.ParseCoordinates(loc.Location); err != nil {
+		c.logger.WithError(err).Debug("Client coordinates unavailable, select nearest nodes by latency")
+		return shard.MethodNearest
+	}
+
+	return shard.MethodNearest + ":" + loc.Location
+}
+
 // NewUploaderFromIndexerNodes return an uploader with selected storage nodes from indexer service.
 func (c *Client) NewUploaderFromIndexerNodes(ctx context.Context, segNum uint64, w3Client *web3go.Client, expectedReplica uint, dropped []string, method string) (*transfer.Uploader, error) {
 	clients, err := c.SelectNodes(ctx, segNum, expectedReplica, dropped, method)
@@ -132,7 +183,12 @@ func (c *Client) NewUploaderFromIndexerNodes(ctx context.Context, segNum uint64,
 		urls[i] = client.URL()
 	}
 	c.logger.Infof("get %v storage nodes from indexer: %v", len(urls), urls)
-	return transfer.NewUploader(ctx, w3Client, clients, c.option.LogOption)
+	uploader, err := transfer.NewUploader(ctx, w3Client, clients, c.option.LogOption)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploader.WithMetrics(c.option.Metrics), nil
 }
 
 // Upload submit data to 0g storage contract, then transfer the data to the storage nodes selected from indexer service.
@@ -266,7 +322,7 @@ func (c *Client) NewDownloaderFromIndexerNodes(ctx context.Context, root string)
 		return nil, err
 	}
 
-	return downloader, nil
+	return downloader.WithCache(c.option.Cache).WithMetrics(c.option.Metrics), nil
 }
 
 func (c *Client) DownloadFragments(ctx context.Context, roots []string, filename string, withProof bool) error {
@@ -278,11 +334,7 @@ func (c *Client) DownloadFragments(ctx context.Context, roots []string, filename
 
 	for _, root := range roots {
 		tempFile := fmt.Sprintf("%v.temp", root)
-		downloader, err := c.NewDownloaderFromIndexerNodes(ctx, root)
-		if err != nil {
-			return err
-		}
-		err = downloader.Download(ctx, root, tempFile, withProof)
+		err = c.Download(ctx, root, tempFile, withProof)
 		if err != nil {
 			return errors.WithMessage(err, "Failed to download file")
 		}
@@ -307,6 +359,12 @@ func (c *Client) DownloadFragments(ctx context.Context, roots []string, filename
 
 // Download download file by given data root
 func (c *Client) Download(ctx context.Context, root, filename string, withProof bool) error {
+	// Try to download from local cache before querying file locations
+	hit, err := transfer.DownloadFromCache(c.option.Cache, eth_common.HexToHash(root), filename)
+	if err != nil || hit {
+		return err
+	}
+
 	downloader, err := c.NewDownloaderFromIndexerNodes(ctx, root)
 	if err != nil {
 		return err
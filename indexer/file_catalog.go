@@ -0,0 +1,27 @@
+package indexer
+
+import (
+	"github.com/0glabs/0g-storage-client/common/util"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCatalogDisabled = errors.New("File catalog disabled")
+
+	defaultCatalog *catalog.Catalog
+)
+
+// InitDefaultCatalog initializes the default file catalog, which syncs files from chain in background.
+func InitDefaultCatalog(config catalog.Config) (*catalog.Catalog, error) {
+	c, err := catalog.New(config)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to create file catalog")
+	}
+
+	defaultCatalog = c
+
+	go util.ScheduleNow(c.Sync, c.PollInterval(), "Failed to sync file catalog once")
+
+	return c, nil
+}
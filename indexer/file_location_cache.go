@@ -23,6 +23,10 @@ type FileLocationCacheConfig struct {
 	Expiry         time.Duration
 	DiscoveryNode  string
 	DiscoveryPorts []int
+
+	// CacheFile is the file to persist cached file locations, which are reloaded and revalidated on startup.
+	CacheFile          string
+	CacheWriteInterval time.Duration
 }
 
 type successCall struct {
@@ -37,6 +41,8 @@ type FileLocationCache struct {
 	latestSuccessCall sync.Map // url -> successCall
 	discoverNode      *node.AdminClient
 	discoveryPorts    []int
+	expiry            time.Duration
+	cacheFile         string
 }
 
 var defaultFileLocationCache FileLocationCache
@@ -49,10 +55,19 @@ func InitFileLocationCache(config FileLocationCacheConfig) (cache *FileLocationC
 	}
 	defaultFileLocationCache.cache = expirable.NewLRU[uint64, []*shard.ShardedNode](config.CacheSize, nil, config.Expiry)
 	defaultFileLocationCache.discoveryPorts = config.DiscoveryPorts
+	defaultFileLocationCache.expiry = config.Expiry
+	if len(config.CacheFile) > 0 {
+		defaultFileLocationCache.initCache(config.CacheFile, config.CacheWriteInterval)
+	}
 	return &defaultFileLocationCache, nil
 }
 
 func (c *FileLocationCache) Close() {
+	if len(c.cacheFile) > 0 {
+		if err := c.write(); err != nil {
+			logrus.WithError(err).Warn("Failed to write file locations on close")
+		}
+	}
 	if c.discoverNode != nil {
 		c.discoverNode.Close()
 	}
@@ -61,7 +76,10 @@ func (c *FileLocationCache) Close() {
 func (c *FileLocationCache) GetFileLocations(ctx context.Context, txSeq uint64) ([]*shard.ShardedNode, error) {
 	var nodes []*shard.ShardedNode
 	nodes, ok := c.cache.Get(txSeq)
-	if !ok {
+	if ok {
+		metricFileLocationCacheHits.Inc()
+	} else {
+		metricFileLocationCacheMisses.Inc()
 		nodes = make([]*shard.ShardedNode, 0)
 	}
 
@@ -188,6 +206,7 @@ func (c *FileLocationCache) getFileLocation(ctx context.Context, txSeq uint64, c
 		logrus.Debugf("triggering FindFile for tx seq %v", txSeq)
 		c.discoverNode.FindFile(ctx, txSeq)
 		c.latestFindFile.Store(txSeq, time.Now())
+		metricFindFileTriggers.Inc()
 	}
 	return nil, nil
 
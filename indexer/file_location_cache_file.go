@@ -0,0 +1,175 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/common/parallel"
+	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/0glabs/0g-storage-client/common/util"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// fileLocationCacheData is the persisted data of file location cache.
+type fileLocationCacheData struct {
+	Time    int64               `json:"time"`    // timestamp that cache file written
+	Entries []fileLocationEntry `json:"entries"` // from the oldest to newest
+}
+
+type fileLocationEntry struct {
+	TxSeq uint64               `json:"txSeq"`
+	Nodes []*shard.ShardedNode `json:"nodes"`
+}
+
+// initCache reloads the persisted file locations, which are revalidated asynchronously, and writes the
+// cached file locations to cache file periodically.
+func (c *FileLocationCache) initCache(cacheFile string, writeInterval time.Duration) {
+	c.cacheFile = cacheFile
+
+	entries, err := c.read()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to read cached file locations")
+	} else {
+		logrus.WithField("count", len(entries)).Info("Succeeded to read cached file locations")
+	}
+
+	if len(entries) > 0 {
+		go c.revalidate(entries)
+	}
+
+	if writeInterval <= 0 {
+		writeInterval = defaultCacheWriteInterval
+	}
+
+	go util.Schedule(c.write, writeInterval, "Failed to write file locations once")
+}
+
+// read reads file locations from cache file. Note, entries will be ignored if cache file expired, and the
+// loaded entries are treated as newly added, since the expiry of each entry is not persisted.
+func (c *FileLocationCache) read() ([]fileLocationEntry, error) {
+	var data fileLocationCacheData
+	if _, err := readCacheFile(c.cacheFile, &data); err != nil {
+		return nil, err
+	}
+
+	if c.expiry > 0 && time.Since(time.Unix(data.Time, 0)) >= c.expiry {
+		return nil, nil
+	}
+
+	for _, v := range data.Entries {
+		c.cache.Add(v.TxSeq, v.Nodes)
+	}
+
+	return data.Entries, nil
+}
+
+// write writes cached file locations to cache file.
+func (c *FileLocationCache) write() error {
+	data := fileLocationCacheData{
+		Time: time.Now().Unix(),
+	}
+
+	// keys are ordered from the oldest to newest
+	for _, txSeq := range c.cache.Keys() {
+		if nodes, ok := c.cache.Peek(txSeq); ok {
+			data.Entries = append(data.Entries, fileLocationEntry{txSeq, nodes})
+		}
+	}
+
+	if err := writeCacheFile(c.cacheFile, &data); err != nil {
+		return errors.WithMessage(err, "Failed to write file locations to file")
+	}
+
+	logrus.WithField("count", len(data.Entries)).Debug("Succeeded to write file locations to file")
+
+	return nil
+}
+
+// revalidate checks whether the nodes of reloaded entries still hold the finalized files, and removes the
+// entries whose remaining nodes could not cover all shards. Note, nodes are only dropped if reported that file
+// not found or not finalized, and kept if failed to query, e.g. node temporarily unavailable.
+func (c *FileLocationCache) revalidate(entries []fileLocationEntry) {
+	start := time.Now()
+
+	txSeqs := make(map[string][]uint64) // url -> tx seqs
+	for _, v := range entries {
+		for _, n := range v.Nodes {
+			txSeqs[n.URL] = append(txSeqs[n.URL], v.TxSeq)
+		}
+	}
+
+	urls := make([]string, 0, len(txSeqs))
+	for url := range txSeqs {
+		urls = append(urls, url)
+	}
+
+	// tx seq -> whether file finalized, in which unknown tx seqs are excluded
+	rpcFunc := func(client *node.ZgsClient, ctx context.Context) (map[uint64]bool, error) {
+		finalized := make(map[uint64]bool)
+
+		for _, txSeq := range txSeqs[client.URL()] {
+			info, err := client.GetFileInfoByTxSeq(ctx, txSeq)
+			if err != nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"node":  client.URL(),
+					"txSeq": txSeq,
+				}).Debug("Failed to get file info to revalidate cached file location")
+				continue
+			}
+
+			finalized[txSeq] = info != nil && info.Finalized
+		}
+
+		return finalized, nil
+	}
+
+	result := parallel.QueryZgsRpc(context.Background(), urls, rpcFunc, defaultRpcOpt)
+
+	var numRemoved int
+
+	for _, v := range entries {
+		// updated or evicted already
+		nodes, ok := c.cache.Peek(v.TxSeq)
+		if !ok || len(nodes) != len(v.Nodes) {
+			continue
+		}
+
+		var valid []*shard.ShardedNode
+		for _, n := range nodes {
+			if !missingFile(result[n.URL], v.TxSeq) {
+				valid = append(valid, n)
+			}
+		}
+
+		if len(valid) == len(nodes) {
+			continue
+		}
+
+		if _, covered := shard.Select(valid, 1, "random"); covered {
+			c.cache.Add(v.TxSeq, valid)
+		} else {
+			c.cache.Remove(v.TxSeq)
+			numRemoved++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"entries": len(entries),
+		"nodes":   len(urls),
+		"removed": numRemoved,
+		"elapsed": time.Since(start),
+	}).Info("Completed to revalidate cached file locations")
+}
+
+// missingFile returns whether the node reported that file not found or not finalized.
+func missingFile(result *parallel.RpcResult[map[uint64]bool], txSeq uint64) bool {
+	if result == nil || result.Err != nil {
+		return false
+	}
+
+	finalized, ok := result.Data[txSeq]
+
+	return ok && !finalized
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/0glabs/0g-storage-client/common/api"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
+	eth_common "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+)
+
+// queryCatalogFiles queries files submitted on chain with pagination and filtering.
+func (ctrl *RestController) queryCatalogFiles(c *gin.Context) (interface{}, error) {
+	var input struct {
+		Submitter string  `form:"submitter"`
+		Root      string  `form:"root"`
+		Tags      *string `form:"tags"`
+		FromTime  int64   `form:"fromTime"`
+		ToTime    int64   `form:"toTime"`
+		Cursor    *uint64 `form:"cursor"`
+		Limit     int     `form:"limit"`
+	}
+
+	if err := c.ShouldBindQuery(&input); err != nil {
+		return nil, api.ErrValidation.WithData(err.Error())
+	}
+
+	query := catalog.Query{
+		FromTime: input.FromTime,
+		ToTime:   input.ToTime,
+		Cursor:   input.Cursor,
+		Limit:    input.Limit,
+	}
+
+	if len(input.Submitter) > 0 {
+		if !eth_common.IsHexAddress(input.Submitter) {
+			return nil, api.ErrValidation.WithData("Invalid submitter address")
+		}
+
+		submitter := eth_common.HexToAddress(input.Submitter)
+		query.Submitter = &submitter
+	}
+
+	if len(input.Root) > 0 {
+		root, err := hexutil.Decode(input.Root)
+		if err != nil || len(root) != eth_common.HashLength {
+			return nil, api.ErrValidation.WithData("Invalid root hash")
+		}
+
+		hash := eth_common.BytesToHash(root)
+		query.Root = &hash
+	}
+
+	if input.Tags != nil {
+		tags, err := hexutil.Decode(strings.TrimSpace(*input.Tags))
+		if err != nil {
+			return nil, api.ErrValidation.WithData("Invalid tags, hex string with 0x prefix required")
+		}
+
+		query.Tags = tags
+	}
+
+	return ctrl.catalog.Query(query)
+}
+
+// getCatalogFile gets file submitted on chain by tx seq.
+func (ctrl *RestController) getCatalogFile(c *gin.Context) (interface{}, error) {
+	txSeq, err := strconv.ParseUint(strings.TrimSpace(c.Param("txSeq")), 10, 64)
+	if err != nil {
+		return nil, api.ErrValidation.WithData("Invalid tx seq")
+	}
+
+	file, err := ctrl.catalog.GetFile(txSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		return nil, ErrFileNotFound
+	}
+
+	return file, nil
+}
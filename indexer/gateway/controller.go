@@ -6,6 +6,7 @@ import (
 
 	"github.com/0glabs/0g-storage-client/common/shard"
 	"github.com/0glabs/0g-storage-client/indexer"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 	"github.com/0glabs/0g-storage-client/node"
 	eth_common "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ func NewCid(cidStr string) Cid {
 type RestController struct {
 	nodeManager       *indexer.NodeManager
 	fileLocationCache *indexer.FileLocationCache
+	catalog           *catalog.Catalog // optional file catalog
 
 	maxDownloadFileSize uint64 // max download file size
 }
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/0glabs/0g-storage-client/transfer/dir"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // downloadFile handles file downloads by root hash or transaction sequence.
@@ -84,6 +86,11 @@ func (ctrl *RestController) downloadFileInFolder(c *gin.Context) (interface{}, e
 
 	switch fnode.Type {
 	case dir.FileTypeDirectory:
+		if format := c.Query("format"); len(format) > 0 {
+			// Export the directory as an archive.
+			return nil, ctrl.exportAndServeDirectory(c, fnode, format)
+		}
+
 		// Show the list of files in the directory.
 		return serveDirectoryListing(fnode), nil
 	case dir.FileTypeSymbolic:
@@ -153,10 +160,84 @@ func (ctrl *RestController) downloadAndServeFile(c *gin.Context, cid Cid, filena
 	}
 
 	c.FileAttachment(tmpfile, filename)
+	observeDownloadBytes(c)
+
+	return api.ErrHandled
+}
+
+// exportAndServeDirectory streams the directory as an archive attachment.
+func (ctrl *RestController) exportAndServeDirectory(c *gin.Context, dirNode *dir.FsNode, format string) error {
+	archiveFormat, err := transfer.ParseArchiveFormat(format)
+	if err != nil {
+		return api.ErrValidation.WithData(err.Error())
+	}
+
+	var totalSize int64
+	dirNode.Traverse(func(node *dir.FsNode, _ string) error {
+		totalSize += node.Size
+		return nil
+	})
+
+	if totalSize > int64(ctrl.maxDownloadFileSize) {
+		return ErrFileSizeTooLarge.WithData(map[string]uint64{
+			"actual": uint64(totalSize),
+			"max":    ctrl.maxDownloadFileSize,
+		})
+	}
+
+	name := dirNode.Name
+	if name == "/" {
+		name = "root"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v.%v"`, name, archiveFormat))
+	if archiveFormat == transfer.ArchiveFormatZip {
+		c.Header("Content-Type", "application/zip")
+	} else {
+		c.Header("Content-Type", "application/x-tar")
+	}
+
+	// Files within directory may be stored on different storage nodes.
+	downloader := &locationAwareDownloader{ctrl}
+	if err = transfer.ExportFileTree(c, downloader, dirNode, archiveFormat, c.Writer); err != nil {
+		// response is partially written already
+		logrus.WithError(err).WithField("dir", dirNode.Name).Warn("Failed to export directory as archive")
+	}
+
+	observeDownloadBytes(c)
 
 	return api.ErrHandled
 }
 
+// locationAwareDownloader downloads file from storage nodes that hold the file.
+type locationAwareDownloader struct {
+	ctrl *RestController
+}
+
+func (downloader *locationAwareDownloader) Download(ctx context.Context, root, filename string, withProof bool) error {
+	clients, err := downloader.ctrl.getAvailableStorageNodes(ctx, Cid{Root: root})
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get available storage nodes")
+	}
+
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+
+	fileDownloader, err := transfer.NewDownloader(clients)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create downloader")
+	}
+
+	return fileDownloader.Download(ctx, root, filename, withProof)
+}
+
+func (downloader *locationAwareDownloader) DownloadFragments(ctx context.Context, roots []string, filename string, withProof bool) error {
+	return errors.New("Fragments download not supported")
+}
+
 // serveDirectoryListing serves the list of files in a directory.
 func serveDirectoryListing(dirNode *dir.FsNode) interface{} {
 	type DirListing struct {
@@ -5,17 +5,30 @@ import (
 
 	"github.com/0glabs/0g-storage-client/common/api"
 	"github.com/0glabs/0g-storage-client/indexer"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Config struct {
 	Endpoint            string       // http endpoint
 	RPCHandler          http.Handler // enable to provide both RPC and REST API service
 	MaxDownloadFileSize uint64       // max download file size
+
+	// Catalog enables to query files submitted on chain.
+	Catalog *catalog.Catalog
+
+	// Metrics enables to serve metrics of registry at `/metrics`, along with the REST API and download metrics.
+	Metrics *prometheus.Registry
 }
 
 func MustServeWithRPC(nodeManager *indexer.NodeManager, locationCache *indexer.FileLocationCache, config Config) {
 	controller := NewRestController(nodeManager, locationCache, config.MaxDownloadFileSize)
+	controller.catalog = config.Catalog
+
+	if config.Metrics != nil {
+		config.Metrics.MustRegister(metricDownloadBytes)
+	}
 
 	api.Serve(config.Endpoint, func(router *gin.Engine) {
 		router.GET("/file", api.Wrap(controller.downloadFile))
@@ -25,8 +38,25 @@ func MustServeWithRPC(nodeManager *indexer.NodeManager, locationCache *indexer.F
 		router.GET("/node/status", api.Wrap(controller.getNodeStatus))
 		router.POST("/file/segment", api.Wrap(controller.uploadSegment))
 
+		if config.Catalog != nil {
+			router.GET("/catalog/files", api.Wrap(controller.queryCatalogFiles))
+			router.GET("/catalog/files/:txSeq", api.Wrap(controller.getCatalogFile))
+		}
+
 		if config.RPCHandler != nil {
 			router.POST("/", gin.WrapH(config.RPCHandler))
 		}
-	})
+	}, api.RouterOption{Metrics: config.Metrics})
+}
+
+var metricDownloadBytes = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "indexer_gateway_download_bytes_total",
+	Help: "Number of bytes served to download files and directories.",
+})
+
+// observeDownloadBytes observes the bytes written in response body.
+func observeDownloadBytes(c *gin.Context) {
+	if size := c.Writer.Size(); size > 0 {
+		metricDownloadBytes.Add(float64(size))
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"sync"
@@ -16,6 +17,10 @@ import (
 
 var defaultIPLocationManager = IPLocationManager{}
 
+// negativeIPLocationExpiry is the validity period of failed or partial IP locations, which are not persisted,
+// so as to avoid querying web API for the same IP address frequently.
+const negativeIPLocationExpiry = 10 * time.Minute
+
 type IPLocation struct {
 	City     string `json:"city"`
 	Region   string `json:"region"`
@@ -28,17 +33,36 @@ type IPLocationConfig struct {
 	CacheFile          string
 	CacheWriteInterval time.Duration
 	AccessToken        string
+	QueryTimeout       time.Duration // timeout to retrieve IP location from web API, default 5 seconds
+
+	// TrustedProxies is the reverse proxies in front of indexer, whose X-Forwarded-For and X-Real-IP headers
+	// are honoured to locate RPC callers. Otherwise, the headers are ignored since they could be spoofed.
+	TrustedProxies []net.IPNet
 }
 
 // IPLocationManager manages IP locations.
 type IPLocationManager struct {
-	config IPLocationConfig
-	items  sync.Map // ip -> *IPLocation
+	config    IPLocationConfig
+	client    http.Client
+	items     sync.Map // ip -> *IPLocation
+	negatives sync.Map // ip -> *negativeIPLocation
+}
+
+// negativeIPLocation is a failed or partial IP location, which is cached for a while.
+type negativeIPLocation struct {
+	loc    *IPLocation
+	err    error
+	expiry time.Time
 }
 
 // InitDefaultIPLocationManager initializes the default `IPLocationManager`.
 func InitDefaultIPLocationManager(config IPLocationConfig) {
+	if config.QueryTimeout <= 0 {
+		config.QueryTimeout = 5 * time.Second
+	}
+
 	defaultIPLocationManager.config = config
+	defaultIPLocationManager.client.Timeout = config.QueryTimeout
 
 	// try load from cached IP locations
 	n, err := defaultIPLocationManager.read()
@@ -72,12 +96,38 @@ func (manager *IPLocationManager) Get(ip string) (*IPLocation, bool) {
 	return val.(*IPLocation), true
 }
 
-// Query returns the cached IP location if any. Otherwise, retrieve from web API.
+// Query returns the cached IP location if any. Otherwise, retrieve from web API. Note, failed or partial
+// IP locations are cached for a while, and will be retrieved again after expired.
 func (manager *IPLocationManager) Query(ip string) (*IPLocation, error) {
 	if loc, ok := manager.items.Load(ip); ok {
 		return loc.(*IPLocation), nil
 	}
 
+	if val, ok := manager.negatives.Load(ip); ok {
+		if negative := val.(*negativeIPLocation); time.Now().Before(negative.expiry) {
+			return negative.loc, negative.err
+		}
+
+		manager.negatives.Delete(ip)
+	}
+
+	loc, err := manager.query(ip)
+	if err != nil || !loc.complete() {
+		manager.negatives.Store(ip, &negativeIPLocation{loc, err, time.Now().Add(negativeIPLocationExpiry)})
+	} else {
+		manager.items.Store(ip, loc)
+	}
+
+	return loc, err
+}
+
+// complete indicates whether all fields of IP location are available.
+func (loc *IPLocation) complete() bool {
+	return len(loc.Timezone) > 0 && len(loc.Region) > 0 && len(loc.Country) > 0 && len(loc.City) > 0 && len(loc.Location) > 0
+}
+
+// query retrieves IP location from web API.
+func (manager *IPLocationManager) query(ip string) (*IPLocation, error) {
 	var url string
 	if len(manager.config.AccessToken) == 0 {
 		url = fmt.Sprintf("http://ipinfo.io/%v/json", ip)
@@ -85,12 +135,16 @@ func (manager *IPLocationManager) Query(ip string) (*IPLocation, error) {
 		url = fmt.Sprintf("http://ipinfo.io/%v/json?token=%v", ip, manager.config.AccessToken)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := manager.client.Get(url)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "Failed to http GET from %v", url)
+		return nil, errors.WithMessagef(err, "Failed to http GET IP location of %v", ip)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Failed to http GET IP location of %v, status = %v", ip, resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.WithMessage(err, "Failed to read http response body")
@@ -109,8 +163,7 @@ func (manager *IPLocationManager) Query(ip string) (*IPLocation, error) {
 		"loc":      loc.Location,
 	})
 
-	if len(loc.Timezone) > 0 && len(loc.Region) > 0 && len(loc.Country) > 0 && len(loc.City) > 0 && len(loc.Location) > 0 {
-		manager.items.Store(ip, &loc)
+	if loc.complete() {
 		logger.Debug("New IP location detected")
 	} else {
 		logger.Warn("New IP location detected with partial fields")
@@ -119,6 +172,22 @@ func (manager *IPLocationManager) Query(ip string) (*IPLocation, error) {
 	return &loc, nil
 }
 
+// trustedProxy indicates whether the IP address is a trusted reverse proxy.
+func (manager *IPLocationManager) trustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, v := range manager.config.TrustedProxies {
+		if v.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // read reads IP locations from cache file.
 func (manager *IPLocationManager) read() (int, error) {
 	data, err := os.ReadFile(manager.config.CacheFile)
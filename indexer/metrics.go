@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	metricDiscoverDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "indexer_discover_duration_seconds",
+		Help:    "Duration to discover peers from storage node in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	metricUpdateDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "indexer_update_duration_seconds",
+		Help:    "Duration to update shard configs of discovered nodes in seconds.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+
+	metricFileLocationCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "indexer_file_location_cache_hits_total",
+		Help: "Number of file locations found in cache.",
+	})
+
+	metricFileLocationCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "indexer_file_location_cache_misses_total",
+		Help: "Number of file locations not found in cache.",
+	})
+
+	metricFindFileTriggers = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "indexer_find_file_triggers_total",
+		Help: "Number of FindFile triggered to locate files in network.",
+	})
+)
+
+// RegisterMetrics registers metrics of the default node manager and file location cache.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "indexer_trusted_nodes",
+			Help: "Number of trusted storage nodes.",
+		}, func() float64 { return float64(countNodes(&defaultNodeManager.trusted)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "indexer_discovered_nodes",
+			Help: "Number of discovered storage nodes.",
+		}, func() float64 { return float64(countNodes(&defaultNodeManager.discovered)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "indexer_quarantined_nodes",
+			Help: "Number of trusted and discovered storage nodes that quarantined.",
+		}, func() float64 { return float64(defaultNodeManager.countQuarantined()) }),
+		metricDiscoverDuration,
+		metricUpdateDuration,
+		metricFileLocationCacheHits,
+		metricFileLocationCacheMisses,
+		metricFindFileTriggers,
+	}
+
+	for _, v := range collectors {
+		if err := registerer.Register(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func countNodes(nodes *sync.Map) int {
+	var n int
+
+	nodes.Range(func(key, value any) bool {
+		n++
+		return true
+	})
+
+	return n
+}
+
+func (nm *NodeManager) countQuarantined() int {
+	var n int
+
+	for _, nodes := range []*sync.Map{&nm.trusted, &nm.discovered} {
+		nodes.Range(func(key, value any) bool {
+			if nm.health.quarantined(key.(string)) {
+				n++
+			}
+			return true
+		})
+	}
+
+	return n
+}
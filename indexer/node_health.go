@@ -0,0 +1,271 @@
+package indexer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthWindow   = 20 // number of recent probes to calculate success rate and latency percentiles
+	defaultQuarantineMin  = time.Minute
+	defaultQuarantineMax  = time.Hour
+	defaultMaxFailures    = 10
+	defaultProbeInterval  = time.Minute
+	healthLatencyBaseline = 1000 // latency in milliseconds that halves the score
+	healthLagBaseline     = 100  // sync height lag that halves the score
+)
+
+// NodeHealth is the health status of storage node, which is evaluated by the recent probes of shard config
+// and sync status.
+type NodeHealth struct {
+	URL     string `json:"url"`
+	Trusted bool   `json:"trusted"`
+
+	// Score in range [0, 1] to rank nodes, which is 0 if node quarantined. Otherwise, it is the success rate,
+	// and halved by every 1 second of median latency and 100 blocks of sync height lag proportionally.
+	Score float64 `json:"score"`
+
+	Probes      int     `json:"probes"`      // number of recent probes
+	SuccessRate float64 `json:"successRate"` // success rate of recent probes
+	LatencyP50  int64   `json:"latencyP50"`  // median latency of recent successful probes in milliseconds
+	LatencyP90  int64   `json:"latencyP90"`
+	LatencyP99  int64   `json:"latencyP99"`
+
+	SyncHeight    uint64 `json:"syncHeight"`    // log sync height of node
+	SyncHeightLag uint64 `json:"syncHeightLag"` // lag behind the max sync height of all nodes
+
+	LastSuccess   int64  `json:"lastSuccess,omitempty"` // timestamp of last successful probe
+	LastError     string `json:"lastError,omitempty"`
+	LastErrorTime int64  `json:"lastErrorTime,omitempty"`
+
+	Failures         int   `json:"failures"`                   // number of consecutive failures
+	QuarantinedUntil int64 `json:"quarantinedUntil,omitempty"` // timestamp that quarantine expires
+}
+
+type probeResult struct {
+	ok      bool
+	latency time.Duration
+}
+
+// nodeHealthStatus is the health status of a node.
+type nodeHealthStatus struct {
+	mu sync.Mutex
+
+	probes        []probeResult // recent probes, from the oldest to newest
+	syncHeight    uint64
+	lastSuccess   time.Time
+	lastError     string
+	lastErrorTime time.Time
+
+	failures         int
+	quarantinedUntil time.Time
+}
+
+// healthTracker tracks health status of nodes, and quarantines the failed nodes with exponential backoff.
+type healthTracker struct {
+	window        int
+	quarantineMin time.Duration
+	quarantineMax time.Duration
+
+	nodes sync.Map // url -> *nodeHealthStatus
+}
+
+func newHealthTracker(quarantineMin, quarantineMax time.Duration) *healthTracker {
+	if quarantineMin <= 0 {
+		quarantineMin = defaultQuarantineMin
+	}
+
+	if quarantineMax < quarantineMin {
+		quarantineMax = max(defaultQuarantineMax, quarantineMin)
+	}
+
+	return &healthTracker{
+		window:        defaultHealthWindow,
+		quarantineMin: quarantineMin,
+		quarantineMax: quarantineMax,
+	}
+}
+
+func (t *healthTracker) status(url string) *nodeHealthStatus {
+	val, _ := t.nodes.LoadOrStore(url, &nodeHealthStatus{})
+	return val.(*nodeHealthStatus)
+}
+
+// record records the probe result of node, and returns the number of consecutive failures.
+func (t *healthTracker) record(url string, latency time.Duration, syncHeight uint64, err error) int {
+	s := t.status(url)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.probes = append(s.probes, probeResult{err == nil, latency})
+	if len(s.probes) > t.window {
+		s.probes = s.probes[len(s.probes)-t.window:]
+	}
+
+	now := time.Now()
+
+	if err == nil {
+		s.syncHeight = syncHeight
+		s.lastSuccess = now
+		s.failures = 0
+		s.quarantinedUntil = time.Time{}
+		return 0
+	}
+
+	s.lastError = err.Error()
+	s.lastErrorTime = now
+	s.failures++
+
+	// exponential backoff
+	backoff := t.quarantineMax
+	if s.failures < 32 {
+		backoff = min(t.quarantineMin<<(s.failures-1), t.quarantineMax)
+	}
+	s.quarantinedUntil = now.Add(backoff)
+
+	return s.failures
+}
+
+// setFailures sets the number of consecutive failures, e.g. reloaded from cache, without quarantine.
+func (t *healthTracker) setFailures(url string, failures int) {
+	s := t.status(url)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = failures
+}
+
+func (t *healthTracker) failures(url string) int {
+	val, ok := t.nodes.Load(url)
+	if !ok {
+		return 0
+	}
+
+	s := val.(*nodeHealthStatus)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.failures
+}
+
+// quarantined returns whether the node is quarantined due to recent failures.
+func (t *healthTracker) quarantined(url string) bool {
+	val, ok := t.nodes.Load(url)
+	if !ok {
+		return false
+	}
+
+	s := val.(*nodeHealthStatus)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return time.Now().Before(s.quarantinedUntil)
+}
+
+func (t *healthTracker) remove(url string) {
+	t.nodes.Delete(url)
+}
+
+// health returns the health status of nodes, which are sorted by score in descending order.
+func (t *healthTracker) health(urls []string, trusted map[string]bool) []*NodeHealth {
+	var result []*NodeHealth
+	var maxHeight uint64
+
+	for _, url := range urls {
+		h := t.status(url).health(url)
+		h.Trusted = trusted[url]
+		maxHeight = max(maxHeight, h.SyncHeight)
+		result = append(result, h)
+	}
+
+	for _, h := range result {
+		if h.Probes > 0 && h.SuccessRate > 0 {
+			h.SyncHeightLag = maxHeight - h.SyncHeight
+		}
+
+		h.Score = h.score()
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result
+}
+
+func (s *nodeHealthStatus) health(url string) *NodeHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := NodeHealth{
+		URL:        url,
+		Probes:     len(s.probes),
+		SyncHeight: s.syncHeight,
+		LastError:  s.lastError,
+		Failures:   s.failures,
+	}
+
+	var latencies []int64
+	for _, v := range s.probes {
+		if v.ok {
+			latencies = append(latencies, v.latency.Milliseconds())
+		}
+	}
+
+	if len(s.probes) > 0 {
+		h.SuccessRate = float64(len(latencies)) / float64(len(s.probes))
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		h.LatencyP50 = percentile(latencies, 50)
+		h.LatencyP90 = percentile(latencies, 90)
+		h.LatencyP99 = percentile(latencies, 99)
+	}
+
+	if !s.lastSuccess.IsZero() {
+		h.LastSuccess = s.lastSuccess.Unix()
+	}
+
+	if !s.lastErrorTime.IsZero() {
+		h.LastErrorTime = s.lastErrorTime.Unix()
+	}
+
+	if time.Now().Before(s.quarantinedUntil) {
+		h.QuarantinedUntil = s.quarantinedUntil.Unix()
+	}
+
+	return &h
+}
+
+func (h *NodeHealth) score() float64 {
+	if h.QuarantinedUntil > 0 {
+		return 0
+	}
+
+	// not probed yet
+	if h.Probes == 0 {
+		return 0.5
+	}
+
+	score := h.SuccessRate
+	score /= 1 + float64(h.LatencyP50)/healthLatencyBaseline
+	score /= 1 + float64(h.SyncHeightLag)/healthLagBaseline
+
+	return score
+}
+
+// percentile returns the percentile of sorted values with nearest-rank method.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,66 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthTrackerQuarantine(t *testing.T) {
+	tracker := newHealthTracker(time.Minute, 5*time.Minute)
+	url := "http://1.1.1.1:5678"
+	errProbe := errors.New("probe failed")
+
+	assert.Equal(t, 0, tracker.record(url, 10*time.Millisecond, 100, nil))
+	assert.False(t, tracker.quarantined(url))
+
+	// exponential backoff
+	for i, backoff := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		assert.Equal(t, i+1, tracker.record(url, time.Second, 0, errProbe))
+		assert.True(t, tracker.quarantined(url))
+
+		until := time.Unix(tracker.status(url).health(url).QuarantinedUntil, 0)
+		assert.WithinDuration(t, time.Now().Add(backoff), until, 2*time.Second)
+	}
+
+	health := tracker.status(url).health(url)
+	assert.Equal(t, 6, health.Probes)
+	assert.InDelta(t, 1.0/6, health.SuccessRate, 1e-9)
+	assert.Equal(t, "probe failed", health.LastError)
+	assert.Equal(t, 0.0, health.score())
+
+	// recovered
+	assert.Equal(t, 0, tracker.record(url, 10*time.Millisecond, 100, nil))
+	assert.False(t, tracker.quarantined(url))
+	assert.Equal(t, 0, tracker.failures(url))
+}
+
+func TestHealthTrackerRank(t *testing.T) {
+	tracker := newHealthTracker(0, 0)
+
+	for i := 0; i < 10; i++ {
+		tracker.record("fast", time.Duration(10+i)*time.Millisecond, 1000, nil)
+		tracker.record("slow", time.Duration(500+i)*time.Millisecond, 1000, nil)
+		tracker.record("lagged", time.Duration(10+i)*time.Millisecond, 500, nil)
+	}
+
+	health := tracker.health([]string{"lagged", "slow", "fast", "unknown"}, map[string]bool{"slow": true})
+	assert.Equal(t, 4, len(health))
+
+	assert.Equal(t, "fast", health[0].URL)
+	assert.Equal(t, int64(14), health[0].LatencyP50)
+	assert.Equal(t, int64(18), health[0].LatencyP90)
+	assert.Equal(t, int64(19), health[0].LatencyP99)
+	assert.Equal(t, uint64(0), health[0].SyncHeightLag)
+
+	assert.Equal(t, "slow", health[1].URL)
+	assert.True(t, health[1].Trusted)
+
+	assert.Equal(t, "unknown", health[2].URL)
+	assert.Equal(t, 0.5, health[2].Score)
+
+	assert.Equal(t, "lagged", health[3].URL)
+	assert.Equal(t, uint64(500), health[3].SyncHeightLag)
+}
@@ -3,6 +3,7 @@ package indexer
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,7 +29,7 @@ var (
 		Provider: defaultZgsClientOpt,
 	}
 
-	defaultNodeManager = NodeManager{}
+	defaultNodeManager = NodeManager{health: newHealthTracker(0, 0)}
 )
 
 type NodeManagerConfig struct {
@@ -39,15 +40,34 @@ type NodeManagerConfig struct {
 	DiscoveryPorts    []int
 
 	UpdateInterval time.Duration
+
+	// ProbeInterval is the interval to probe trusted nodes in background, default 1 minute.
+	ProbeInterval time.Duration
+
+	// Failed nodes are quarantined with exponential backoff from QuarantineMin (default 1 minute) to
+	// QuarantineMax (default 1 hour), and a discovered node is removed after MaxFailures (default 10)
+	// consecutive failures.
+	QuarantineMin time.Duration
+	QuarantineMax time.Duration
+	MaxFailures   int
+
+	// CacheFile is the file to persist discovered nodes, which are reloaded and revalidated on startup.
+	CacheFile          string
+	CacheWriteInterval time.Duration
 }
 
 // NodeManager manages trusted storage nodes and auto discover peers from network.
 type NodeManager struct {
-	trusted sync.Map // url -> *node.ZgsClient
+	trusted      sync.Map // url -> *node.ZgsClient
+	trustedNodes sync.Map // url -> *shard.ShardedNode, cached results of background probing
 
 	discoverNode   *node.AdminClient
 	discoveryPorts []int
 	discovered     sync.Map // url -> *shard.ShardedNode
+
+	health      *healthTracker
+	maxFailures int
+	cacheFile   string
 }
 
 // InitDefaultNodeManager initializes the default `NodeManager`.
@@ -59,10 +79,31 @@ func InitDefaultNodeManager(config NodeManagerConfig) (mgr *NodeManager, err err
 	}
 	defaultNodeManager.discoveryPorts = config.DiscoveryPorts
 
+	defaultNodeManager.health = newHealthTracker(config.QuarantineMin, config.QuarantineMax)
+	defaultNodeManager.maxFailures = config.MaxFailures
+	if defaultNodeManager.maxFailures <= 0 {
+		defaultNodeManager.maxFailures = defaultMaxFailures
+	}
+
 	if err = defaultNodeManager.AddTrustedNodes(config.TrustedNodes...); err != nil {
 		return nil, errors.WithMessage(err, "Failed to add trusted nodes")
 	}
 
+	if len(config.TrustedNodes) > 0 {
+		probeInterval := config.ProbeInterval
+		if probeInterval <= 0 {
+			probeInterval = defaultProbeInterval
+		}
+
+		// probe once to serve trusted nodes on startup
+		defaultNodeManager.probeTrusted()
+		go util.Schedule(defaultNodeManager.probeTrusted, probeInterval, "Failed to probe trusted nodes once")
+	}
+
+	if len(config.CacheFile) > 0 {
+		defaultNodeManager.initCache(config.CacheFile, config.CacheWriteInterval)
+	}
+
 	if len(config.DiscoveryNode) > 0 {
 		go util.ScheduleNow(defaultNodeManager.discover, config.DiscoveryInterval, "Failed to discover storage nodes once")
 		go util.Schedule(defaultNodeManager.update, config.UpdateInterval, "Failed to update shard configs once")
@@ -81,45 +122,59 @@ func (nm *NodeManager) TrustedClients() []*node.ZgsClient {
 	return clients
 }
 
-// Trusted returns trusted sharded nodes.
+// Trusted returns trusted sharded nodes that probed in background, which are ranked by health score, and the
+// quarantined ones are excluded.
 func (nm *NodeManager) Trusted() ([]*shard.ShardedNode, error) {
-	clients := nm.TrustedClients()
+	return nm.rank(&nm.trustedNodes), nil
+}
 
-	var nodes []*shard.ShardedNode
+// Discovered returns discovered sharded nodes ranked by health score, and the quarantined ones are excluded.
+func (nm *NodeManager) Discovered() []*shard.ShardedNode {
+	return nm.rank(&nm.discovered)
+}
 
-	for _, v := range clients {
-		start := time.Now()
-		config, err := v.GetShardConfig(context.Background())
-		if err != nil {
-			logrus.Debugf("Failed to retrieve shard config from trusted storage node %v, error: %v", v.URL(), err)
-			continue
-		}
+// rank returns the sharded nodes that not quarantined in descending order of health score.
+func (nm *NodeManager) rank(nodes *sync.Map) []*shard.ShardedNode {
+	scores := make(map[string]float64)
+	for _, v := range nm.NodeHealth() {
+		scores[v.URL] = v.Score
+	}
 
-		if !config.IsValid() {
-			logrus.Debugf("Invalid shard config retrieved from trusted storage node %v: %v", v.URL(), config)
-			continue
+	var result []*shard.ShardedNode
+
+	nodes.Range(func(key, value any) bool {
+		if !nm.health.quarantined(key.(string)) {
+			result = append(result, value.(*shard.ShardedNode))
 		}
+		return true
+	})
 
-		nodes = append(nodes, &shard.ShardedNode{
-			URL:     v.URL(),
-			Config:  config,
-			Latency: time.Since(start).Milliseconds(),
-		})
-	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return scores[result[i].URL] > scores[result[j].URL]
+	})
 
-	return nodes, nil
+	return result
 }
 
-// Discovered returns discovered sharded nodes.
-func (nm *NodeManager) Discovered() []*shard.ShardedNode {
-	var nodes []*shard.ShardedNode
+// NodeHealth returns the health status of all trusted and discovered nodes in descending order of score.
+func (nm *NodeManager) NodeHealth() []*NodeHealth {
+	var urls []string
+	trusted := make(map[string]bool)
+
+	nm.trusted.Range(func(key, value any) bool {
+		urls = append(urls, key.(string))
+		trusted[key.(string)] = true
+		return true
+	})
 
 	nm.discovered.Range(func(key, value any) bool {
-		nodes = append(nodes, value.(*shard.ShardedNode))
+		urls = append(urls, key.(string))
 		return true
 	})
 
-	return nodes
+	sort.Strings(urls)
+
+	return nm.health.health(urls, trusted)
 }
 
 func parseIP(url string) string {
@@ -160,6 +215,12 @@ func (nm *NodeManager) AddTrustedNodes(nodes ...string) error {
 }
 
 func (nm *NodeManager) Close() {
+	if len(nm.cacheFile) > 0 {
+		if err := nm.write(); err != nil {
+			logrus.WithError(err).Warn("Failed to write discovered nodes on close")
+		}
+	}
+
 	nm.trusted.Range(func(key, value any) bool {
 		value.(*node.ZgsClient).Close()
 		return true
@@ -173,6 +234,8 @@ func (nm *NodeManager) Close() {
 // discover discovers peers from storage node.
 func (nm *NodeManager) discover() error {
 	start := time.Now()
+	defer func() { metricDiscoverDuration.Observe(time.Since(start).Seconds()) }()
+
 	peers, err := nm.discoverNode.GetPeers(context.Background())
 	if err != nil {
 		return errors.WithMessage(err, "Failed to retrieve peers from storage node")
@@ -214,25 +277,21 @@ func (nm *NodeManager) discover() error {
 		}
 	}
 
-	result := queryShardConfigs(newPeers)
+	result := probeNodes(newPeers)
 	for url, rpcResult := range result {
 		if rpcResult.Err != nil {
 			logrus.WithError(rpcResult.Err).WithField("url", url).Debug("Failed to add new peer")
 			continue
 		}
 
-		nm.discovered.Store(url, &shard.ShardedNode{
-			URL:     url,
-			Config:  rpcResult.Data,
-			Latency: rpcResult.Latency.Milliseconds(),
-			Since:   time.Now().Unix(),
-		})
+		nm.health.record(url, rpcResult.Latency, rpcResult.Data.status.LogSyncHeight, nil)
+		nm.discovered.Store(url, rpcResult.Data.shardedNode(url, rpcResult.Latency))
 
 		numNew++
 
 		logrus.WithFields(logrus.Fields{
 			"url":     url,
-			"shard":   rpcResult.Data,
+			"shard":   rpcResult.Data.config,
 			"latency": rpcResult.Latency.Milliseconds(),
 		}).Debug("New peer discovered")
 	}
@@ -244,11 +303,14 @@ func (nm *NodeManager) discover() error {
 	return nil
 }
 
-// update updates shard configs of all storage nodes.
+// update updates shard configs of all discovered storage nodes that not quarantined. The failed nodes are
+// quarantined, and removed if failed too many times.
 func (nm *NodeManager) update() error {
 	var urls []string
 	nm.discovered.Range(func(key, value any) bool {
-		urls = append(urls, key.(string))
+		if !nm.health.quarantined(key.(string)) {
+			urls = append(urls, key.(string))
+		}
 		return true
 	})
 
@@ -259,19 +321,26 @@ func (nm *NodeManager) update() error {
 	logrus.WithField("nodes", len(urls)).Info("Begin to update shard config")
 
 	start := time.Now()
+	defer func() { metricUpdateDuration.Observe(time.Since(start).Seconds()) }()
 
-	result := queryShardConfigs(urls)
+	result := probeNodes(urls)
 	for url, rpcResult := range result {
 		if rpcResult.Err == nil {
-			nm.discovered.Store(url, &shard.ShardedNode{
-				URL:     url,
-				Config:  rpcResult.Data,
-				Latency: rpcResult.Latency.Milliseconds(),
-				Since:   time.Now().Unix(),
-			})
+			nm.health.record(url, rpcResult.Latency, rpcResult.Data.status.LogSyncHeight, nil)
+			nm.discovered.Store(url, rpcResult.Data.shardedNode(url, rpcResult.Latency))
+			continue
+		}
+
+		failures := nm.health.record(url, rpcResult.Latency, 0, rpcResult.Err)
+		if failures < nm.maxFailures {
+			logrus.WithError(rpcResult.Err).WithFields(logrus.Fields{
+				"url":      url,
+				"failures": failures,
+			}).Debug("Failed to update shard config, quarantined")
 		} else {
 			logrus.WithError(rpcResult.Err).WithField("url", url).Debug("Failed to update shard config, remove from cache")
 			nm.discovered.Delete(url)
+			nm.health.remove(url)
 		}
 	}
 
@@ -283,7 +352,51 @@ func (nm *NodeManager) update() error {
 	return nil
 }
 
-func queryShardConfigs(nodes []string) map[string]*parallel.RpcResult[shard.ShardConfig] {
+// probeTrusted probes trusted storage nodes that not quarantined, and caches the results.
+func (nm *NodeManager) probeTrusted() error {
+	var urls []string
+	nm.trusted.Range(func(key, value any) bool {
+		if !nm.health.quarantined(key.(string)) {
+			urls = append(urls, key.(string))
+		}
+		return true
+	})
+
+	result := probeNodes(urls)
+	for url, rpcResult := range result {
+		if rpcResult.Err != nil {
+			failures := nm.health.record(url, rpcResult.Latency, 0, rpcResult.Err)
+			logrus.WithError(rpcResult.Err).WithFields(logrus.Fields{
+				"url":      url,
+				"failures": failures,
+			}).Debug("Failed to probe trusted storage node, quarantined")
+			continue
+		}
+
+		nm.health.record(url, rpcResult.Latency, rpcResult.Data.status.LogSyncHeight, nil)
+		nm.trustedNodes.Store(url, rpcResult.Data.shardedNode(url, rpcResult.Latency))
+	}
+
+	return nil
+}
+
+// nodeProbe is the shard config and sync status of storage node.
+type nodeProbe struct {
+	config shard.ShardConfig
+	status node.Status
+}
+
+func (p *nodeProbe) shardedNode(url string, latency time.Duration) *shard.ShardedNode {
+	return &shard.ShardedNode{
+		URL:     url,
+		Config:  p.config,
+		Latency: latency.Milliseconds(),
+		Since:   time.Now().Unix(),
+	}
+}
+
+// probeNodes queries shard configs and sync status of storage nodes in parallel.
+func probeNodes(nodes []string) map[string]*parallel.RpcResult[*nodeProbe] {
 	// update IP if absent
 	for _, v := range nodes {
 		ip := parseIP(v)
@@ -292,17 +405,22 @@ func queryShardConfigs(nodes []string) map[string]*parallel.RpcResult[shard.Shar
 		}
 	}
 
-	rpcFunc := func(client *node.ZgsClient, ctx context.Context) (shard.ShardConfig, error) {
+	rpcFunc := func(client *node.ZgsClient, ctx context.Context) (*nodeProbe, error) {
 		config, err := client.GetShardConfig(ctx)
 		if err != nil {
-			return shard.ShardConfig{}, err
+			return nil, err
 		}
 
 		if !config.IsValid() {
-			return shard.ShardConfig{}, errors.Errorf("Invalid shard config retrieved %v", config)
+			return nil, errors.Errorf("Invalid shard config retrieved %v", config)
+		}
+
+		status, err := client.GetStatus(ctx)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Failed to retrieve sync status")
 		}
 
-		return config, nil
+		return &nodeProbe{config, status}, nil
 	}
 
 	return parallel.QueryZgsRpc(context.Background(), nodes, rpcFunc, defaultRpcOpt)
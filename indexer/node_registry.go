@@ -0,0 +1,106 @@
+package indexer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/0glabs/0g-storage-client/common/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// NodeRecord is the persisted record of discovered storage node, in which `Since` is the timestamp that
+// node last seen.
+type NodeRecord struct {
+	shard.ShardedNode
+
+	// Failures is the number of consecutive failures to update shard config.
+	Failures int `json:"failures"`
+}
+
+// initCache reloads the persisted discovered nodes, which are revalidated asynchronously, and writes the
+// discovered nodes to cache file periodically.
+func (nm *NodeManager) initCache(cacheFile string, writeInterval time.Duration) {
+	nm.cacheFile = cacheFile
+
+	n, err := nm.read()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to read cached discovered nodes")
+	} else {
+		logrus.WithField("count", n).Info("Succeeded to read cached discovered nodes")
+	}
+
+	if n > 0 {
+		go func() {
+			if err := nm.update(); err != nil {
+				logrus.WithError(err).Warn("Failed to revalidate cached discovered nodes")
+			}
+		}()
+	}
+
+	if writeInterval <= 0 {
+		writeInterval = defaultCacheWriteInterval
+	}
+
+	go util.Schedule(nm.write, writeInterval, "Failed to write discovered nodes once")
+}
+
+// records returns the records of all discovered nodes in order of URL.
+func (nm *NodeManager) records() []*NodeRecord {
+	var records []*NodeRecord
+
+	nm.discovered.Range(func(key, value any) bool {
+		records = append(records, &NodeRecord{
+			ShardedNode: *value.(*shard.ShardedNode),
+			Failures:    nm.health.failures(key.(string)),
+		})
+		return true
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].URL < records[j].URL
+	})
+
+	return records
+}
+
+// read reads discovered nodes from cache file, except the trusted ones.
+func (nm *NodeManager) read() (int, error) {
+	var records []*NodeRecord
+	if _, err := readCacheFile(nm.cacheFile, &records); err != nil {
+		return 0, err
+	}
+
+	var n int
+
+	for _, v := range records {
+		if _, ok := nm.trusted.Load(v.URL); ok {
+			continue
+		}
+
+		node := v.ShardedNode
+		nm.discovered.Store(v.URL, &node)
+
+		if v.Failures > 0 {
+			nm.health.setFailures(v.URL, v.Failures)
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// write writes discovered nodes to cache file.
+func (nm *NodeManager) write() error {
+	records := nm.records()
+
+	if err := writeCacheFile(nm.cacheFile, records); err != nil {
+		return errors.WithMessage(err, "Failed to write discovered nodes to file")
+	}
+
+	logrus.WithField("count", len(records)).Debug("Succeeded to write discovered nodes to file")
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package indexer
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/common/rpc"
+	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeManagerCache(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nodes.json")
+
+	nm := NodeManager{cacheFile: file, health: newHealthTracker(0, 0)}
+	nm.discovered.Store("http://1.1.1.1:5678", &shard.ShardedNode{URL: "http://1.1.1.1:5678", Latency: 10, Since: 100})
+	nm.discovered.Store("http://2.2.2.2:5678", &shard.ShardedNode{URL: "http://2.2.2.2:5678", Latency: 20, Since: 200})
+	nm.health.setFailures("http://2.2.2.2:5678", 2)
+	assert.NoError(t, nm.write())
+
+	// trusted nodes are not reloaded as discovered
+	reloaded := NodeManager{cacheFile: file, health: newHealthTracker(0, 0)}
+	reloaded.trusted.Store("http://1.1.1.1:5678", &node.ZgsClient{})
+
+	n, err := reloaded.read()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []*NodeRecord{
+		{ShardedNode: shard.ShardedNode{URL: "http://2.2.2.2:5678", Latency: 20, Since: 200}, Failures: 2},
+	}, reloaded.records())
+
+	// cache file not exists
+	n, err = (&NodeManager{cacheFile: filepath.Join(t.TempDir(), "none.json"), health: newHealthTracker(0, 0)}).read()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestFileLocationCacheFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "locations.json")
+	nodes := []*shard.ShardedNode{{URL: "http://1.1.1.1:5678", Config: shard.ShardConfig{NumShard: 1}}}
+
+	newCache := func(expiry time.Duration) *FileLocationCache {
+		return &FileLocationCache{
+			cache:     expirable.NewLRU[uint64, []*shard.ShardedNode](10, nil, expiry),
+			expiry:    expiry,
+			cacheFile: file,
+		}
+	}
+
+	cache := newCache(time.Hour)
+	cache.cache.Add(1, nodes)
+	cache.cache.Add(2, nodes)
+	assert.NoError(t, cache.write())
+
+	reloaded := newCache(time.Hour)
+	entries, err := reloaded.read()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, []uint64{1, 2}, reloaded.cache.Keys())
+
+	loaded, ok := reloaded.cache.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, nodes, loaded)
+
+	// cache file expired
+	var data fileLocationCacheData
+	_, err = readCacheFile(file, &data)
+	assert.NoError(t, err)
+	data.Time = time.Now().Add(-2 * time.Hour).Unix()
+	assert.NoError(t, writeCacheFile(file, &data))
+
+	expired := newCache(time.Hour)
+	entries, err = expired.read()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, 0, expired.cache.Len())
+}
+
+// fakeZgsApi serves file info of storage node, and fails for tx seqs in errs.
+type fakeZgsApi struct {
+	infos map[uint64]*node.FileInfo
+	errs  map[uint64]bool
+}
+
+func (api *fakeZgsApi) GetFileInfoByTxSeq(ctx context.Context, txSeq uint64) (*node.FileInfo, error) {
+	if api.errs[txSeq] {
+		return nil, errors.New("temporarily unavailable")
+	}
+
+	return api.infos[txSeq], nil
+}
+
+func TestFileLocationCacheRevalidate(t *testing.T) {
+	server := httptest.NewServer(rpc.MustNewHandler(map[string]interface{}{
+		"zgs": &fakeZgsApi{
+			infos: map[uint64]*node.FileInfo{
+				1: {Finalized: true},
+				4: {Finalized: false},
+			},
+			errs: map[uint64]bool{2: true},
+		},
+	}))
+	defer server.Close()
+
+	// unavailable node
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	a := &shard.ShardedNode{URL: server.URL, Config: shard.ShardConfig{NumShard: 1}}
+	b := &shard.ShardedNode{URL: down.URL, Config: shard.ShardConfig{NumShard: 1}}
+
+	entries := []fileLocationEntry{
+		{TxSeq: 1, Nodes: []*shard.ShardedNode{a, b}},
+		{TxSeq: 2, Nodes: []*shard.ShardedNode{a}},
+		{TxSeq: 3, Nodes: []*shard.ShardedNode{b}},
+		{TxSeq: 4, Nodes: []*shard.ShardedNode{a}},
+		{TxSeq: 5, Nodes: []*shard.ShardedNode{a, b}},
+	}
+
+	cache := FileLocationCache{cache: expirable.NewLRU[uint64, []*shard.ShardedNode](10, nil, time.Hour)}
+	for _, v := range entries {
+		cache.cache.Add(v.TxSeq, v.Nodes)
+	}
+
+	cache.revalidate(entries)
+
+	// nodes kept if failed to query, and dropped if file not finalized or not found
+	assert.Equal(t, []uint64{1, 2, 3, 5}, cache.cache.Keys())
+
+	for txSeq, expected := range map[uint64][]*shard.ShardedNode{
+		1: {a, b},
+		2: {a},
+		3: {b},
+		5: {b},
+	} {
+		nodes, _ := cache.cache.Peek(txSeq)
+		assert.Equal(t, expected, nodes, "txSeq = %v", txSeq)
+	}
+}
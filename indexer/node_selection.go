@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// shardLocation converts the IP location to geographic location of node, or nil if coordinates unavailable.
+func (loc *IPLocation) shardLocation() *shard.Location {
+	result, err := shard.ParseCoordinates(loc.Location)
+	if err != nil {
+		return nil
+	}
+
+	result.Country = loc.Country
+	result.Region = loc.Region
+
+	return result
+}
+
+// withLocations returns copies of nodes along with the resolved geographic locations.
+func withLocations(nodes []*shard.ShardedNode) []*shard.ShardedNode {
+	result := make([]*shard.ShardedNode, 0, len(nodes))
+
+	for _, v := range nodes {
+		node := *v
+
+		if loc, ok := defaultIPLocationManager.Get(parseIP(v.URL)); ok {
+			node.Location = loc.shardLocation()
+		}
+
+		result = append(result, &node)
+	}
+
+	return result
+}
+
+// callerIP returns the IP address of RPC caller. The proxy headers are respected only if the request comes
+// from a trusted proxy, in which case the rightmost untrusted address of X-Forwarded-For is the caller.
+func callerIP(ctx context.Context) string {
+	request, ok := ctx.Value("request").(*http.Request)
+	if !ok {
+		return ""
+	}
+
+	ip, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		ip = request.RemoteAddr
+	}
+
+	if !defaultIPLocationManager.trustedProxy(ip) {
+		return ip
+	}
+
+	if forwarded := request.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			if ip = strings.TrimSpace(hops[i]); !defaultIPLocationManager.trustedProxy(ip) {
+				break
+			}
+		}
+
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(request.Header.Get("X-Real-IP")); len(realIP) > 0 {
+		return realIP
+	}
+
+	return ip
+}
+
+// locateCaller returns the IP location of RPC caller.
+func locateCaller(ctx context.Context) (*IPLocation, error) {
+	ip := callerIP(ctx)
+	if len(ip) == 0 {
+		return nil, errors.New("Caller IP unavailable")
+	}
+
+	loc, err := defaultIPLocationManager.Query(ip)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Failed to query IP location of %v", ip)
+	}
+
+	return loc, nil
+}
+
+// resolveMethod resolves the coordinates of RPC caller for nearest selection if coordinates not specified.
+func resolveMethod(ctx context.Context, method string) string {
+	if method != shard.MethodNearest {
+		return method
+	}
+
+	loc, err := locateCaller(ctx)
+	if err == nil && loc.shardLocation() != nil {
+		return shard.MethodNearest + ":" + loc.Location
+	}
+
+	logrus.WithError(err).Debug("Failed to locate caller, select nearest nodes by latency")
+
+	return method
+}
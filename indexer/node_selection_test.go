@@ -0,0 +1,67 @@
+package indexer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCallerIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	defaultIPLocationManager.config.TrustedProxies = []net.IPNet{*proxies}
+	defer func() { defaultIPLocationManager.config.TrustedProxies = nil }()
+
+	callerIPOf := func(remoteAddr string, headers map[string]string) string {
+		request, _ := http.NewRequest(http.MethodPost, "/", nil)
+		request.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+
+		return callerIP(context.WithValue(context.Background(), "request", request))
+	}
+
+	// proxy headers ignored if not from trusted proxy
+	assert.Equal(t, "1.1.1.1", callerIPOf("1.1.1.1:1234", map[string]string{"X-Forwarded-For": "2.2.2.2"}))
+	assert.Equal(t, "1.1.1.1", callerIPOf("1.1.1.1:1234", map[string]string{"X-Real-IP": "2.2.2.2"}))
+
+	// rightmost untrusted address of forwarded chain
+	assert.Equal(t, "2.2.2.2", callerIPOf("10.0.0.1:1234", map[string]string{"X-Forwarded-For": "3.3.3.3, 2.2.2.2, 10.0.0.2"}))
+	assert.Equal(t, "2.2.2.2", callerIPOf("10.0.0.1:1234", map[string]string{"X-Real-IP": "2.2.2.2"}))
+	assert.Equal(t, "10.0.0.1", callerIPOf("10.0.0.1:1234", nil))
+}
+
+func TestIPLocationNegativeCache(t *testing.T) {
+	var queries int
+
+	manager := IPLocationManager{}
+	manager.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		queries++
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"country":"US"}`)),
+		}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		loc, err := manager.Query("1.1.1.1")
+		assert.NoError(t, err)
+		assert.Equal(t, "US", loc.Country)
+	}
+
+	// partial location cached, but not persisted
+	assert.Equal(t, 1, queries)
+	assert.Empty(t, manager.All())
+}
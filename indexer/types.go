@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/0glabs/0g-storage-client/common/shard"
+	"github.com/0glabs/0g-storage-client/indexer/catalog"
 )
 
 type ShardedNodes struct {
@@ -22,4 +23,14 @@ type Interface interface {
 	GetNodeLocations(ctx context.Context) (map[string]*IPLocation, error)
 
 	GetFileLocations(ctx context.Context, root string) ([]*shard.ShardedNode, error)
+
+	GetNodeHealth(ctx context.Context) ([]*NodeHealth, error)
+
+	SelectShardedNodes(ctx context.Context, expectedReplica uint, method string) ([]*shard.ShardedNode, error)
+
+	GetClientLocation(ctx context.Context) (*IPLocation, error)
+
+	GetCatalogFiles(ctx context.Context, query catalog.Query) (*catalog.QueryResult, error)
+
+	GetCatalogFile(ctx context.Context, txSeq uint64) (*catalog.File, error)
 }
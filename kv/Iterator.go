@@ -16,6 +16,7 @@ type Iterator struct {
 	streamId    common.Hash
 	version     uint64
 	currentPair *node.KeyValue
+	currentKey  []byte // key stored in kv stream at current position, which is hashed for encrypted stream if key hashing enabled
 }
 
 // Valid check if current position is exist
@@ -31,23 +32,33 @@ func (iter *Iterator) KeyValue() *node.KeyValue {
 func (iter *Iterator) move(ctx context.Context, kv *node.KeyValue) error {
 	if kv == nil {
 		iter.currentPair = nil
+		iter.currentKey = nil
 		return nil
 	}
-	value, err := iter.client.GetValue(ctx, iter.streamId, kv.Key, iter.version)
+	value, err := iter.client.getValue(ctx, iter.streamId, kv.Key, iter.version)
 	if err != nil {
 		return err
 	}
-	iter.currentPair = &node.KeyValue{
+	if value == nil {
+		return errors.New("value not found")
+	}
+	pair, err := iter.client.option.Keyring.decryptPair(iter.streamId, &node.KeyValue{
 		Version: value.Version,
 		Key:     kv.Key,
 		Data:    value.Data,
 		Size:    value.Size,
+	})
+	if err != nil {
+		return err
 	}
+	iter.currentPair = pair
+	iter.currentKey = kv.Key
 	return nil
 }
 
 // SeekBefore seek to the position before given key(inclusive)
 func (iter *Iterator) SeekBefore(ctx context.Context, key []byte) error {
+	key = iter.client.option.Keyring.storedKey(iter.streamId, key)
 	kv, err := iter.client.GetPrev(ctx, iter.streamId, key, 0, 0, true, iter.version)
 	if err != nil {
 		return err
@@ -57,6 +68,7 @@ func (iter *Iterator) SeekBefore(ctx context.Context, key []byte) error {
 
 // SeekAfter seek to the position after given key(inclusive)
 func (iter *Iterator) SeekAfter(ctx context.Context, key []byte) error {
+	key = iter.client.option.Keyring.storedKey(iter.streamId, key)
 	kv, err := iter.client.GetNext(ctx, iter.streamId, key, 0, 0, true, iter.version)
 	if err != nil {
 		return err
@@ -87,7 +99,7 @@ func (iter *Iterator) Next(ctx context.Context) error {
 	if !iter.Valid() {
 		return errIteratorInvalid
 	}
-	kv, err := iter.client.GetNext(ctx, iter.streamId, iter.currentPair.Key, 0, 0, false, iter.version)
+	kv, err := iter.client.GetNext(ctx, iter.streamId, iter.currentKey, 0, 0, false, iter.version)
 	if err != nil {
 		return err
 	}
@@ -99,7 +111,7 @@ func (iter *Iterator) Prev(ctx context.Context) error {
 	if !iter.Valid() {
 		return errIteratorInvalid
 	}
-	kv, err := iter.client.GetPrev(ctx, iter.streamId, iter.currentPair.Key, 0, 0, false, iter.version)
+	kv, err := iter.client.GetPrev(ctx, iter.streamId, iter.currentKey, 0, 0, false, iter.version)
 	if err != nil {
 		return err
 	}
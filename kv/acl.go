@@ -0,0 +1,86 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// ACLReport is the access control facts of an account and/or a key in stream. Facts that are not
+// applicable to the query are nil, e.g. IsAdmin if account not specified.
+type ACLReport struct {
+	StreamId common.Hash     `json:"streamId"`
+	Account  *common.Address `json:"account,omitempty"`
+	Key      hexutil.Bytes   `json:"key,omitempty"`
+
+	IsAdmin            *bool `json:"isAdmin,omitempty"`            // account is admin of stream
+	IsWriterOfStream   *bool `json:"isWriterOfStream,omitempty"`   // account is writer of all normal keys
+	IsSpecialKey       *bool `json:"isSpecialKey,omitempty"`       // key has unique access control
+	IsWriterOfKey      *bool `json:"isWriterOfKey,omitempty"`      // account is writer of special key
+	HasWritePermission *bool `json:"hasWritePermission,omitempty"` // account is able to write key
+}
+
+// QueryACL queries the access control facts of account and/or key in stream, at least one of which
+// should be specified.
+func (c *Client) QueryACL(ctx context.Context, streamId common.Hash, account *common.Address, key []byte, version ...uint64) (*ACLReport, error) {
+	if account == nil && len(key) == 0 {
+		return nil, errors.New("either account or key should be specified")
+	}
+
+	report := ACLReport{
+		StreamId: streamId,
+		Account:  account,
+		Key:      key,
+	}
+
+	query := func(field **bool, name string, fn func() (bool, error)) error {
+		v, err := fn()
+		if err != nil {
+			return errors.WithMessagef(err, "failed to query %v", name)
+		}
+
+		*field = &v
+
+		return nil
+	}
+
+	if account != nil {
+		if err := query(&report.IsAdmin, "admin role", func() (bool, error) {
+			return c.IsAdmin(ctx, *account, streamId, version...)
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := query(&report.IsWriterOfStream, "stream write role", func() (bool, error) {
+			return c.IsWriterOfStream(ctx, *account, streamId, version...)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(key) > 0 {
+		if err := query(&report.IsSpecialKey, "special key", func() (bool, error) {
+			return c.IsSpecialKey(ctx, streamId, key, version...)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if account != nil && len(key) > 0 {
+		if err := query(&report.IsWriterOfKey, "key write role", func() (bool, error) {
+			return c.IsWriterOfKey(ctx, *account, streamId, key, version...)
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := query(&report.HasWritePermission, "write permission", func() (bool, error) {
+			return c.HasWritePermission(ctx, *account, streamId, key, version...)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,111 @@
+package kv_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryACL(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	admin := common.HexToAddress("0xa1")
+	writer := common.HexToAddress("0xa2")
+	keyWriter := common.HexToAddress("0xa3")
+	special := []byte("special")
+	normal := []byte("normal")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	// sender becomes admin of new stream
+	batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.Set(streamId, normal, []byte("1"))
+	applyBatch(t, server, admin, batcher)
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.GrantWriteRole(streamId, writer)
+	batcher.SetKeyToSpecial(streamId, special)
+	batcher.GrantSpecialWriteRole(streamId, special, keyWriter)
+	granted := applyBatch(t, server, admin, batcher)
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.RevokeWriteRole(streamId, writer)
+	applyBatch(t, server, admin, batcher)
+
+	yes, no := true, false
+
+	// account only
+	report, err := client.QueryACL(ctx, streamId, &writer, nil, granted)
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.ACLReport{
+		StreamId:         streamId,
+		Account:          &writer,
+		IsAdmin:          &no,
+		IsWriterOfStream: &yes,
+	}, report)
+
+	// write role revoked at the latest version
+	report, err = client.QueryACL(ctx, streamId, &writer, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &no, report.IsWriterOfStream)
+
+	report, err = client.QueryACL(ctx, streamId, &admin, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &yes, report.IsAdmin)
+	assert.Equal(t, &no, report.IsWriterOfStream)
+
+	// key only
+	report, err = client.QueryACL(ctx, streamId, nil, special)
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.ACLReport{
+		StreamId:     streamId,
+		Key:          special,
+		IsSpecialKey: &yes,
+	}, report)
+
+	// account and key
+	report, err = client.QueryACL(ctx, streamId, &keyWriter, special)
+	assert.NoError(t, err)
+	assert.Equal(t, &kv.ACLReport{
+		StreamId:           streamId,
+		Account:            &keyWriter,
+		Key:                special,
+		IsAdmin:            &no,
+		IsWriterOfStream:   &no,
+		IsSpecialKey:       &yes,
+		IsWriterOfKey:      &yes,
+		HasWritePermission: &yes,
+	}, report)
+
+	// special key could only be written by key writers
+	report, err = client.QueryACL(ctx, streamId, &admin, special)
+	assert.NoError(t, err)
+	assert.Equal(t, &no, report.IsWriterOfKey)
+	assert.Equal(t, &no, report.HasWritePermission)
+
+	report, err = client.QueryACL(ctx, streamId, &admin, normal)
+	assert.NoError(t, err)
+	assert.Equal(t, &no, report.IsSpecialKey)
+	assert.Equal(t, &yes, report.HasWritePermission)
+
+	// special key not set before granted
+	report, err = client.QueryACL(ctx, streamId, &keyWriter, special, granted-1)
+	assert.NoError(t, err)
+	assert.Equal(t, &no, report.IsSpecialKey)
+	assert.Equal(t, &no, report.IsWriterOfKey)
+	assert.Equal(t, &no, report.HasWritePermission)
+
+	// neither account nor key specified
+	_, err = client.QueryACL(ctx, streamId, nil, nil)
+	assert.Error(t, err)
+}
@@ -2,9 +2,10 @@ package kv
 
 import (
 	"context"
+	"math/big"
+	"time"
 
 	zg_common "github.com/0glabs/0g-storage-client/common"
-	"github.com/0glabs/0g-storage-client/core"
 	"github.com/0glabs/0g-storage-client/node"
 	"github.com/0glabs/0g-storage-client/transfer"
 	"github.com/ethereum/go-ethereum/common"
@@ -13,6 +14,34 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrReadSetTooLarge           = errors.New("read set too large to split")
+	ErrSplitReadsWithoutKvClient = errors.New("kv client required to split batch with keys read")
+)
+
+// ExecOption is the option to execute batch.
+type ExecOption struct {
+	transfer.UploadOption
+
+	// Split splits oversize batch, whose reads, writes or access controls exceed the max set size, into
+	// multiple ordered submissions. KvClient is required if oversize batch with keys read.
+	Split bool
+
+	// KvClient is used to wait for the replay results of submissions if specified. In this case, SkipTx of
+	// UploadOption is ignored and new log entries are always submitted, since the replay result of an existing
+	// log entry could not be told apart from the submission of this batch.
+	KvClient     *node.KvClient
+	WaitTimeout  time.Duration // timeout to wait for the replay result of each submission, 0 for no timeout
+	PollInterval time.Duration // interval to poll the replay result, default 1 second
+}
+
+// ExecResult is the result of executed batch, which contains multiple submissions if split.
+type ExecResult struct {
+	TxHashes []common.Hash // empty hash for submission skipped if SkipTx specified
+	TxSeqs   []uint64
+	Results  []*TxResult // replay results if KvClient specified
+}
+
 // Batcher struct to cache and execute KV write and access control operations.
 type Batcher struct {
 	*streamDataBuilder
@@ -36,34 +65,229 @@ func NewBatcher(version uint64, clients []*node.ZgsClient, w3Client *web3go.Clie
 // Note, this may be time consuming operation, e.g. several seconds or even longer.
 // When it comes to a time sentitive context, it should be executed in a separate go-routine.
 func (b *Batcher) Exec(ctx context.Context, option ...transfer.UploadOption) (common.Hash, error) {
+	txHash, _, err := b.exec(ctx, option...)
+	return txHash, err
+}
+
+func (b *Batcher) exec(ctx context.Context, option ...transfer.UploadOption) (common.Hash, *transfer.Uploader, error) {
 	// build stream data
 	streamData, err := b.Build()
 	if err != nil {
-		return common.Hash{}, errors.WithMessage(err, "Failed to build stream data")
-	}
-
-	encoded, err := streamData.Encode()
-	if err != nil {
-		return common.Hash{}, errors.WithMessage(err, "Failed to encode data")
-	}
-	data, err := core.NewDataInMemory(encoded)
-	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, nil, errors.WithMessage(err, "Failed to build stream data")
 	}
 
 	// upload file
 	uploader, err := transfer.NewUploader(ctx, b.w3Client, b.clients, zg_common.LogOption{Logger: b.logger})
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, nil, err
 	}
 	var opt transfer.UploadOption
 	if len(option) > 0 {
 		opt = option[0]
 	}
 	opt.Tags = b.buildTags()
-	txHash, _, err := uploader.Upload(ctx, data, opt)
+	txHash, _, err := b.upload(ctx, uploader, streamData, opt)
+	if err != nil {
+		return txHash, nil, err
+	}
+	return txHash, uploader, nil
+}
+
+// upload submits the serialized stream data to 0g storage network with tags specified in option, and returns
+// the tx hash and data root. Note, the tx hash is empty if submission skipped.
+func (b *Batcher) upload(ctx context.Context, uploader *transfer.Uploader, streamData *StreamData, opt transfer.UploadOption) (common.Hash, common.Hash, error) {
+	// values set from readers are not buffered in memory
+	data, err := streamData.encodeData()
+	if err != nil {
+		return common.Hash{}, common.Hash{}, errors.WithMessage(err, "Failed to encode data")
+	}
+
+	txHash, root, err := uploader.Upload(ctx, data, opt)
+	if err != nil {
+		return txHash, root, errors.WithMessagef(err, "Failed to upload data")
+	}
+
+	return txHash, root, nil
+}
+
+// ExecWithOption is similar to Exec, but supports to split oversize batch into multiple ordered submissions
+// if option.Split specified, and returns the tx hashes and seqs of all submissions.
+//
+// If option.KvClient specified, it waits until each submission replayed by the kv node, and returns the typed
+// results, so that the written keys could be read afterwards. The execution stops with error of the result
+// type, e.g. ErrTxConflict, if any submission is not committed.
+//
+// Note, the split submissions are not atomic, and the submitted ones will not be reverted if the later ones
+// failed. Keys read are only checked in the first submission, and ErrReadSetTooLarge is returned if the
+// read set exceeds the max set size. To keep the later submissions from committing once the first one
+// conflicts, option.KvClient is required to split batch with keys read, otherwise
+// ErrSplitReadsWithoutKvClient is returned.
+func (b *Batcher) ExecWithOption(ctx context.Context, option ExecOption) (*ExecResult, error) {
+	parts, err := b.splitParts(option)
+	if err != nil {
+		return nil, err
+	}
+
+	uploader, err := transfer.NewUploader(ctx, b.w3Client, b.clients, zg_common.LogOption{Logger: b.logger})
+	if err != nil {
+		return nil, err
+	}
+
+	opt := option.UploadOption
+
+	// always submit new log entries to wait for the replay results
+	if option.KvClient != nil {
+		opt.SkipTx = false
+	}
+
+	return b.execParts(ctx, parts, option, func(i int, part *StreamData) (common.Hash, uint64, error) {
+		opt.Tags = createTags(part.streamIds()...)
+
+		// nonce increases for subsequent submissions
+		if option.Nonce != nil {
+			opt.Nonce = new(big.Int).Add(option.Nonce, big.NewInt(int64(i)))
+		}
+
+		txHash, root, err := b.upload(ctx, uploader, part, opt)
+		if err != nil {
+			return txHash, 0, err
+		}
+
+		var txSeq uint64
+		if txHash == (common.Hash{}) {
+			txSeq, err = b.existingTxSeq(ctx, root)
+		} else {
+			txSeq, err = b.txSeqOf(ctx, uploader, txHash)
+		}
+		if err != nil {
+			return txHash, 0, errors.WithMessage(err, "Failed to get tx seq")
+		}
+
+		return txHash, txSeq, nil
+	})
+}
+
+// splitParts builds the cached operations into ordered parts to submit as specified in option.
+func (b *Batcher) splitParts(option ExecOption) ([]*StreamData, error) {
+	streamData, err := b.collect(false)
 	if err != nil {
-		return txHash, errors.WithMessagef(err, "Failed to upload data")
+		return nil, errors.WithMessage(err, "Failed to build stream data")
 	}
-	return txHash, nil
+
+	if !option.Split {
+		if err = streamData.checkSize(); err != nil {
+			return nil, errors.WithMessage(err, "Failed to build stream data")
+		}
+
+		return []*StreamData{streamData}, nil
+	}
+
+	parts, err := streamData.split(maxSetSize)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to build stream data")
+	}
+
+	// later parts are not checked by the kv node against keys read, and should only be submitted once the
+	// first part committed
+	if len(parts) > 1 && len(streamData.Reads) > 0 && option.KvClient == nil {
+		return nil, ErrSplitReadsWithoutKvClient
+	}
+
+	return parts, nil
+}
+
+// execParts submits the parts in order, and waits for the replay result of each submission if KvClient
+// specified in option.
+func (b *Batcher) execParts(ctx context.Context, parts []*StreamData, option ExecOption,
+	submit func(i int, part *StreamData) (common.Hash, uint64, error)) (*ExecResult, error) {
+	var kvClient *Client
+	if option.KvClient != nil {
+		kvClient = NewClient(option.KvClient)
+	}
+
+	var result ExecResult
+
+	for i, part := range parts {
+		txHash, txSeq, err := submit(i, part)
+		if err != nil {
+			return &result, errors.WithMessagef(err, "Failed to execute batch %v of %v", i+1, len(parts))
+		}
+
+		result.TxHashes = append(result.TxHashes, txHash)
+		result.TxSeqs = append(result.TxSeqs, txSeq)
+
+		if kvClient != nil {
+			txResult, err := b.waitTxResult(ctx, kvClient, txSeq, option)
+			if err != nil {
+				return &result, errors.WithMessagef(err, "Failed to wait for result of batch %v of %v", i+1, len(parts))
+			}
+
+			result.Results = append(result.Results, txResult)
+
+			if err = txResult.Err(); err != nil {
+				return &result, errors.WithMessagef(err, "Batch %v of %v not committed, txSeq = %v", i+1, len(parts), txSeq)
+			}
+		}
+
+		if len(parts) > 1 {
+			b.logger.WithFields(logrus.Fields{
+				"batch":  i + 1,
+				"total":  len(parts),
+				"txHash": txHash,
+				"txSeq":  txSeq,
+			}).Info("Split batch executed")
+		}
+	}
+
+	return &result, nil
+}
+
+// waitTxResult waits for the replay result of given transaction within the timeout specified in option.
+func (b *Batcher) waitTxResult(ctx context.Context, client *Client, txSeq uint64, option ExecOption) (*TxResult, error) {
+	if option.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, option.WaitTimeout)
+		defer cancel()
+	}
+
+	return client.WaitTxResult(ctx, txSeq, option.PollInterval)
+}
+
+// existingTxSeq returns the sequence number of log entry already settled on chain with the given data root.
+func (b *Batcher) existingTxSeq(ctx context.Context, root common.Hash) (uint64, error) {
+	for _, client := range b.clients {
+		info, err := client.GetFileInfo(ctx, root, true)
+		if err != nil {
+			return 0, errors.WithMessage(err, "Failed to get file info")
+		}
+
+		if info != nil {
+			return info.Tx.Seq, nil
+		}
+	}
+
+	return 0, errors.New("Log entry not found")
+}
+
+// txSeqOf returns the sequence number of log entry submitted in the given transaction.
+func (b *Batcher) txSeqOf(ctx context.Context, uploader *transfer.Uploader, txHash common.Hash) (uint64, error) {
+	receipt, err := b.w3Client.Eth.TransactionReceipt(txHash)
+	if err != nil {
+		return 0, errors.WithMessage(err, "Failed to get transaction receipt")
+	}
+
+	if receipt == nil {
+		return 0, errors.New("Transaction receipt not found")
+	}
+
+	seqNums, err := uploader.ParseLogs(ctx, receipt.Logs)
+	if err != nil {
+		return 0, errors.WithMessage(err, "Failed to parse logs")
+	}
+
+	if len(seqNums) != 1 {
+		return 0, errors.New("log entry event count mismatch")
+	}
+
+	return seqNums[0], nil
 }
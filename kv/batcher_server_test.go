@@ -0,0 +1,80 @@
+package kv_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecSplitWithReads(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	sender := common.HexToAddress("0xa1")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+
+	// sender becomes admin of new stream
+	init := kv.NewBatcher(math.MaxUint64, nil, nil)
+	init.Set(streamId, []byte("read"), []byte("1"))
+	version := applyBatch(t, server, sender, init)
+
+	// writes exceed the max set size
+	numWrites := 1<<16 + 1
+	newBatcher := func() *kv.Batcher {
+		batcher := kv.NewBatcher(version, nil, nil)
+		batcher.Watch(streamId, []byte("read"))
+		for i := 0; i < numWrites; i++ {
+			batcher.Set(streamId, []byte(fmt.Sprintf("key-%v", i)), []byte("v"))
+		}
+		return batcher
+	}
+
+	var submitted int
+	submit := func(encoded []byte) uint64 {
+		submitted++
+		txSeq, _ := server.Apply(sender, encoded)
+		return txSeq
+	}
+
+	// kv client required to check the result of first part
+	_, err := newBatcher().ExecWith(ctx, kv.ExecOption{Split: true}, submit)
+	assert.Equal(t, kv.ErrSplitReadsWithoutKvClient, err)
+	assert.Zero(t, submitted)
+
+	// later parts not submitted once the first part conflicts
+	server.Set(streamId, []byte("read"), []byte("2"))
+
+	result, err := newBatcher().ExecWith(ctx, kv.ExecOption{Split: true, KvClient: kvClient}, submit)
+	assert.True(t, errors.Is(err, kv.ErrTxConflict))
+	assert.Equal(t, 1, submitted)
+	assert.Equal(t, 1, len(result.TxSeqs))
+	assert.Equal(t, kv.TxStatusConflict, result.Results[0].Status)
+
+	val, err := kv.NewClient(kvClient).GetValue(ctx, streamId, []byte(fmt.Sprintf("key-%v", numWrites-1)))
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	// all parts committed without conflict
+	submitted = 0
+	version = server.NextTxSeq() - 1
+
+	result, err = newBatcher().ExecWith(ctx, kv.ExecOption{Split: true, KvClient: kvClient}, submit)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, submitted)
+	assert.Equal(t, 2, len(result.Results))
+
+	val, err = kv.NewClient(kvClient).GetValue(ctx, streamId, []byte(fmt.Sprintf("key-%v", numWrites-1)))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v"), val.Data)
+}
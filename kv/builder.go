@@ -1,11 +1,12 @@
 package kv
 
 import (
-	"errors"
+	"io"
 	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
 )
 
 const maxSetSize = 1 << 16 // 64K
@@ -21,11 +22,19 @@ var errKeyTooLarge = errors.New("key too large")
 var errKeyIsEmpty = errors.New("key is empty")
 
 type streamDataBuilder struct {
-	version   uint64                            // The version of all read and written keys must be less than this value when the cached KV operations are settled on chain.
-	streamIds map[common.Hash]bool              // cached stream ids, used to build tags
-	controls  []accessControl                   // cached access control operations
-	reads     map[common.Hash]map[string]bool   // cached keys to read
-	writes    map[common.Hash]map[string][]byte // cached keys to write
+	version   uint64                                 // The version of all read and written keys must be less than this value when the cached KV operations are settled on chain.
+	streamIds map[common.Hash]bool                   // cached stream ids, used to build tags
+	controls  []accessControl                        // cached access control operations
+	reads     map[common.Hash]map[string]bool        // cached keys to read
+	writes    map[common.Hash]map[string]streamValue // cached keys to write
+	keyring   *Keyring                               // keys to encrypt values of streams, nil for no encryption
+}
+
+// streamValue is the value to write, which is either in memory or read from reader on demand.
+type streamValue struct {
+	data   []byte
+	reader io.ReaderAt
+	size   int64 // size of data read from reader
 }
 
 // newStreamDataBuilder initialize a stream data builder.
@@ -35,22 +44,42 @@ func newStreamDataBuilder(version uint64) *streamDataBuilder {
 		controls:  make([]accessControl, 0),
 		version:   version,
 		reads:     make(map[common.Hash]map[string]bool),
-		writes:    make(map[common.Hash]map[string][]byte),
+		writes:    make(map[common.Hash]map[string]streamValue),
 	}
 }
 
 // Build serialize all cached KV operations to StreamData.
 func (builder *streamDataBuilder) Build(sorted ...bool) (*StreamData, error) {
-	var err error
-	data := StreamData{
-		Version: builder.version,
+	data, err := builder.collect(len(sorted) > 0 && sorted[0])
+	if err != nil {
+		return nil, err
 	}
 
-	// controls
-	if data.Controls, err = builder.buildAccessControl(); err != nil {
+	if err = data.checkSize(); err != nil {
 		return nil, err
 	}
 
+	return data, nil
+}
+
+// collect collects all cached KV operations without limit of set size.
+func (builder *streamDataBuilder) collect(sorted bool) (*StreamData, error) {
+	data := StreamData{
+		Version:  builder.version,
+		Controls: builder.controls,
+	}
+
+	// keys of access controls are hashed for encrypted streams if key hashing enabled
+	if builder.keyring != nil {
+		data.Controls = make([]accessControl, 0, len(builder.controls))
+		for _, v := range builder.controls {
+			if v.Key != nil {
+				v.Key = builder.keyring.storedKey(v.StreamId, v.Key)
+			}
+			data.Controls = append(data.Controls, v)
+		}
+	}
+
 	// reads
 	for streamId, keys := range builder.reads {
 		for k := range keys {
@@ -63,12 +92,8 @@ func (builder *streamDataBuilder) Build(sorted ...bool) (*StreamData, error) {
 			}
 			data.Reads = append(data.Reads, streamRead{
 				StreamId: streamId,
-				Key:      key,
+				Key:      builder.keyring.storedKey(streamId, key),
 			})
-
-			if len(data.Reads) > maxSetSize {
-				return nil, errSizeTooLarge
-			}
 		}
 	}
 
@@ -82,44 +107,69 @@ func (builder *streamDataBuilder) Build(sorted ...bool) (*StreamData, error) {
 			if len(key) == 0 {
 				return nil, errKeyIsEmpty
 			}
-			data.Writes = append(data.Writes, streamWrite{
-				StreamId: streamId,
-				Key:      key,
-				Data:     d,
-			})
-
-			if len(data.Writes) > maxSetSize {
-				return nil, errSizeTooLarge
+			write, err := builder.encryptWrite(streamId, key, d)
+			if err != nil {
+				return nil, err
 			}
+			data.Writes = append(data.Writes, write)
 		}
 	}
 
-	if len(sorted) > 0 {
-		if sorted[0] {
-			sort.SliceStable(data.Reads, func(i, j int) bool {
-				streamIdI := data.Reads[i].StreamId.Hex()
-				streamIdJ := data.Reads[j].StreamId.Hex()
-				if streamIdI == streamIdJ {
-					return hexutil.Encode(data.Reads[i].Key) < hexutil.Encode(data.Reads[j].Key)
-				} else {
-					return streamIdI < streamIdJ
-				}
-			})
-			sort.SliceStable(data.Writes, func(i, j int) bool {
-				streamIdI := data.Writes[i].StreamId.Hex()
-				streamIdJ := data.Writes[j].StreamId.Hex()
-				if streamIdI == streamIdJ {
-					return hexutil.Encode(data.Writes[i].Key) < hexutil.Encode(data.Writes[j].Key)
-				} else {
-					return streamIdI < streamIdJ
-				}
-			})
-		}
+	if sorted {
+		sort.SliceStable(data.Reads, func(i, j int) bool {
+			streamIdI := data.Reads[i].StreamId.Hex()
+			streamIdJ := data.Reads[j].StreamId.Hex()
+			if streamIdI == streamIdJ {
+				return hexutil.Encode(data.Reads[i].Key) < hexutil.Encode(data.Reads[j].Key)
+			} else {
+				return streamIdI < streamIdJ
+			}
+		})
+		sort.SliceStable(data.Writes, func(i, j int) bool {
+			streamIdI := data.Writes[i].StreamId.Hex()
+			streamIdJ := data.Writes[j].StreamId.Hex()
+			if streamIdI == streamIdJ {
+				return hexutil.Encode(data.Writes[i].Key) < hexutil.Encode(data.Writes[j].Key)
+			} else {
+				return streamIdI < streamIdJ
+			}
+		})
 	}
 
 	return &data, nil
 }
 
+// encryptWrite returns the write of given key-value, which is encrypted if the stream is encrypted. Note,
+// value to read from reader is buffered in memory to encrypt.
+func (builder *streamDataBuilder) encryptWrite(streamId common.Hash, key []byte, value streamValue) (streamWrite, error) {
+	if !builder.keyring.Encrypted(streamId) {
+		return streamWrite{
+			StreamId: streamId,
+			Key:      key,
+			Data:     value.data,
+			reader:   value.reader,
+			size:     value.size,
+		}, nil
+	}
+
+	data := value.data
+	if value.reader != nil {
+		data = make([]byte, value.size)
+		if _, err := io.ReadFull(io.NewSectionReader(value.reader, 0, value.size), data); err != nil {
+			return streamWrite{}, errors.WithMessagef(err, "failed to read value of key %x", key)
+		}
+	}
+
+	storedKey := builder.keyring.storedKey(streamId, key)
+
+	encrypted, err := builder.keyring.encrypt(streamId, key, storedKey, data)
+	if err != nil {
+		return streamWrite{}, errors.WithMessagef(err, "failed to encrypt value of key %x", key)
+	}
+
+	return streamWrite{StreamId: streamId, Key: storedKey, Data: encrypted}, nil
+}
+
 func (builder *streamDataBuilder) addStreamId(streamId common.Hash) {
 	builder.streamIds[streamId] = true
 }
@@ -142,6 +192,13 @@ func (builder *streamDataBuilder) buildTags(sorted ...bool) []byte {
 	return createTags(ids...)
 }
 
+// SetKeyring Set the keyring to encrypt values of streams. Keys and values of streams in keyring are encrypted
+// when the cached KV operations are serialized.
+func (builder *streamDataBuilder) SetKeyring(keyring *Keyring) *streamDataBuilder {
+	builder.keyring = keyring
+	return builder
+}
+
 // SetVersion Set the expected version of keys.
 func (builder *streamDataBuilder) SetVersion(version uint64) *streamDataBuilder {
 	builder.version = version
@@ -162,26 +219,29 @@ func (builder *streamDataBuilder) Watch(streamId common.Hash, key []byte) *strea
 
 // Set Cache a write key operation.
 func (builder *streamDataBuilder) Set(streamId common.Hash, key []byte, data []byte) *streamDataBuilder {
+	return builder.setValue(streamId, key, streamValue{data: data})
+}
+
+// SetFromReader Cache a write key operation, whose value of specified size is read from reader on demand
+// when the batch executed, so that large value will not be buffered in memory. Note, the reader should
+// not be changed or closed until the batch executed.
+func (builder *streamDataBuilder) SetFromReader(streamId common.Hash, key []byte, reader io.ReaderAt, size int64) *streamDataBuilder {
+	return builder.setValue(streamId, key, streamValue{reader: reader, size: size})
+}
+
+func (builder *streamDataBuilder) setValue(streamId common.Hash, key []byte, value streamValue) *streamDataBuilder {
 	builder.addStreamId(streamId)
 
 	if keys, ok := builder.writes[streamId]; ok {
-		keys[hexutil.Encode(key)] = data
+		keys[hexutil.Encode(key)] = value
 	} else {
-		builder.writes[streamId] = make(map[string][]byte)
-		builder.writes[streamId][hexutil.Encode(key)] = data
+		builder.writes[streamId] = make(map[string]streamValue)
+		builder.writes[streamId][hexutil.Encode(key)] = value
 	}
 
 	return builder
 }
 
-func (builder *streamDataBuilder) buildAccessControl() ([]accessControl, error) {
-	if len(builder.controls) > maxSetSize {
-		return nil, errSizeTooLarge
-	}
-
-	return builder.controls, nil
-}
-
 func (builder *streamDataBuilder) withControl(t accessControlType, streamId common.Hash, account *common.Address, key []byte) *streamDataBuilder {
 	builder.addStreamId(streamId)
 
@@ -3,21 +3,64 @@ package kv
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/0glabs/0g-storage-client/node"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 )
 
-// Client client to query data from 0g kv node.
+// Client client to query data from 0g kv nodes, which fails over to other nodes on error.
 type Client struct {
-	node *node.KvClient
+	nodes  []*kvNode
+	option ClientOption
 }
 
-// NewClient creates a new client for kv queries.
-func NewClient(node *node.KvClient) *Client {
-	return &Client{
-		node: node,
+// NewClient creates a new client for kv queries, which reads from any healthy node.
+func NewClient(nodes ...*node.KvClient) *Client {
+	return NewClientWithOption(nodes, ClientOption{})
+}
+
+// NewClientWithOption creates a new client for kv queries with specified read consistency.
+func NewClientWithOption(nodes []*node.KvClient, option ClientOption) *Client {
+	if option.UnhealthyDuration <= 0 {
+		option.UnhealthyDuration = 30 * time.Second
+	}
+
+	c := &Client{option: option}
+	for _, n := range nodes {
+		c.nodes = append(c.nodes, &kvNode{client: n})
 	}
+
+	return c
+}
+
+// WithConsistency returns a client of the specified read consistency, which shares nodes and their health
+// status with the original client.
+func (c *Client) WithConsistency(consistency Consistency) *Client {
+	option := c.option
+	option.Consistency = consistency
+
+	return &Client{c.nodes, option}
+}
+
+// WithMinVersion returns a client that reads from nodes which have replayed the specified version, e.g. the
+// tx seq of a previous write, so as to read your writes.
+func (c *Client) WithMinVersion(version uint64) *Client {
+	option := c.option
+	option.Consistency = ConsistencyMinVersion
+	option.MinVersion = version
+
+	return &Client{c.nodes, option}
+}
+
+// WithKeyring returns a client that decrypts values of encrypted streams in keyring, which shares nodes and
+// their health status with the original client.
+func (c *Client) WithKeyring(keyring *Keyring) *Client {
+	option := c.option
+	option.Keyring = keyring
+
+	return &Client{c.nodes, option}
 }
 
 // NewIterator creates an iterator.
@@ -35,8 +78,31 @@ func (c *Client) NewIterator(streamId common.Hash, version ...uint64) *Iterator
 	}
 }
 
-// GetValue Get value of a given key from kv node.
-func (c *Client) GetValue(ctx context.Context, streamId common.Hash, key []byte, version ...uint64) (val *node.Value, err error) {
+// GetValue Get value of a given key from kv node, and returns nil if key not found. Value of encrypted
+// stream is decrypted if the client has keyring.
+func (c *Client) GetValue(ctx context.Context, streamId common.Hash, key []byte, version ...uint64) (*node.Value, error) {
+	keyring := c.option.Keyring
+	if !keyring.Encrypted(streamId) {
+		return c.getValue(ctx, streamId, key, version...)
+	}
+
+	storedKey := keyring.storedKey(streamId, key)
+
+	val, err := c.getValue(ctx, streamId, storedKey, version...)
+	if err != nil || val == nil {
+		return val, err
+	}
+
+	_, data, err := keyring.decrypt(streamId, storedKey, val.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &node.Value{Version: val.Version, Data: data, Size: uint64(len(data))}, nil
+}
+
+// getValue returns the complete stored value of a given key, and nil if key not found.
+func (c *Client) getValue(ctx context.Context, streamId common.Hash, key []byte, version ...uint64) (val *node.Value, err error) {
 	var v uint64
 	v = math.MaxUint64
 	if len(version) > 0 {
@@ -49,9 +115,9 @@ func (c *Client) GetValue(ctx context.Context, streamId common.Hash, key []byte,
 	}
 	for {
 		var seg *node.Value
-		seg, err = c.node.GetValue(ctx, streamId, key, uint64(len(val.Data)), maxQuerySize, val.Version)
-		if err != nil {
-			return
+		seg, err = c.get(ctx, streamId, key, uint64(len(val.Data)), maxQuerySize, val.Version)
+		if err != nil || seg == nil {
+			return seg, err
 		}
 		if val.Version == math.MaxUint64 {
 			val.Version = seg.Version
@@ -67,62 +133,96 @@ func (c *Client) GetValue(ctx context.Context, streamId common.Hash, key []byte,
 	}
 }
 
-// Get returns paginated value for the specified stream key.
+// Get returns paginated value for the specified stream key. Paginated value of encrypted stream could not be
+// decrypted, so it returns ErrEncryptedStream if the client has keyring of the stream, use GetValue instead.
 func (c *Client) Get(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, version ...uint64) (val *node.Value, err error) {
-	return c.node.GetValue(ctx, streamId, key, startIndex, length, version...)
+	if c.option.Keyring.Encrypted(streamId) {
+		return nil, errors.WithMessage(ErrEncryptedStream, "paginated value could not be decrypted, use GetValue instead")
+	}
+
+	return c.get(ctx, streamId, key, startIndex, length, version...)
+}
+
+// get returns paginated stored value for the specified stream key.
+func (c *Client) get(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, version ...uint64) (val *node.Value, err error) {
+	return callVersioned(ctx, c, func(n *node.KvClient) (*node.Value, error) {
+		return n.GetValue(ctx, streamId, key, startIndex, length, version...)
+	}, valueVersion)
 }
 
 // GetNext returns paginated key-value of the next key of the specified stream key.
 func (c *Client) GetNext(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, inclusive bool, version ...uint64) (val *node.KeyValue, err error) {
-	return c.node.GetNext(ctx, streamId, key, startIndex, length, inclusive, version...)
+	return call(ctx, c, func(n *node.KvClient) (*node.KeyValue, error) {
+		return n.GetNext(ctx, streamId, key, startIndex, length, inclusive, version...)
+	})
 }
 
 // GetPrev returns paginated key-value of the prev key of the specified stream key.
 func (c *Client) GetPrev(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, inclusive bool, version ...uint64) (val *node.KeyValue, err error) {
-	return c.node.GetPrev(ctx, streamId, key, startIndex, length, inclusive, version...)
+	return call(ctx, c, func(n *node.KvClient) (*node.KeyValue, error) {
+		return n.GetPrev(ctx, streamId, key, startIndex, length, inclusive, version...)
+	})
 }
 
 // GetFirst returns paginated key-value of the first key of the specified stream.
 func (c *Client) GetFirst(ctx context.Context, streamId common.Hash, startIndex, length uint64, version ...uint64) (val *node.KeyValue, err error) {
-	return c.node.GetFirst(ctx, streamId, startIndex, length, version...)
+	return call(ctx, c, func(n *node.KvClient) (*node.KeyValue, error) {
+		return n.GetFirst(ctx, streamId, startIndex, length, version...)
+	})
 }
 
 // GetLast returns paginated key-value of the first key of the specified stream.
 func (c *Client) GetLast(ctx context.Context, streamId common.Hash, startIndex, length uint64, version ...uint64) (val *node.KeyValue, err error) {
-	return c.node.GetLast(ctx, streamId, startIndex, length, version...)
+	return call(ctx, c, func(n *node.KvClient) (*node.KeyValue, error) {
+		return n.GetLast(ctx, streamId, startIndex, length, version...)
+	})
 }
 
 // GetTransactionResult query the kv replay status of a given data by sequence id.
 func (c *Client) GetTransactionResult(ctx context.Context, txSeq uint64) (result string, err error) {
-	return c.node.GetTransactionResult(ctx, txSeq)
+	return call(ctx, c, func(n *node.KvClient) (string, error) {
+		return n.GetTransactionResult(ctx, txSeq)
+	})
 }
 
 // GetHoldingStreamIds query the stream ids monitered by the kv node.
 func (c *Client) GetHoldingStreamIds(ctx context.Context) (streamIds []common.Hash, err error) {
-	return c.node.GetHoldingStreamIds(ctx)
+	return call(ctx, c, func(n *node.KvClient) ([]common.Hash, error) {
+		return n.GetHoldingStreamIds(ctx)
+	})
 }
 
 // HasWritePermission check if the account is able to write the stream.
 func (c *Client) HasWritePermission(ctx context.Context, account common.Address, streamId common.Hash, key []byte, version ...uint64) (hasPermission bool, err error) {
-	return c.node.HasWritePermission(ctx, account, streamId, key, version...)
+	return call(ctx, c, func(n *node.KvClient) (bool, error) {
+		return n.HasWritePermission(ctx, account, streamId, key, version...)
+	})
 }
 
 // IsAdmin check if the account is the admin of the stream.
 func (c *Client) IsAdmin(ctx context.Context, account common.Address, streamId common.Hash, version ...uint64) (isAdmin bool, err error) {
-	return c.node.IsAdmin(ctx, account, streamId, version...)
+	return call(ctx, c, func(n *node.KvClient) (bool, error) {
+		return n.IsAdmin(ctx, account, streamId, version...)
+	})
 }
 
 // IsSpecialKey check if the key has unique access control.
 func (c *Client) IsSpecialKey(ctx context.Context, streamId common.Hash, key []byte, version ...uint64) (isSpecialKey bool, err error) {
-	return c.node.IsSpecialKey(ctx, streamId, key, version...)
+	return call(ctx, c, func(n *node.KvClient) (bool, error) {
+		return n.IsSpecialKey(ctx, streamId, key, version...)
+	})
 }
 
 // IsWriterOfKey check if the account can write the special key.
 func (c *Client) IsWriterOfKey(ctx context.Context, account common.Address, streamId common.Hash, key []byte, version ...uint64) (isWriter bool, err error) {
-	return c.node.IsWriterOfKey(ctx, account, streamId, key, version...)
+	return call(ctx, c, func(n *node.KvClient) (bool, error) {
+		return n.IsWriterOfKey(ctx, account, streamId, key, version...)
+	})
 }
 
 // IsWriterOfStream check if the account is the writer of the stream.
 func (c *Client) IsWriterOfStream(ctx context.Context, account common.Address, streamId common.Hash, version ...uint64) (isWriter bool, err error) {
-	return c.node.IsWriterOfStream(ctx, account, streamId, version...)
+	return call(ctx, c, func(n *node.KvClient) (bool, error) {
+		return n.IsWriterOfStream(ctx, account, streamId, version...)
+	})
 }
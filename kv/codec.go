@@ -0,0 +1,149 @@
+package kv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// Codec encodes typed keys or values into bytes to store in kv stream, and decodes them back.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+var (
+	_ Codec[[]byte] = BytesCodec{}
+	_ Codec[string] = StringCodec{}
+	_ Codec[uint64] = Uint64Codec{}
+	_ Codec[any]    = JSONCodec[any]{}
+	_ Codec[any]    = RLPCodec[any]{}
+	_ Codec[any]    = (*GzipCodec[any])(nil)
+)
+
+// BytesCodec stores raw bytes as it is.
+type BytesCodec struct{}
+
+func (BytesCodec) Encode(v []byte) ([]byte, error) {
+	return v, nil
+}
+
+func (BytesCodec) Decode(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// StringCodec stores string in raw bytes.
+type StringCodec struct{}
+
+func (StringCodec) Encode(v string) ([]byte, error) {
+	return []byte(v), nil
+}
+
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// Uint64Codec stores uint64 in 8 bytes of big endian, which keeps the order of numbers when used as keys.
+type Uint64Codec struct{}
+
+func (Uint64Codec) Encode(v uint64) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, v), nil
+}
+
+func (Uint64Codec) Decode(data []byte) (uint64, error) {
+	if len(data) != 8 {
+		return 0, errors.Errorf("invalid uint64 data length %v", len(data))
+	}
+
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// JSONCodec stores values in JSON format.
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// RLPCodec stores values in compact binary format of RLP, which requires T to be RLP serializable,
+// e.g. structs of integers, strings, bytes, slices and big integers.
+type RLPCodec[T any] struct{}
+
+func (RLPCodec[T]) Encode(v T) ([]byte, error) {
+	return rlp.EncodeToBytes(v)
+}
+
+func (RLPCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := rlp.DecodeBytes(data, &v)
+	return v, err
+}
+
+// GzipCodec compresses the data encoded by the underlying codec, which is suitable for large values.
+type GzipCodec[T any] struct {
+	codec Codec[T]
+	level int
+}
+
+// NewGzipCodec creates a codec to transparently compress data encoded by the given codec.
+// Compression level is gzip.DefaultCompression by default.
+func NewGzipCodec[T any](codec Codec[T], level ...int) *GzipCodec[T] {
+	l := gzip.DefaultCompression
+	if len(level) > 0 {
+		l = level[0]
+	}
+
+	return &GzipCodec[T]{codec, l}
+}
+
+func (c *GzipCodec[T]) Encode(v T) ([]byte, error) {
+	data, err := c.codec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return nil, errors.WithMessage(err, "failed to compress data")
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, errors.WithMessage(err, "failed to compress data")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *GzipCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return v, errors.WithMessage(err, "failed to decompress data")
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return v, errors.WithMessage(err, "failed to decompress data")
+	}
+
+	return c.codec.Decode(decompressed)
+}
@@ -0,0 +1,70 @@
+package kv
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	Name    string
+	Balance *big.Int
+	Tags    []string
+}
+
+func testCodec[T any](t *testing.T, codec Codec[T], v T) []byte {
+	encoded, err := codec.Encode(v)
+	assert.NoError(t, err)
+
+	decoded, err := codec.Decode(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, v, decoded)
+
+	return encoded
+}
+
+func TestCodecs(t *testing.T) {
+	testCodec[[]byte](t, BytesCodec{}, []byte{1, 2, 3})
+	testCodec[string](t, StringCodec{}, "hello")
+
+	record := testRecord{"alice", big.NewInt(100), []string{"a", "b"}}
+	testCodec[testRecord](t, JSONCodec[testRecord]{}, record)
+	testCodec[testRecord](t, RLPCodec[testRecord]{}, record)
+
+	// big endian keeps the order of numbers
+	encoded1 := testCodec[uint64](t, Uint64Codec{}, 255)
+	encoded2 := testCodec[uint64](t, Uint64Codec{}, 256)
+	assert.Equal(t, -1, bytes.Compare(encoded1, encoded2))
+
+	_, err := Uint64Codec{}.Decode([]byte{1})
+	assert.Error(t, err)
+
+	// compression
+	large := string(bytes.Repeat([]byte("0g storage "), 1000))
+	compressed := testCodec[string](t, NewGzipCodec[string](StringCodec{}), large)
+	assert.Less(t, len(compressed), len(large))
+}
+
+func TestMapPut(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+	m := NewMap[uint64, testRecord](nil, streamId, Uint64Codec{}, JSONCodec[testRecord]{})
+	assert.Equal(t, streamId, m.StreamId())
+
+	batcher := NewBatcher(0, nil, nil)
+	assert.NoError(t, m.Put(batcher, 1, testRecord{Name: "alice"}))
+	assert.NoError(t, m.Delete(batcher, 2))
+
+	data, err := batcher.Build(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(data.Writes))
+
+	assert.Equal(t, streamId, data.Writes[0].StreamId)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, data.Writes[0].Key)
+	assert.Equal(t, `{"Name":"alice","Balance":null,"Tags":null}`, string(data.Writes[0].Data))
+
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 2}, data.Writes[1].Key)
+	assert.Empty(t, data.Writes[1].Data)
+}
@@ -0,0 +1,222 @@
+package kv
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// streamDataDecoder deserializes stream data sequentially.
+type streamDataDecoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *streamDataDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.offset < n {
+		return nil, errors.Errorf("unexpected end of data, offset = %v, required = %v, total = %v", d.offset, n, len(d.data))
+	}
+
+	start := d.offset
+	d.offset += n
+
+	return d.data[start:d.offset:d.offset], nil
+}
+
+func (d *streamDataDecoder) size24() (int, error) {
+	buf, err := d.next(3)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(buf[0])<<16 | int(buf[1])<<8 | int(buf[2]), nil
+}
+
+func (d *streamDataDecoder) size32() (int, error) {
+	buf, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(binary.BigEndian.Uint32(buf)), nil
+}
+
+// count decodes the number of items, and checks it against the remaining data, so that a corrupted count
+// will not allocate too much memory.
+func (d *streamDataDecoder) count(minItemSize int) (int, error) {
+	n, err := d.size32()
+	if err != nil {
+		return 0, err
+	}
+
+	if remaining := len(d.data) - d.offset; n > remaining/minItemSize {
+		return 0, errors.Errorf("count too large for remaining data, count = %v, remaining = %v", n, remaining)
+	}
+
+	return n, nil
+}
+
+func (d *streamDataDecoder) uint64() (uint64, error) {
+	buf, err := d.next(8)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+func (d *streamDataDecoder) streamId() (common.Hash, error) {
+	buf, err := d.next(common.HashLength)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return common.BytesToHash(buf), nil
+}
+
+func (d *streamDataDecoder) key() ([]byte, error) {
+	size, err := d.size24()
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return nil, errKeyIsEmpty
+	}
+
+	return d.next(size)
+}
+
+func (d *streamDataDecoder) account() (*common.Address, error) {
+	buf, err := d.next(common.AddressLength)
+	if err != nil {
+		return nil, err
+	}
+
+	account := common.BytesToAddress(buf)
+
+	return &account, nil
+}
+
+// DecodeStreamData deserializes the stream data encoded by StreamData.Encode. Note, the decoded keys and
+// values refer to the given data.
+func DecodeStreamData(data []byte) (*StreamData, error) {
+	d := streamDataDecoder{data: data}
+	var sd StreamData
+	var err error
+
+	// version
+	if sd.Version, err = d.uint64(); err != nil {
+		return nil, errors.WithMessage(err, "failed to decode version")
+	}
+
+	// reads
+	// stream id and key size at least
+	numReads, err := d.count(common.HashLength + 3)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decode number of reads")
+	}
+
+	for i := 0; i < numReads; i++ {
+		var read streamRead
+
+		if read.StreamId, err = d.streamId(); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode stream id of read %v", i)
+		}
+
+		if read.Key, err = d.key(); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode key of read %v", i)
+		}
+
+		sd.Reads = append(sd.Reads, read)
+	}
+
+	// writes, in which values follow the metadata of all writes
+	// stream id, key size and value size at least
+	numWrites, err := d.count(common.HashLength + 3 + 8)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decode number of writes")
+	}
+
+	sizes := make([]uint64, numWrites)
+	for i := 0; i < numWrites; i++ {
+		var write streamWrite
+
+		if write.StreamId, err = d.streamId(); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode stream id of write %v", i)
+		}
+
+		if write.Key, err = d.key(); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode key of write %v", i)
+		}
+
+		if sizes[i], err = d.uint64(); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode value size of write %v", i)
+		}
+
+		sd.Writes = append(sd.Writes, write)
+	}
+
+	for i := range sd.Writes {
+		if sizes[i] > uint64(len(data)) {
+			return nil, errors.Errorf("value size of write %v too large, size = %v", i, sizes[i])
+		}
+
+		if sd.Writes[i].Data, err = d.next(int(sizes[i])); err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode value of write %v", i)
+		}
+	}
+
+	// access controls
+	// type and stream id at least
+	numControls, err := d.count(1 + common.HashLength)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decode number of access controls")
+	}
+
+	for i := 0; i < numControls; i++ {
+		control, err := d.accessControl()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to decode access control %v", i)
+		}
+
+		sd.Controls = append(sd.Controls, control)
+	}
+
+	if d.offset != len(data) {
+		return nil, errors.Errorf("unexpected trailing data, offset = %v, total = %v", d.offset, len(data))
+	}
+
+	return &sd, nil
+}
+
+func (d *streamDataDecoder) accessControl() (control accessControl, err error) {
+	buf, err := d.next(1)
+	if err != nil {
+		return control, err
+	}
+
+	control.Type = accessControlType(buf[0])
+	if !control.Type.valid() {
+		return control, errors.Errorf("unknown access control type %v", buf[0])
+	}
+
+	if control.StreamId, err = d.streamId(); err != nil {
+		return control, err
+	}
+
+	if control.Type.hasKey() {
+		if control.Key, err = d.key(); err != nil {
+			return control, err
+		}
+	}
+
+	if control.Type.hasAccount() {
+		if control.Account, err = d.account(); err != nil {
+			return control, err
+		}
+	}
+
+	return control, nil
+}
@@ -0,0 +1,283 @@
+package kv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+	ErrInvalidEncryptedValue = errors.New("invalid encrypted value")
+	ErrEncryptedStream       = errors.New("not supported for encrypted stream")
+)
+
+// formats of encrypted values
+const (
+	encryptedFormatValue       byte = 0x01 // only value encrypted
+	encryptedFormatKeyAndValue byte = 0x02 // original key encrypted along with value, when keys hashed
+)
+
+// encryptedHeaderSize is the size of format and key id, which are stored in plain text before the nonce.
+const encryptedHeaderSize = 1 + 4
+
+// streamKeys is the encryption keys of a stream, which is immutable once added into keyring, so that it could
+// be used without lock. Any change is made on a copy, which then replaces the original one in keyring.
+type streamKeys struct {
+	current uint32
+	aeads   map[uint32]cipher.AEAD
+	hashKey []byte // secret to hash keys, nil if keys are stored in plain text
+}
+
+// Keyring holds per-stream encryption keys, so that values are encrypted client-side with AES-GCM before
+// written into kv stream, and decrypted transparently when read by Client.GetValue, Iterator and Scan.
+//
+// Each encrypted value is stored along with the id of the key used to encrypt it, so that keys could be
+// rotated: new values are encrypted with the current key, while old values could still be decrypted as long
+// as their keys are added into keyring.
+//
+// Optionally, keys are stored as HMAC-SHA256 of the original keys, with the original keys encrypted along
+// with values. In this case, the key-values are iterated in order of the hashed keys instead of the original
+// keys, so that range bounds of Scan and seeking of Iterator do not make sense.
+//
+// Note, empty values are not encrypted, so that they are still regarded as deleted, e.g. by Map.
+type Keyring struct {
+	mu      sync.RWMutex
+	streams map[common.Hash]*streamKeys
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{
+		streams: make(map[common.Hash]*streamKeys),
+	}
+}
+
+func (k *Keyring) stream(streamId common.Hash) *streamKeys {
+	if k == nil {
+		return nil
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.streams[streamId]
+}
+
+// AddKey adds an AES key of 16, 24 or 32 bytes for the specified stream to decrypt values, which becomes the
+// current key to encrypt values if it is the first key of stream.
+func (k *Keyring) AddKey(streamId common.Hash, keyId uint32, key []byte) error {
+	return k.addKey(streamId, keyId, key, false)
+}
+
+// Rotate adds an AES key for the specified stream, and uses it to encrypt values afterwards. The previous keys
+// are still used to decrypt old values.
+func (k *Keyring) Rotate(streamId common.Hash, keyId uint32, key []byte) error {
+	return k.addKey(streamId, keyId, key, true)
+}
+
+func (k *Keyring) addKey(streamId common.Hash, keyId uint32, key []byte, current bool) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return errors.WithMessage(err, "invalid encryption key")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return errors.WithMessage(err, "failed to create AES-GCM cipher")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys := k.streams[streamId].clone()
+
+	if _, ok := keys.aeads[keyId]; ok {
+		return errors.Errorf("encryption key %v already exists", keyId)
+	}
+
+	if len(keys.aeads) == 0 || current {
+		keys.current = keyId
+	}
+
+	keys.aeads[keyId] = aead
+	k.streams[streamId] = keys
+
+	return nil
+}
+
+// clone returns a copy of stream keys to update, or empty keys if nil.
+func (keys *streamKeys) clone() *streamKeys {
+	result := streamKeys{aeads: make(map[uint32]cipher.AEAD)}
+
+	if keys != nil {
+		result.current = keys.current
+		result.hashKey = keys.hashKey
+
+		for id, aead := range keys.aeads {
+			result.aeads[id] = aead
+		}
+	}
+
+	return &result
+}
+
+// SetKeyHashing enables to store keys of the specified stream as HMAC-SHA256 of the original keys with the
+// given secret. Note, the secret should not be changed once any key written, otherwise, the written keys
+// could not be found anymore.
+func (k *Keyring) SetKeyHashing(streamId common.Hash, secret []byte) error {
+	if len(secret) == 0 {
+		return errors.New("empty key hashing secret")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys, ok := k.streams[streamId]
+	if !ok {
+		return errors.WithMessagef(ErrEncryptionKeyNotFound, "stream %v", streamId)
+	}
+
+	keys = keys.clone()
+	keys.hashKey = bytes.Clone(secret)
+	k.streams[streamId] = keys
+
+	return nil
+}
+
+// Encrypted returns whether values of the specified stream are encrypted.
+func (k *Keyring) Encrypted(streamId common.Hash) bool {
+	return k.stream(streamId) != nil
+}
+
+// keysHashed returns whether keys of the specified stream are hashed.
+func (k *Keyring) keysHashed(streamId common.Hash) bool {
+	keys := k.stream(streamId)
+	return keys != nil && keys.hashKey != nil
+}
+
+// storedKey returns the key stored in kv stream, which is hashed if key hashing enabled.
+func (k *Keyring) storedKey(streamId common.Hash, key []byte) []byte {
+	keys := k.stream(streamId)
+	if keys == nil || keys.hashKey == nil {
+		return key
+	}
+
+	mac := hmac.New(sha256.New, keys.hashKey)
+	mac.Write(key)
+
+	return mac.Sum(nil)
+}
+
+// encrypt encrypts value with the current key of stream, and the stored key is authenticated along with it.
+func (k *Keyring) encrypt(streamId common.Hash, key, storedKey, value []byte) ([]byte, error) {
+	keys := k.stream(streamId)
+	if keys == nil || len(value) == 0 {
+		return value, nil
+	}
+
+	aead := keys.aeads[keys.current]
+
+	plaintext := value
+	header := []byte{encryptedFormatValue}
+	if keys.hashKey != nil {
+		header[0] = encryptedFormatKeyAndValue
+		plaintext = binary.BigEndian.AppendUint32(nil, uint32(len(key)))
+		plaintext = append(append(plaintext, key...), value...)
+	}
+	header = binary.BigEndian.AppendUint32(header, keys.current)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.WithMessage(err, "failed to generate nonce")
+	}
+
+	encrypted := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	encrypted = append(append(encrypted, header...), nonce...)
+
+	return aead.Seal(encrypted, nonce, plaintext, additionalData(streamId, storedKey, header)), nil
+}
+
+// decrypt decrypts the stored value, and returns the original key and value.
+func (k *Keyring) decrypt(streamId common.Hash, storedKey, encrypted []byte) (key, value []byte, err error) {
+	keys := k.stream(streamId)
+	if keys == nil || len(encrypted) == 0 {
+		return storedKey, encrypted, nil
+	}
+
+	if len(encrypted) < encryptedHeaderSize {
+		return nil, nil, errors.WithMessagef(ErrInvalidEncryptedValue, "key %x, size = %v", storedKey, len(encrypted))
+	}
+
+	header := encrypted[:encryptedHeaderSize]
+	keyId := binary.BigEndian.Uint32(header[1:])
+
+	aead, ok := keys.aeads[keyId]
+	if !ok {
+		return nil, nil, errors.WithMessagef(ErrEncryptionKeyNotFound, "key id %v of stream %v", keyId, streamId)
+	}
+
+	if len(encrypted) < encryptedHeaderSize+aead.NonceSize()+aead.Overhead() {
+		return nil, nil, errors.WithMessagef(ErrInvalidEncryptedValue, "key %x, size = %v", storedKey, len(encrypted))
+	}
+
+	nonce := encrypted[encryptedHeaderSize : encryptedHeaderSize+aead.NonceSize()]
+	ciphertext := encrypted[encryptedHeaderSize+aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(streamId, storedKey, header))
+	if err != nil {
+		return nil, nil, errors.WithMessagef(ErrInvalidEncryptedValue, "failed to decrypt value of key %x: %v", storedKey, err)
+	}
+
+	switch header[0] {
+	case encryptedFormatValue:
+		return storedKey, plaintext, nil
+	case encryptedFormatKeyAndValue:
+		if len(plaintext) < 4 || uint64(len(plaintext)-4) < uint64(binary.BigEndian.Uint32(plaintext)) {
+			return nil, nil, errors.WithMessagef(ErrInvalidEncryptedValue, "invalid key size of key %x", storedKey)
+		}
+
+		keySize := int(binary.BigEndian.Uint32(plaintext))
+
+		return plaintext[4 : 4+keySize], plaintext[4+keySize:], nil
+	default:
+		return nil, nil, errors.WithMessagef(ErrInvalidEncryptedValue, "unknown format %v of key %x", header[0], storedKey)
+	}
+}
+
+// additionalData binds the encrypted value to its location, so that it could not be moved to another key.
+func additionalData(streamId common.Hash, storedKey, header []byte) []byte {
+	data := make([]byte, 0, common.HashLength+len(storedKey)+len(header))
+	data = append(data, streamId.Bytes()...)
+	data = append(data, storedKey...)
+
+	return append(data, header...)
+}
+
+// decryptPair decrypts the stored key-value, whose data should be complete.
+func (k *Keyring) decryptPair(streamId common.Hash, pair *node.KeyValue) (*node.KeyValue, error) {
+	if pair == nil || !k.Encrypted(streamId) {
+		return pair, nil
+	}
+
+	key, value, err := k.decrypt(streamId, pair.Key, pair.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &node.KeyValue{
+		Version: pair.Version,
+		Key:     key,
+		Data:    value,
+		Size:    uint64(len(value)),
+	}, nil
+}
@@ -0,0 +1,123 @@
+package kv
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyringEncryption(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+	key1 := bytes.Repeat([]byte{1}, 32)
+	key2 := bytes.Repeat([]byte{2}, 16)
+
+	keyring := NewKeyring()
+	assert.NoError(t, keyring.AddKey(streamId, 1, key1))
+	assert.Error(t, keyring.AddKey(streamId, 1, key2))
+	assert.Error(t, keyring.AddKey(streamId, 2, []byte("short")))
+	assert.False(t, keyring.Encrypted(common.HexToHash("0x02")))
+
+	builder := newStreamDataBuilder(math.MaxUint64).SetKeyring(keyring)
+	builder.Set(streamId, []byte("a"), []byte("hello"))
+	builder.Set(streamId, []byte("b"), []byte{})
+	builder.Set(common.HexToHash("0x02"), []byte("c"), []byte("plain"))
+
+	data, err := builder.Build(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(data.Writes))
+
+	// values encrypted with key id header
+	encrypted := data.Writes[0]
+	assert.Equal(t, []byte("a"), encrypted.Key)
+	assert.NotEqual(t, []byte("hello"), encrypted.Data)
+	assert.Equal(t, []byte{encryptedFormatValue, 0, 0, 0, 1}, encrypted.Data[:encryptedHeaderSize])
+
+	// empty value and other streams not encrypted
+	assert.Equal(t, []byte{}, data.Writes[1].Data)
+	assert.Equal(t, []byte("plain"), data.Writes[2].Data)
+
+	// old values could be decrypted after key rotated
+	assert.NoError(t, keyring.Rotate(streamId, 2, key2))
+
+	key, value, err := keyring.decrypt(streamId, encrypted.Key, encrypted.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), key)
+	assert.Equal(t, []byte("hello"), value)
+
+	data, err = builder.Build(true)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{encryptedFormatValue, 0, 0, 0, 2}, data.Writes[0].Data[:encryptedHeaderSize])
+
+	// value could not be moved to another key
+	_, _, err = keyring.decrypt(streamId, []byte("b"), encrypted.Data)
+	assert.True(t, errors.Is(err, ErrInvalidEncryptedValue))
+
+	// unknown key id
+	other := NewKeyring()
+	assert.NoError(t, other.AddKey(streamId, 2, key2))
+	_, _, err = other.decrypt(streamId, encrypted.Key, encrypted.Data)
+	assert.True(t, errors.Is(err, ErrEncryptionKeyNotFound))
+}
+
+func TestKeyringKeyHashing(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+
+	keyring := NewKeyring()
+	assert.Error(t, keyring.SetKeyHashing(streamId, []byte("secret")))
+	assert.NoError(t, keyring.AddKey(streamId, 1, bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, keyring.SetKeyHashing(streamId, []byte("secret")))
+
+	builder := newStreamDataBuilder(math.MaxUint64).SetKeyring(keyring)
+	builder.Watch(streamId, []byte("a"))
+	builder.Set(streamId, []byte("a"), []byte("hello"))
+	builder.SetKeyToSpecial(streamId, []byte("a"))
+
+	data, err := builder.Build()
+	assert.NoError(t, err)
+
+	// keys hashed deterministically
+	storedKey := keyring.storedKey(streamId, []byte("a"))
+	assert.Equal(t, 32, len(storedKey))
+	assert.Equal(t, storedKey, data.Reads[0].Key)
+	assert.Equal(t, storedKey, data.Writes[0].Key)
+	assert.Equal(t, storedKey, data.Controls[0].Key)
+	assert.Equal(t, []byte("a"), builder.controls[0].Key)
+
+	// original key recovered
+	key, value, err := keyring.decrypt(streamId, storedKey, data.Writes[0].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), key)
+	assert.Equal(t, []byte("hello"), value)
+}
+
+func TestKeyringConcurrentRotate(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+
+	keyring := NewKeyring()
+	assert.NoError(t, keyring.AddKey(streamId, 0, bytes.Repeat([]byte{0}, 32)))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint32(1); i <= 100; i++ {
+			assert.NoError(t, keyring.Rotate(streamId, i, bytes.Repeat([]byte{byte(i)}, 32)))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		encrypted, err := keyring.encrypt(streamId, []byte("a"), []byte("a"), []byte("hello"))
+		assert.NoError(t, err)
+
+		_, value, err := keyring.decrypt(streamId, []byte("a"), encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hello"), value)
+	}
+
+	wg.Wait()
+}
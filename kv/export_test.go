@@ -0,0 +1,62 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NewTestTx creates a transaction without uploader, so that it could be validated against a fake kv node.
+func NewTestTx(client *Client) *Tx {
+	return newTx(client, NewBatcher(0, nil, nil))
+}
+
+// Prepare exports Tx.prepare for tests.
+func (tx *Tx) Prepare(ctx context.Context) (uint64, error) {
+	return tx.prepare(ctx)
+}
+
+// NextPendingTxSeq exports Client.nextPendingTxSeq for tests.
+func (c *Client) NextPendingTxSeq(ctx context.Context, from uint64) (uint64, error) {
+	return c.nextPendingTxSeq(ctx, from)
+}
+
+// ExecWith exports the execution of Batcher.ExecWithOption for tests, in which the encoded parts are submitted
+// by the given function, e.g. applied to a fake kv node, instead of uploader.
+func (b *Batcher) ExecWith(ctx context.Context, option ExecOption, submit func(encoded []byte) uint64) (*ExecResult, error) {
+	parts, err := b.splitParts(option)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.execParts(ctx, parts, option, func(i int, part *StreamData) (common.Hash, uint64, error) {
+		encoded, err := part.Encode()
+		if err != nil {
+			return common.Hash{}, 0, err
+		}
+
+		return common.Hash{}, submit(encoded), nil
+	})
+}
+
+// TxnWith exports Txn for tests, in which the encoded transaction is submitted by the given function, e.g.
+// applied to a fake kv node, instead of uploader.
+func TxnWith(ctx context.Context, client *Client, fn func(tx *Tx) error, opt TxnOption, submit func(encoded []byte) uint64) (*TxResult, error) {
+	newBatcher := func() *Batcher {
+		return NewBatcher(0, nil, nil)
+	}
+
+	return txn(ctx, client, fn, opt, newBatcher, func(ctx context.Context, batcher *Batcher) (uint64, error) {
+		data, err := batcher.Build()
+		if err != nil {
+			return 0, err
+		}
+
+		encoded, err := data.Encode()
+		if err != nil {
+			return 0, err
+		}
+
+		return submit(encoded), nil
+	})
+}
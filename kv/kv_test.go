@@ -144,3 +144,146 @@ func TestKVBuilder(t *testing.T) {
 	assert.Equal(t, true, bytes.Equal(expectedData, encoded), "chunk data not match")
 	assert.Equal(t, true, bytes.Equal(expectedTags, tags), "tags not match")
 }
+
+func TestKVBuilderSetFromReader(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+	value := bytes.Repeat([]byte("0g"), 100000)
+
+	expected := newStreamDataBuilder(1)
+	expected.Set(streamId, []byte("key"), value)
+	expected.GrantWriteRole(streamId, common.HexToAddress("0x02"))
+	expectedData, err := expected.Build()
+	assert.NoError(t, err)
+	expectedEncoded, err := expectedData.Encode()
+	assert.NoError(t, err)
+
+	actual := newStreamDataBuilder(1)
+	actual.SetFromReader(streamId, []byte("key"), bytes.NewReader(value), int64(len(value)))
+	actual.GrantWriteRole(streamId, common.HexToAddress("0x02"))
+	actualData, err := actual.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData.Size(), actualData.Size())
+
+	actualEncoded, err := actualData.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEncoded, actualEncoded)
+
+	// values are read on demand
+	iterable, err := actualData.encodeData()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(expectedEncoded)), iterable.Size())
+
+	buf := make([]byte, len(expectedEncoded))
+	_, err = iterable.Read(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEncoded, buf)
+}
+
+func TestDecodeStreamData(t *testing.T) {
+	builder := newStreamDataBuilder(10)
+	hashCount = big.NewInt(0)
+	addressCount = big.NewInt(10000000)
+	keyCount = 0
+
+	for i := 0; i < 3; i++ {
+		builder.Watch(nextHash(), nextKey())
+	}
+	for i := 0; i < 3; i++ {
+		builder.Set(nextHash(), nextKey(), bytes.Repeat([]byte{byte(65 + i)}, i))
+	}
+
+	// acl with and without account/key
+	builder.GrantAdminRole(nextHash(), nextAddress())
+	builder.RenounceAdminRole(nextHash())
+	builder.SetKeyToNormal(nextHash(), nextKey())
+	builder.SetKeyToSpecial(nextHash(), nextKey())
+	builder.GrantWriteRole(nextHash(), nextAddress())
+	builder.RevokeWriteRole(nextHash(), nextAddress())
+	builder.RenounceWriteRole(nextHash())
+	builder.GrantSpecialWriteRole(nextHash(), nextKey(), nextAddress())
+	builder.RevokeSpecialWriteRole(nextHash(), nextKey(), nextAddress())
+	builder.RenounceSpecialWriteRole(nextHash(), nextKey())
+
+	data, err := builder.Build(true)
+	assert.NoError(t, err)
+	encoded, err := data.Encode()
+	assert.NoError(t, err)
+
+	decoded, err := DecodeStreamData(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	reencoded, err := decoded.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, encoded, reencoded)
+
+	// truncated or trailing data
+	_, err = DecodeStreamData(encoded[:len(encoded)-1])
+	assert.Error(t, err)
+	_, err = DecodeStreamData(append(encoded, 0))
+	assert.Error(t, err)
+
+	// huge counts rejected before allocation
+	version := encoded[:8]
+	huge := []byte{0xff, 0xff, 0xff, 0xff}
+	empty := []byte{0, 0, 0, 0}
+	for _, corrupted := range [][]byte{
+		bytes.Join([][]byte{version, huge}, nil),
+		bytes.Join([][]byte{version, empty, huge, make([]byte, 1024)}, nil),
+		bytes.Join([][]byte{version, empty, empty, huge}, nil),
+	} {
+		_, err = DecodeStreamData(corrupted)
+		assert.ErrorContains(t, err, "count too large")
+	}
+
+	// the count of writes exceeds the remaining data by one
+	writes := bytes.Join([][]byte{version, empty, {0, 0, 0, 2}, make([]byte, 2*43-1)}, nil)
+	_, err = DecodeStreamData(writes)
+	assert.ErrorContains(t, err, "count too large")
+}
+
+func TestStreamDataSplit(t *testing.T) {
+	builder := newStreamDataBuilder(10)
+	streamId := common.HexToHash("0x01")
+
+	builder.Watch(streamId, []byte("read"))
+	for i := 0; i < 5; i++ {
+		builder.Set(streamId, []byte{byte(i + 1)}, []byte{byte(i)})
+	}
+	for i := 0; i < 3; i++ {
+		builder.GrantWriteRole(common.HexToHash("0x02"), common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+
+	data, err := builder.collect(true)
+	assert.NoError(t, err)
+
+	parts, err := data.split(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(parts))
+
+	// reads only in the first part
+	assert.Equal(t, data.Reads, parts[0].Reads)
+	assert.Empty(t, parts[1].Reads)
+	assert.Empty(t, parts[2].Reads)
+
+	// writes and access controls in order
+	var writes []streamWrite
+	var controls []accessControl
+	for _, part := range parts {
+		assert.Equal(t, data.Version, part.Version)
+		assert.LessOrEqual(t, len(part.Writes), 2)
+		assert.LessOrEqual(t, len(part.Controls), 2)
+		writes = append(writes, part.Writes...)
+		controls = append(controls, part.Controls...)
+	}
+	assert.Equal(t, data.Writes, writes)
+	assert.Equal(t, data.Controls, controls)
+
+	// tags of stream ids in each part
+	assert.Equal(t, []common.Hash{streamId, common.HexToHash("0x02")}, parts[0].streamIds())
+	assert.Equal(t, []common.Hash{streamId}, parts[2].streamIds())
+
+	// read set could not be split
+	_, err = data.split(0)
+	assert.Equal(t, ErrReadSetTooLarge, err)
+}
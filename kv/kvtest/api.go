@@ -0,0 +1,142 @@
+package kvtest
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KvApi implements the kv_* RPC methods of kv node, in which the optional version defaults to the latest.
+type KvApi struct {
+	store *Store
+}
+
+// NewKvApi creates the RPC api of the given store.
+func NewKvApi(store *Store) *KvApi {
+	return &KvApi{store}
+}
+
+func versionOf(version *uint64) uint64 {
+	if version == nil {
+		return math.MaxUint64
+	}
+
+	return *version
+}
+
+func (api *KvApi) GetValue(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, version *uint64) (*node.Value, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	kv := api.store.value(streamId, key, startIndex, length, versionOf(version))
+	if kv == nil {
+		return nil, nil
+	}
+
+	return &node.Value{Version: kv.Version, Data: kv.Data, Size: kv.Size}, nil
+}
+
+func (api *KvApi) GetNext(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, inclusive bool, version *uint64) (*node.KeyValue, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	return api.store.seek(streamId, key, startIndex, length, inclusive, true, versionOf(version)), nil
+}
+
+func (api *KvApi) GetPrev(ctx context.Context, streamId common.Hash, key []byte, startIndex, length uint64, inclusive bool, version *uint64) (*node.KeyValue, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	return api.store.seek(streamId, key, startIndex, length, inclusive, false, versionOf(version)), nil
+}
+
+func (api *KvApi) GetFirst(ctx context.Context, streamId common.Hash, startIndex, length uint64, version *uint64) (*node.KeyValue, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	return api.store.edge(streamId, startIndex, length, true, versionOf(version)), nil
+}
+
+func (api *KvApi) GetLast(ctx context.Context, streamId common.Hash, startIndex, length uint64, version *uint64) (*node.KeyValue, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	return api.store.edge(streamId, startIndex, length, false, versionOf(version)), nil
+}
+
+// GetTransactionResult returns the replay result of tx, or empty string if not replayed yet.
+func (api *KvApi) GetTransactionResult(ctx context.Context, txSeq uint64) (string, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	if txSeq >= uint64(len(api.store.results)) {
+		return "", nil
+	}
+
+	return api.store.results[txSeq], nil
+}
+
+// GetHoldingStreamIds returns the sorted ids of all streams written.
+func (api *KvApi) GetHoldingStreamIds(ctx context.Context) ([]common.Hash, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	streamIds := make([]common.Hash, 0, len(api.store.streams))
+	for streamId := range api.store.streams {
+		streamIds = append(streamIds, streamId)
+	}
+
+	sort.Slice(streamIds, func(i, j int) bool {
+		return streamIds[i].Hex() < streamIds[j].Hex()
+	})
+
+	return streamIds, nil
+}
+
+func (api *KvApi) HasWritePermission(ctx context.Context, account common.Address, streamId common.Hash, key []byte, version *uint64) (bool, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	st := api.store.stream(streamId)
+
+	return st != nil && st.hasWritePermission(account, key, versionOf(version)), nil
+}
+
+func (api *KvApi) IsAdmin(ctx context.Context, account common.Address, streamId common.Hash, version *uint64) (bool, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	st := api.store.stream(streamId)
+
+	return st != nil && st.isAdmin(account, versionOf(version)), nil
+}
+
+func (api *KvApi) IsSpecialKey(ctx context.Context, streamId common.Hash, key []byte, version *uint64) (bool, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	st := api.store.stream(streamId)
+
+	return st != nil && st.isSpecialKey(key, versionOf(version)), nil
+}
+
+func (api *KvApi) IsWriterOfKey(ctx context.Context, account common.Address, streamId common.Hash, key []byte, version *uint64) (bool, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	st := api.store.stream(streamId)
+
+	return st != nil && st.isWriterOfKey(account, key, versionOf(version)), nil
+}
+
+func (api *KvApi) IsWriterOfStream(ctx context.Context, account common.Address, streamId common.Hash, version *uint64) (bool, error) {
+	api.store.mu.RLock()
+	defer api.store.mu.RUnlock()
+
+	st := api.store.stream(streamId)
+
+	return st != nil && st.isWriterOfStream(account, versionOf(version)), nil
+}
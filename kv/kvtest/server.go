@@ -0,0 +1,50 @@
+package kvtest
+
+import (
+	"net/http/httptest"
+
+	"github.com/0glabs/0g-storage-client/common/rpc"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/sirupsen/logrus"
+)
+
+// Server is an in-process fake kv node, which serves the kv_* RPC methods on a local HTTP server. Use the
+// embedded Store to apply transactions, e.g. the encoded data of kv.Batcher.
+type Server struct {
+	*Store
+	server *httptest.Server
+}
+
+// NewServer starts a fake kv node with an empty store. The server should be closed after use.
+func NewServer() *Server {
+	store := NewStore()
+
+	handler := rpc.MustNewHandler(map[string]interface{}{
+		"kv": NewKvApi(store),
+	})
+
+	return &Server{
+		Store:  store,
+		server: httptest.NewServer(handler),
+	}
+}
+
+// URL returns the RPC endpoint of kv node.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// MustNewClient creates a kv client connected to the server, and panic on failure.
+func (s *Server) MustNewClient() *node.KvClient {
+	client, err := node.NewKvClient(s.URL())
+	if err != nil {
+		logrus.WithError(err).WithField("url", s.URL()).Fatal("Failed to create kv client")
+	}
+
+	return client
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
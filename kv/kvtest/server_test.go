@@ -0,0 +1,236 @@
+package kvtest_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeBatch(t *testing.T, batcher *kv.Batcher) []byte {
+	data, err := batcher.Build()
+	assert.NoError(t, err)
+
+	encoded, err := data.Encode()
+	assert.NoError(t, err)
+
+	return encoded
+}
+
+func TestServerWriteThenRead(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	admin := common.HexToAddress("0xa1")
+	writer := common.HexToAddress("0xa2")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	// sender of the first transaction becomes admin
+	batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.Set(streamId, []byte("a"), []byte("1"))
+	batcher.Set(streamId, []byte("b"), []byte("2"))
+	batcher.Set(streamId, []byte("c"), []byte("3"))
+	seq1, result := server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	txResult, err := client.WaitTxResult(ctx, seq1, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, txResult.Err())
+
+	val, err := client.GetValue(ctx, streamId, []byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, seq1, val.Version)
+	assert.Equal(t, []byte("2"), val.Data)
+
+	var keys []string
+	client.Scan(ctx, streamId, kv.ScanOptions{})(func(pair *node.KeyValue, err error) bool {
+		assert.NoError(t, err)
+		keys = append(keys, string(pair.Key))
+		return true
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+
+	iter := client.NewIterator(streamId)
+	assert.NoError(t, iter.SeekToLast(ctx))
+	assert.Equal(t, []byte("c"), iter.KeyValue().Key)
+	assert.NoError(t, iter.Prev(ctx))
+	assert.Equal(t, []byte("b"), iter.KeyValue().Key)
+
+	// no write permission
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.Set(streamId, []byte("a"), []byte("x"))
+	_, result = server.Apply(writer, encodeBatch(t, batcher))
+	assert.True(t, strings.HasPrefix(result, "WritePermissionDenied"))
+
+	// granted
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.GrantWriteRole(streamId, writer)
+	_, result = server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	ok, err := client.IsWriterOfStream(ctx, writer, streamId)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	batcher = kv.NewBatcher(seq1, nil, nil)
+	batcher.Set(streamId, []byte("a"), []byte("4"))
+	seq2, result := server.Apply(writer, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	// stale version
+	batcher = kv.NewBatcher(seq1, nil, nil)
+	batcher.Watch(streamId, []byte("a"))
+	batcher.Set(streamId, []byte("d"), []byte("5"))
+	_, result = server.Apply(writer, encodeBatch(t, batcher))
+	assert.Equal(t, "VersionConfirmed", result)
+
+	val, err = client.GetValue(ctx, streamId, []byte("d"))
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	// read at version
+	val, err = client.GetValue(ctx, streamId, []byte("a"), seq1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), val.Data)
+
+	val, err = client.GetValue(ctx, streamId, []byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, seq2, val.Version)
+	assert.Equal(t, []byte("4"), val.Data)
+
+	// special key is only writable by key writers
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.SetKeyToSpecial(streamId, []byte("a"))
+	_, result = server.Apply(writer, encodeBatch(t, batcher))
+	assert.True(t, strings.HasPrefix(result, "AccessControlPermissionDenied"))
+
+	_, result = server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	ok, err = client.HasWritePermission(ctx, writer, streamId, []byte("a"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.GrantSpecialWriteRole(streamId, []byte("a"), writer)
+	_, result = server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	ok, err = client.HasWritePermission(ctx, writer, streamId, []byte("a"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	streamIds, err := client.GetHoldingStreamIds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Hash{streamId}, streamIds)
+
+	_, result = server.Apply(admin, []byte{1, 2, 3})
+	assert.True(t, strings.HasPrefix(result, "DataParseError"))
+}
+
+func TestServerEncryptedStream(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	admin := common.HexToAddress("0xa1")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+
+	keyring := kv.NewKeyring()
+	assert.NoError(t, keyring.AddKey(streamId, 1, bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, keyring.SetKeyHashing(streamId, []byte("secret")))
+
+	batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.SetKeyring(keyring)
+	batcher.Set(streamId, []byte("a"), []byte("1"))
+	_, result := server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	// rotate key for new values
+	assert.NoError(t, keyring.Rotate(streamId, 2, bytes.Repeat([]byte{2}, 32)))
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.SetKeyring(keyring)
+	batcher.Set(streamId, []byte("b"), []byte("2"))
+	seq, result := server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	// keys and values are not public on kv node
+	plain := kv.NewClient(kvClient)
+	val, err := plain.GetValue(ctx, streamId, []byte("a"))
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	first, err := plain.GetFirst(ctx, streamId, 0, 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(first.Key))
+	assert.NotContains(t, []string{"1", "2"}, string(first.Data))
+
+	// decrypted transparently
+	client := plain.WithKeyring(keyring)
+	for key, value := range map[string]string{"a": "1", "b": "2"} {
+		val, err = client.GetValue(ctx, streamId, []byte(key))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(value), val.Data)
+	}
+
+	pairs := make(map[string]string)
+	client.Scan(ctx, streamId, kv.ScanOptions{})(func(pair *node.KeyValue, err error) bool {
+		assert.NoError(t, err)
+		pairs[string(pair.Key)] = string(pair.Data)
+		return true
+	})
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, pairs)
+
+	iter := client.NewIterator(streamId)
+	assert.NoError(t, iter.SeekToFirst(ctx))
+	assert.NoError(t, iter.Next(ctx))
+	assert.True(t, iter.Valid())
+	assert.Equal(t, pairs[string(iter.KeyValue().Key)], string(iter.KeyValue().Data))
+	assert.NoError(t, iter.Next(ctx))
+	assert.False(t, iter.Valid())
+
+	// paginated reads not supported
+	_, err = client.Get(ctx, streamId, []byte("a"), 0, 1024)
+	assert.True(t, errors.Is(err, kv.ErrEncryptedStream))
+	_, err = client.OpenValue(ctx, streamId, []byte("a"))
+	assert.True(t, errors.Is(err, kv.ErrEncryptedStream))
+
+	// watch hashed keys
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := client.Watch(watchCtx, streamId, kv.WatchOption{
+		Keys:         [][]byte{[]byte("a")},
+		PollInterval: 10 * time.Millisecond,
+		FromVersion:  &seq,
+	})
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.SetKeyring(keyring)
+	batcher.Set(streamId, []byte("a"), []byte("3"))
+	seqA, result := server.Apply(admin, encodeBatch(t, batcher))
+	assert.Equal(t, "Commit", result)
+
+	change := <-changes
+	assert.Equal(t, []byte("a"), change.Key)
+	assert.Equal(t, seqA, change.NewVersion)
+	assert.Equal(t, []byte("3"), change.Value)
+}
@@ -0,0 +1,415 @@
+// Package kvtest provides an in-process fake kv node for tests, which serves the kv_* RPC methods
+// backed by an in-memory versioned store.
+package kvtest
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// access control types, see kv.StreamData
+const (
+	aclGrantAdminRole           = 0x00
+	aclRenounceAdminRole        = 0x01
+	aclSetKeyToSpecial          = 0x10
+	aclSetKeyToNormal           = 0x11
+	aclGrantWriteRole           = 0x20
+	aclRevokeWriteRole          = 0x21
+	aclRenounceWriteRole        = 0x22
+	aclGrantSpecialWriteRole    = 0x30
+	aclRevokeSpecialWriteRole   = 0x31
+	aclRenounceSpecialWriteRole = 0x32
+)
+
+// entry is a value updated at version.
+type entry[T any] struct {
+	version uint64
+	value   T
+}
+
+// history is the ordered updates of a value.
+type history[T any] []entry[T]
+
+// at returns the latest entry not after the specified version.
+func (h history[T]) at(version uint64) (entry[T], bool) {
+	i := sort.Search(len(h), func(i int) bool { return h[i].version > version })
+	if i == 0 {
+		return entry[T]{}, false
+	}
+
+	return h[i-1], true
+}
+
+func (h *history[T]) set(version uint64, value T) {
+	if n := len(*h); n > 0 && (*h)[n-1].version == version {
+		(*h)[n-1].value = value
+	} else {
+		*h = append(*h, entry[T]{version, value})
+	}
+}
+
+// stream is the versioned state of a kv stream.
+type stream struct {
+	values      map[string]*history[[]byte]
+	admins      map[common.Address]*history[bool]
+	writers     map[common.Address]*history[bool]
+	specialKeys map[string]*history[bool]
+	keyWriters  map[string]map[common.Address]*history[bool]
+}
+
+func newStream() *stream {
+	return &stream{
+		values:      make(map[string]*history[[]byte]),
+		admins:      make(map[common.Address]*history[bool]),
+		writers:     make(map[common.Address]*history[bool]),
+		specialKeys: make(map[string]*history[bool]),
+		keyWriters:  make(map[string]map[common.Address]*history[bool]),
+	}
+}
+
+func lookup[K comparable, T any](m map[K]*history[T], key K) *history[T] {
+	h, ok := m[key]
+	if !ok {
+		h = &history[T]{}
+		m[key] = h
+	}
+
+	return h
+}
+
+func flag[K comparable](m map[K]*history[bool], key K, version uint64) bool {
+	h, ok := m[key]
+	if !ok {
+		return false
+	}
+
+	e, ok := h.at(version)
+
+	return ok && e.value
+}
+
+func (s *stream) isAdmin(account common.Address, version uint64) bool {
+	return flag(s.admins, account, version)
+}
+
+func (s *stream) isWriterOfStream(account common.Address, version uint64) bool {
+	return flag(s.writers, account, version)
+}
+
+func (s *stream) isSpecialKey(key []byte, version uint64) bool {
+	return flag(s.specialKeys, string(key), version)
+}
+
+func (s *stream) isWriterOfKey(account common.Address, key []byte, version uint64) bool {
+	writers, ok := s.keyWriters[string(key)]
+	return ok && flag(writers, account, version)
+}
+
+// hasWritePermission returns whether the account could write the key. Special keys could only be written
+// by key writers, and the others could be written by admins and stream writers.
+func (s *stream) hasWritePermission(account common.Address, key []byte, version uint64) bool {
+	if s.isSpecialKey(key, version) {
+		return s.isWriterOfKey(account, key, version)
+	}
+
+	return s.isAdmin(account, version) || s.isWriterOfStream(account, version)
+}
+
+// keys returns the sorted keys that exist at the specified version.
+func (s *stream) keys(version uint64) []string {
+	var keys []string
+
+	for k, h := range s.values {
+		if _, ok := h.at(version); ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Store is an in-memory versioned kv store, which applies kv transactions in order of tx seq as kv node.
+// The version of keys and access controls is the tx seq that updated them, and tx seq starts from 1.
+//
+// Access control rules:
+//   - The sender of the first transaction on a stream becomes the admin of stream.
+//   - Admin is required to grant or revoke roles, and to set keys to special or normal.
+//   - Special keys could only be written by key writers, and the others by admins and stream writers.
+type Store struct {
+	mu      sync.RWMutex
+	results []string // index is tx seq, "" for tx seq 0
+	streams map[common.Hash]*stream
+}
+
+// NewStore creates an empty store.
+func NewStore() *Store {
+	return &Store{
+		results: []string{""},
+		streams: make(map[common.Hash]*stream),
+	}
+}
+
+func (s *Store) stream(streamId common.Hash) *stream {
+	return s.streams[streamId]
+}
+
+// NextTxSeq returns the tx seq of next transaction to apply.
+func (s *Store) NextTxSeq() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return uint64(len(s.results))
+}
+
+// Apply applies the StreamData encoded by kv.StreamData.Encode in a new transaction sent by sender, and
+// returns the tx seq and result as kv node, e.g. "Commit", "VersionConfirmed" or "WritePermissionDenied: ...".
+func (s *Store) Apply(sender common.Address, data []byte) (uint64, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txSeq := uint64(len(s.results))
+	result := s.apply(txSeq, sender, data)
+	s.results = append(s.results, result)
+
+	return txSeq, result
+}
+
+// Set writes the key-value in a new transaction without access control, and returns the tx seq.
+func (s *Store) Set(streamId common.Hash, key, value []byte) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txSeq := uint64(len(s.results))
+
+	st, ok := s.streams[streamId]
+	if !ok {
+		st = newStream()
+		s.streams[streamId] = st
+	}
+
+	lookup(st.values, string(key)).set(txSeq, bytes.Clone(value))
+	s.results = append(s.results, "Commit")
+
+	return txSeq
+}
+
+func (s *Store) apply(txSeq uint64, sender common.Address, data []byte) string {
+	sd, err := kv.DecodeStreamData(data)
+	if err != nil {
+		return fmt.Sprintf("DataParseError: %v", err)
+	}
+
+	// version check of keys to read and write
+	for _, v := range sd.Reads {
+		if s.keyVersion(v.StreamId, v.Key) > sd.Version {
+			return "VersionConfirmed"
+		}
+	}
+
+	for _, v := range sd.Writes {
+		if s.keyVersion(v.StreamId, v.Key) > sd.Version {
+			return "VersionConfirmed"
+		}
+	}
+
+	// changes are applied at tx seq, and reverted on failure
+	var undo []func()
+	defer func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+
+	getStream := func(streamId common.Hash) *stream {
+		st, ok := s.streams[streamId]
+		if ok {
+			return st
+		}
+
+		// sender becomes admin of new stream
+		st = newStream()
+		lookup(st.admins, sender).set(txSeq, true)
+		s.streams[streamId] = st
+		undo = append(undo, func() { delete(s.streams, streamId) })
+
+		return st
+	}
+
+	setFlag := func(h *history[bool], value bool) {
+		snapshot := slices.Clone(*h)
+		h.set(txSeq, value)
+		undo = append(undo, func() { *h = snapshot })
+	}
+
+	for _, v := range sd.Controls {
+		st := getStream(v.StreamId)
+		t := uint8(v.Type)
+
+		switch t {
+		case aclRenounceAdminRole:
+			setFlag(lookup(st.admins, sender), false)
+			continue
+		case aclRenounceWriteRole:
+			setFlag(lookup(st.writers, sender), false)
+			continue
+		case aclRenounceSpecialWriteRole:
+			setFlag(lookup(keyWriters(st, v.Key), sender), false)
+			continue
+		}
+
+		if !st.isAdmin(sender, txSeq) {
+			return fmt.Sprintf("AccessControlPermissionDenied: %v, stream: %v, sender: %v", v.Type, v.StreamId, sender)
+		}
+
+		switch t {
+		case aclGrantAdminRole:
+			setFlag(lookup(st.admins, *v.Account), true)
+		case aclSetKeyToSpecial:
+			setFlag(lookup(st.specialKeys, string(v.Key)), true)
+		case aclSetKeyToNormal:
+			setFlag(lookup(st.specialKeys, string(v.Key)), false)
+		case aclGrantWriteRole:
+			setFlag(lookup(st.writers, *v.Account), true)
+		case aclRevokeWriteRole:
+			setFlag(lookup(st.writers, *v.Account), false)
+		case aclGrantSpecialWriteRole:
+			setFlag(lookup(keyWriters(st, v.Key), *v.Account), true)
+		case aclRevokeSpecialWriteRole:
+			setFlag(lookup(keyWriters(st, v.Key), *v.Account), false)
+		}
+	}
+
+	for _, v := range sd.Writes {
+		st := getStream(v.StreamId)
+
+		if !st.hasWritePermission(sender, v.Key, txSeq) {
+			return fmt.Sprintf("WritePermissionDenied: stream: %v, key: %#x, sender: %v", v.StreamId, v.Key, sender)
+		}
+	}
+
+	for _, v := range sd.Writes {
+		lookup(s.streams[v.StreamId].values, string(v.Key)).set(txSeq, bytes.Clone(v.Data))
+	}
+
+	undo = nil
+
+	return "Commit"
+}
+
+func keyWriters(st *stream, key []byte) map[common.Address]*history[bool] {
+	writers, ok := st.keyWriters[string(key)]
+	if !ok {
+		writers = make(map[common.Address]*history[bool])
+		st.keyWriters[string(key)] = writers
+	}
+
+	return writers
+}
+
+// keyVersion returns the latest version of key, or 0 if not exists.
+func (s *Store) keyVersion(streamId common.Hash, key []byte) uint64 {
+	st := s.stream(streamId)
+	if st == nil {
+		return 0
+	}
+
+	h, ok := st.values[string(key)]
+	if !ok {
+		return 0
+	}
+
+	e, _ := h.at(math.MaxUint64)
+
+	return e.version
+}
+
+// value returns the paginated value of key at version, or nil if not exists.
+func (s *Store) value(streamId common.Hash, key []byte, startIndex, length, version uint64) *node.KeyValue {
+	st := s.stream(streamId)
+	if st == nil {
+		return nil
+	}
+
+	h, ok := st.values[string(key)]
+	if !ok {
+		return nil
+	}
+
+	e, ok := h.at(version)
+	if !ok {
+		return nil
+	}
+
+	size := uint64(len(e.value))
+	start := min(startIndex, size)
+	end := min(start+length, size)
+
+	return &node.KeyValue{
+		Version: e.version,
+		Key:     bytes.Clone(key),
+		Data:    bytes.Clone(e.value[start:end]),
+		Size:    size,
+	}
+}
+
+// seek returns the paginated key-value adjacent to key at version, or nil if not exists.
+func (s *Store) seek(streamId common.Hash, key []byte, startIndex, length uint64, inclusive, next bool, version uint64) *node.KeyValue {
+	st := s.stream(streamId)
+	if st == nil {
+		return nil
+	}
+
+	keys := st.keys(version)
+
+	if next {
+		i := sort.Search(len(keys), func(i int) bool {
+			c := bytes.Compare([]byte(keys[i]), key)
+			return c > 0 || (inclusive && c == 0)
+		})
+
+		if i == len(keys) {
+			return nil
+		}
+
+		return s.value(streamId, []byte(keys[i]), startIndex, length, version)
+	}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		c := bytes.Compare([]byte(keys[i]), key)
+		if c < 0 || (inclusive && c == 0) {
+			return s.value(streamId, []byte(keys[i]), startIndex, length, version)
+		}
+	}
+
+	return nil
+}
+
+// edge returns the paginated key-value of the first or last key at version, or nil if stream is empty.
+func (s *Store) edge(streamId common.Hash, startIndex, length uint64, first bool, version uint64) *node.KeyValue {
+	st := s.stream(streamId)
+	if st == nil {
+		return nil
+	}
+
+	keys := st.keys(version)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	key := keys[len(keys)-1]
+	if first {
+		key = keys[0]
+	}
+
+	return s.value(streamId, []byte(key), startIndex, length, version)
+}
@@ -0,0 +1,119 @@
+package kv
+
+import (
+	"context"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Map is a typed view of kv stream, whose keys and values are encoded by the specified codecs.
+//
+// Note, a key with empty value is regarded as deleted.
+type Map[K, V any] struct {
+	client     *Client
+	streamId   common.Hash
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+}
+
+// NewMap creates a typed map bound to the given stream.
+func NewMap[K, V any](client *Client, streamId common.Hash, keyCodec Codec[K], valueCodec Codec[V]) *Map[K, V] {
+	return &Map[K, V]{
+		client:     client,
+		streamId:   streamId,
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+	}
+}
+
+// StreamId returns the stream id that map bound to.
+func (m *Map[K, V]) StreamId() common.Hash {
+	return m.streamId
+}
+
+// Get returns the value of given key, and false if key not found.
+func (m *Map[K, V]) Get(ctx context.Context, key K, version ...uint64) (value V, found bool, err error) {
+	encodedKey, err := m.keyCodec.Encode(key)
+	if err != nil {
+		return value, false, errors.WithMessage(err, "failed to encode key")
+	}
+
+	val, err := m.client.GetValue(ctx, m.streamId, encodedKey, version...)
+	if err != nil || val == nil || len(val.Data) == 0 {
+		return value, false, err
+	}
+
+	if value, err = m.valueCodec.Decode(val.Data); err != nil {
+		return value, false, errors.WithMessage(err, "failed to decode value")
+	}
+
+	return value, true, nil
+}
+
+// Put caches a write operation of given key-value in batcher, which will be settled on chain
+// once the batcher executed.
+func (m *Map[K, V]) Put(batcher *Batcher, key K, value V) error {
+	encodedKey, err := m.keyCodec.Encode(key)
+	if err != nil {
+		return errors.WithMessage(err, "failed to encode key")
+	}
+
+	encodedValue, err := m.valueCodec.Encode(value)
+	if err != nil {
+		return errors.WithMessage(err, "failed to encode value")
+	}
+
+	batcher.Set(m.streamId, encodedKey, encodedValue)
+
+	return nil
+}
+
+// Delete caches a write operation with empty value of given key in batcher.
+func (m *Map[K, V]) Delete(batcher *Batcher, key K) error {
+	encodedKey, err := m.keyCodec.Encode(key)
+	if err != nil {
+		return errors.WithMessage(err, "failed to encode key")
+	}
+
+	batcher.Set(m.streamId, encodedKey, []byte{})
+
+	return nil
+}
+
+// Iterate iterates over all key-values in ascending order of encoded keys, and stops if the
+// callback returns false. Deleted keys are skipped.
+func (m *Map[K, V]) Iterate(ctx context.Context, callback func(key K, value V) bool, version ...uint64) error {
+	v := uint64(math.MaxUint64)
+	if len(version) > 0 {
+		v = version[0]
+	}
+
+	iter := m.client.NewIterator(m.streamId, v)
+
+	err := iter.SeekToFirst(ctx)
+
+	for ; err == nil && iter.Valid(); err = iter.Next(ctx) {
+		pair := iter.KeyValue()
+		if len(pair.Data) == 0 {
+			continue
+		}
+
+		key, decodeErr := m.keyCodec.Decode(pair.Key)
+		if decodeErr != nil {
+			return errors.WithMessagef(decodeErr, "failed to decode key %x", pair.Key)
+		}
+
+		value, decodeErr := m.valueCodec.Decode(pair.Data)
+		if decodeErr != nil {
+			return errors.WithMessagef(decodeErr, "failed to decode value of key %x", pair.Key)
+		}
+
+		if !callback(key, value) {
+			return nil
+		}
+	}
+
+	return err
+}
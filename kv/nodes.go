@@ -0,0 +1,282 @@
+package kv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoKvNode             = errors.New("no kv node available")
+	ErrQuorumNotReached     = errors.New("quorum not reached")
+	ErrMinVersionNotReached = errors.New("no kv node reaches the min version")
+)
+
+// Consistency is the read consistency mode of kv client.
+type Consistency int
+
+const (
+	// ConsistencyAny reads from any healthy node, and fails over to other nodes on error.
+	ConsistencyAny Consistency = iota
+
+	// ConsistencyQuorum reads values from a quorum of nodes, and returns the result of the latest version.
+	// Other queries, e.g. key iteration, fail over as ConsistencyAny.
+	ConsistencyQuorum
+
+	// ConsistencyMinVersion reads from nodes that have replayed the min version, e.g. the tx seq of a
+	// previous write, so as to read your writes.
+	ConsistencyMinVersion
+)
+
+// String implements the fmt.Stringer interface.
+func (c Consistency) String() string {
+	switch c {
+	case ConsistencyAny:
+		return "any"
+	case ConsistencyQuorum:
+		return "quorum"
+	case ConsistencyMinVersion:
+		return "minVersion"
+	default:
+		return "unknown"
+	}
+}
+
+// ClientOption is the option to create kv client.
+type ClientOption struct {
+	Consistency Consistency
+	Quorum      int    // number of nodes to read from in ConsistencyQuorum mode, default majority of nodes
+	MinVersion  uint64 // min version that nodes have replayed in ConsistencyMinVersion mode
+
+	// UnhealthyDuration is the duration that a failed node is deprioritized, default 30 seconds.
+	UnhealthyDuration time.Duration
+
+	// Keyring is used to decrypt values of encrypted streams, nil for no encryption.
+	Keyring *Keyring
+}
+
+// NodeHealth is the health status of a kv node.
+type NodeHealth struct {
+	URL     string
+	Healthy bool
+	Error   error
+}
+
+// kvNode is a kv node with health status, which is shared by clients of different consistency modes.
+type kvNode struct {
+	client *node.KvClient
+
+	mu             sync.Mutex
+	unhealthyUntil time.Time
+	replayedSeq    uint64 // tx seq + 1 that known to be replayed, 0 if unknown
+}
+
+func (n *kvNode) healthy() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return time.Now().After(n.unhealthyUntil)
+}
+
+func (n *kvNode) setHealthy(healthy bool, unhealthyDuration time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if healthy {
+		n.unhealthyUntil = time.Time{}
+	} else {
+		n.unhealthyUntil = time.Now().Add(unhealthyDuration)
+	}
+}
+
+// replayed checks whether the node has replayed the given tx seq.
+func (n *kvNode) replayed(ctx context.Context, txSeq uint64) (bool, error) {
+	n.mu.Lock()
+	known := n.replayedSeq > txSeq
+	n.mu.Unlock()
+
+	if known {
+		return true, nil
+	}
+
+	// empty result indicates the tx has not been replayed yet
+	result, err := n.client.GetTransactionResult(ctx, txSeq)
+	if err != nil || len(result) == 0 {
+		return false, err
+	}
+
+	n.mu.Lock()
+	n.replayedSeq = max(n.replayedSeq, txSeq+1)
+	n.mu.Unlock()
+
+	return true, nil
+}
+
+// CheckHealth probes all kv nodes and updates their health status.
+func (c *Client) CheckHealth(ctx context.Context) []NodeHealth {
+	var wg sync.WaitGroup
+	health := make([]NodeHealth, len(c.nodes))
+
+	for i, n := range c.nodes {
+		wg.Add(1)
+
+		go func(i int, n *kvNode) {
+			defer wg.Done()
+
+			_, err := n.client.GetHoldingStreamIds(ctx)
+			n.setHealthy(err == nil, c.option.UnhealthyDuration)
+			health[i] = NodeHealth{n.client.URL(), err == nil, err}
+		}(i, n)
+	}
+
+	wg.Wait()
+
+	return health
+}
+
+// candidates returns nodes to read from, in which healthy nodes take precedence.
+func (c *Client) candidates(ctx context.Context) ([]*kvNode, error) {
+	var healthy, unhealthy []*kvNode
+
+	for _, n := range c.nodes {
+		if c.option.Consistency == ConsistencyMinVersion {
+			ok, err := n.replayed(ctx, c.option.MinVersion)
+			if err != nil {
+				n.setHealthy(false, c.option.UnhealthyDuration)
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		if n.healthy() {
+			healthy = append(healthy, n)
+		} else {
+			unhealthy = append(unhealthy, n)
+		}
+	}
+
+	if len(healthy)+len(unhealthy) == 0 {
+		if c.option.Consistency == ConsistencyMinVersion {
+			return nil, ErrMinVersionNotReached
+		}
+
+		return nil, ErrNoKvNode
+	}
+
+	return append(healthy, unhealthy...), nil
+}
+
+// invoke calls fn on node, and updates the health status of node.
+func invoke[T any](ctx context.Context, c *Client, n *kvNode, fn func(*node.KvClient) (T, error)) (T, error) {
+	result, err := fn(n.client)
+
+	// do not blame node if cancelled
+	if ctx.Err() == nil {
+		n.setHealthy(err == nil, c.option.UnhealthyDuration)
+	}
+
+	return result, err
+}
+
+// call calls fn on candidate nodes one by one until succeeded.
+func call[T any](ctx context.Context, c *Client, fn func(*node.KvClient) (T, error)) (T, error) {
+	var result T
+
+	nodes, err := c.candidates(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, n := range nodes {
+		if result, err = invoke(ctx, c, n, fn); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return result, err
+}
+
+// callVersioned calls fn according to the consistency mode, and returns the result of the latest version
+// in ConsistencyQuorum mode.
+func callVersioned[T any](ctx context.Context, c *Client, fn func(*node.KvClient) (T, error), version func(T) uint64) (T, error) {
+	if c.option.Consistency != ConsistencyQuorum {
+		return call(ctx, c, fn)
+	}
+
+	var best T
+
+	nodes, err := c.candidates(ctx)
+	if err != nil {
+		return best, err
+	}
+
+	quorum := c.option.Quorum
+	if quorum <= 0 {
+		quorum = len(c.nodes)/2 + 1
+	}
+
+	type response struct {
+		result T
+		err    error
+	}
+
+	responses := make(chan response, len(nodes))
+	var next, pending int
+
+	launch := func() {
+		go func(n *kvNode) {
+			result, err := invoke(ctx, c, n, fn)
+			responses <- response{result, err}
+		}(nodes[next])
+
+		next++
+		pending++
+	}
+
+	for next < len(nodes) && next < quorum {
+		launch()
+	}
+
+	var succeeded int
+	var lastErr error
+
+	for ; pending > 0; pending-- {
+		resp := <-responses
+
+		if resp.err != nil {
+			lastErr = resp.err
+
+			// replace the failed node with another one
+			if next < len(nodes) && ctx.Err() == nil {
+				launch()
+			}
+
+			continue
+		}
+
+		if succeeded == 0 || version(resp.result) > version(best) {
+			best = resp.result
+		}
+
+		succeeded++
+	}
+
+	if succeeded < quorum {
+		return best, errors.WithMessagef(ErrQuorumNotReached, "%v of %v nodes responded, quorum is %v, last error = %v", succeeded, len(nodes), quorum, lastErr)
+	}
+
+	return best, nil
+}
+
+func valueVersion(val *node.Value) uint64 {
+	if val == nil {
+		return 0
+	}
+
+	return val.Version
+}
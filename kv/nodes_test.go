@@ -0,0 +1,98 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeKvNode starts a kv node that serves value of the given version, and fails if version is 0.
+func newFakeKvNode(t *testing.T, version uint64) *node.KvClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if version == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var request struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+		switch request.Method {
+		case "kv_getValue":
+			result = &node.Value{Version: version, Data: []byte{byte(version)}, Size: 1}
+		case "kv_getTransactionResult":
+			var txSeq uint64
+			json.Unmarshal(request.Params[0], &txSeq)
+			if txSeq <= version {
+				result = "Commit"
+			}
+		case "kv_getHoldingStreamIds":
+			result = []common.Hash{}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.Id,
+			"result":  result,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := node.NewKvClient(server.URL)
+	assert.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func TestClientConsistency(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+
+	client := NewClient(newFakeKvNode(t, 0), newFakeKvNode(t, 3), newFakeKvNode(t, 5))
+
+	// fail over to the next node
+	val, err := client.GetValue(ctx, streamId, []byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), val.Version)
+
+	health := client.CheckHealth(ctx)
+	assert.False(t, health[0].Healthy)
+	assert.True(t, health[1].Healthy)
+	assert.True(t, health[2].Healthy)
+
+	// latest version across all nodes
+	val, err = client.WithConsistency(ConsistencyQuorum).GetValue(ctx, streamId, []byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), val.Version)
+
+	// read your writes
+	val, err = client.WithMinVersion(4).GetValue(ctx, streamId, []byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), val.Version)
+
+	_, err = client.WithMinVersion(6).GetValue(ctx, streamId, []byte("key"))
+	assert.Equal(t, ErrMinVersionNotReached, err)
+
+	// quorum not reached
+	client = NewClientWithOption([]*node.KvClient{newFakeKvNode(t, 0), newFakeKvNode(t, 0), newFakeKvNode(t, 1)}, ClientOption{
+		Consistency: ConsistencyQuorum,
+	})
+	_, err = client.GetValue(ctx, streamId, []byte("key"))
+	assert.True(t, errors.Is(err, ErrQuorumNotReached))
+}
@@ -0,0 +1,135 @@
+package kv
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TxStatus is the typed replay result of a kv transaction on kv node.
+type TxStatus int
+
+const (
+	// TxStatusPending indicates the transaction is not replayed by kv node yet.
+	TxStatusPending TxStatus = iota
+	// TxStatusCommitted indicates the transaction is applied successfully.
+	TxStatusCommitted
+	// TxStatusConflict indicates any read or written key is updated after the transaction version.
+	TxStatusConflict
+	// TxStatusPermissionDenied indicates the sender has no permission to write keys or change access control.
+	TxStatusPermissionDenied
+	// TxStatusFailed indicates the transaction is rejected for other reasons, e.g. invalid data.
+	TxStatusFailed
+)
+
+func (status TxStatus) String() string {
+	switch status {
+	case TxStatusPending:
+		return "pending"
+	case TxStatusCommitted:
+		return "committed"
+	case TxStatusConflict:
+		return "conflict"
+	case TxStatusPermissionDenied:
+		return "permission denied"
+	default:
+		return "failed"
+	}
+}
+
+var (
+	ErrTxConflict         = errors.New("kv transaction conflict")
+	ErrTxPermissionDenied = errors.New("kv transaction permission denied")
+	ErrTxFailed           = errors.New("kv transaction failed")
+)
+
+// ParseTxStatus parses the result string returned by kv_getTransactionResult RPC, e.g. "Commit",
+// "VersionConfirmed" or "WritePermissionDenied: ...".
+func ParseTxStatus(result string) TxStatus {
+	// result message may be followed by details, e.g. "DataParseError: ..."
+	name, _, _ := strings.Cut(result, ":")
+
+	switch strings.TrimSpace(name) {
+	case "":
+		return TxStatusPending
+	case "Commit":
+		return TxStatusCommitted
+	case "VersionConfirmed":
+		return TxStatusConflict
+	case "SenderNoWritePermission", "WritePermissionDenied", "AccessControlPermissionDenied":
+		return TxStatusPermissionDenied
+	default:
+		return TxStatusFailed
+	}
+}
+
+// TxResult is the replay result of a kv transaction.
+type TxResult struct {
+	TxSeq   uint64
+	Status  TxStatus
+	Message string // raw result message from kv node
+}
+
+// Err returns the error of corresponding status, and nil if transaction committed.
+func (result *TxResult) Err() error {
+	switch result.Status {
+	case TxStatusCommitted:
+		return nil
+	case TxStatusConflict:
+		return errors.WithMessage(ErrTxConflict, result.Message)
+	case TxStatusPermissionDenied:
+		return errors.WithMessage(ErrTxPermissionDenied, result.Message)
+	case TxStatusPending:
+		return errors.New("kv transaction is pending")
+	default:
+		return errors.WithMessage(ErrTxFailed, result.Message)
+	}
+}
+
+// GetTxResult queries the typed replay result of a given transaction.
+func (c *Client) GetTxResult(ctx context.Context, txSeq uint64) (*TxResult, error) {
+	message, err := c.GetTransactionResult(ctx, txSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxResult{
+		TxSeq:   txSeq,
+		Status:  ParseTxStatus(message),
+		Message: message,
+	}, nil
+}
+
+// WaitTxResult polls the replay result of a given transaction until it is replayed by kv node,
+// or the context is done.
+func (c *Client) WaitTxResult(ctx context.Context, txSeq uint64, pollInterval time.Duration) (*TxResult, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := c.GetTxResult(ctx, txSeq)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to get transaction result")
+		}
+
+		if result.Status != TxStatusPending {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
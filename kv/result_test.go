@@ -0,0 +1,46 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTxStatus(t *testing.T) {
+	assert.Equal(t, TxStatusPending, ParseTxStatus(""))
+	assert.Equal(t, TxStatusCommitted, ParseTxStatus("Commit"))
+	assert.Equal(t, TxStatusConflict, ParseTxStatus("VersionConfirmed"))
+	assert.Equal(t, TxStatusPermissionDenied, ParseTxStatus("SenderNoWritePermission"))
+	assert.Equal(t, TxStatusPermissionDenied, ParseTxStatus("WritePermissionDenied: stream: 0x01, key: 0x02"))
+	assert.Equal(t, TxStatusFailed, ParseTxStatus("DataParseError: invalid data"))
+	assert.Equal(t, TxStatusFailed, ParseTxStatus("TagsMismatch"))
+}
+
+func TestTxResultErr(t *testing.T) {
+	assert.NoError(t, (&TxResult{Status: TxStatusCommitted}).Err())
+	assert.True(t, errors.Is((&TxResult{Status: TxStatusConflict}).Err(), ErrTxConflict))
+	assert.True(t, errors.Is((&TxResult{Status: TxStatusPermissionDenied}).Err(), ErrTxPermissionDenied))
+	assert.True(t, errors.Is((&TxResult{Status: TxStatusFailed}).Err(), ErrTxFailed))
+	assert.Error(t, (&TxResult{Status: TxStatusPending}).Err())
+}
+
+func TestBatcherWaitTxResult(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(newFakeKvNode(t, 3))
+	batcher := NewBatcher(0, nil, nil)
+	option := ExecOption{
+		WaitTimeout:  50 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	}
+
+	result, err := batcher.waitTxResult(ctx, client, 3, option)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusCommitted, result.Status)
+
+	// not replayed within timeout
+	_, err = batcher.waitTxResult(ctx, client, 4, option)
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Seq2 is an iterator over sequences of pairs of values, which is compatible with iter.Seq2 since Go 1.23.
+// Call it with a yield function, and the iteration stops once yield returns false.
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// ScanOptions is the option to scan keys in kv stream.
+type ScanOptions struct {
+	Prefix   []byte // only keys with prefix are scanned
+	Start    []byte // scan keys from Start (inclusive)
+	End      []byte // scan keys until End (exclusive)
+	Limit    int    // max number of keys to scan, 0 for unlimited
+	Reverse  bool   // scan keys in descending order
+	KeysOnly bool   // only key and value size are returned, without value data
+	Version  uint64 // version to read at, latest version if 0
+
+	Prefetch    int // number of keys to prefetch, default 64
+	Concurrency int // number of routines to fetch values larger than a single query, default 4
+}
+
+// scanItem is a key-value whose data may be fetched asynchronously.
+type scanItem struct {
+	pair *node.KeyValue
+	err  error
+	done chan struct{} // closed when pair or err is ready
+}
+
+// Scan iterates over the key-values in range of the specified stream. Keys are prefetched in background,
+// and values larger than a single query are fetched concurrently, while the key-values are yielded in order.
+// Note, kv node only serves one key per query, so keys are walked one by one with GetNext (or GetPrev), and
+// Prefetch only bounds how far the walk could go ahead of the consumer.
+// Once any error occurred, it will be yielded along with nil key-value, and the iteration stops.
+//
+// Values of encrypted stream are decrypted if the client has keyring, except in KeysOnly mode, in which the
+// stored keys and sizes are yielded.
+func (c *Client) Scan(ctx context.Context, streamId common.Hash, opt ScanOptions) Seq2[*node.KeyValue, error] {
+	if opt.Version == 0 {
+		opt.Version = math.MaxUint64
+	}
+
+	if opt.Prefetch <= 0 {
+		opt.Prefetch = 64
+	}
+
+	if opt.Concurrency <= 0 {
+		opt.Concurrency = 4
+	}
+
+	lower, upper := scanBounds(opt)
+
+	return func(yield func(*node.KeyValue, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var err error
+
+		items := make(chan *scanItem, opt.Prefetch)
+		go c.prefetch(ctx, streamId, opt, lower, upper, items)
+
+		for item := range items {
+			// cancellation takes precedence over the items ready
+			if err = ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			case <-item.done:
+			}
+
+			if item.err != nil {
+				yield(nil, item.err)
+				return
+			}
+
+			pair := item.pair
+			if !opt.KeysOnly {
+				if pair, err = c.option.Keyring.decryptPair(streamId, pair); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+
+			if !yield(pair, nil) {
+				return
+			}
+		}
+
+		// prefetch stopped due to context cancelled
+		if err = ctx.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// scanBounds returns the lower (inclusive) and upper (exclusive) bounds of keys to scan, and nil for unbounded.
+func scanBounds(opt ScanOptions) (lower, upper []byte) {
+	lower, upper = opt.Start, opt.End
+
+	if len(opt.Prefix) > 0 {
+		if lower == nil || bytes.Compare(opt.Prefix, lower) > 0 {
+			lower = opt.Prefix
+		}
+
+		if prefixEnd := prefixUpperBound(opt.Prefix); prefixEnd != nil && (upper == nil || bytes.Compare(prefixEnd, upper) < 0) {
+			upper = prefixEnd
+		}
+	}
+
+	return lower, upper
+}
+
+// prefixUpperBound returns the smallest key that is greater than all keys with the given prefix,
+// or nil if no such key.
+func prefixUpperBound(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// prefetch walks keys in order and sends them to items channel, which will be closed at the end.
+func (c *Client) prefetch(ctx context.Context, streamId common.Hash, opt ScanOptions, lower, upper []byte, items chan<- *scanItem) {
+	defer close(items)
+
+	var length uint64
+	if !opt.KeysOnly {
+		length = maxQuerySize
+	}
+
+	// limits the number of routines to fetch large values
+	sem := make(chan struct{}, opt.Concurrency)
+
+	send := func(item *scanItem) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case items <- item:
+			return true
+		}
+	}
+
+	pair, err := c.seekFirst(ctx, streamId, opt, lower, upper, length)
+
+	for count := 0; opt.Limit <= 0 || count < opt.Limit; count++ {
+		if err != nil {
+			item := scanItem{err: err, done: make(chan struct{})}
+			close(item.done)
+			send(&item)
+			return
+		}
+
+		if pair == nil || (lower != nil && bytes.Compare(pair.Key, lower) < 0) || (upper != nil && bytes.Compare(pair.Key, upper) >= 0) {
+			return
+		}
+
+		item := scanItem{pair: pair, done: make(chan struct{})}
+
+		if opt.KeysOnly || uint64(len(pair.Data)) >= pair.Size {
+			close(item.done)
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+
+			go func(item *scanItem) {
+				defer func() { <-sem }()
+				item.pair, item.err = c.completeValue(ctx, streamId, item.pair, opt.Version)
+				close(item.done)
+			}(&item)
+		}
+
+		if !send(&item) {
+			return
+		}
+
+		if opt.Reverse {
+			pair, err = c.GetPrev(ctx, streamId, pair.Key, 0, length, false, opt.Version)
+		} else {
+			pair, err = c.GetNext(ctx, streamId, pair.Key, 0, length, false, opt.Version)
+		}
+	}
+}
+
+func (c *Client) seekFirst(ctx context.Context, streamId common.Hash, opt ScanOptions, lower, upper []byte, length uint64) (*node.KeyValue, error) {
+	switch {
+	case !opt.Reverse && lower != nil:
+		return c.GetNext(ctx, streamId, lower, 0, length, true, opt.Version)
+	case !opt.Reverse:
+		return c.GetFirst(ctx, streamId, 0, length, opt.Version)
+	case upper != nil:
+		return c.GetPrev(ctx, streamId, upper, 0, length, false, opt.Version)
+	default:
+		return c.GetLast(ctx, streamId, 0, length, opt.Version)
+	}
+}
+
+// completeValue fetches the remaining data of a key-value, whose value is larger than a single query.
+func (c *Client) completeValue(ctx context.Context, streamId common.Hash, pair *node.KeyValue, version uint64) (*node.KeyValue, error) {
+	data := pair.Data
+
+	for uint64(len(data)) < pair.Size {
+		seg, err := c.get(ctx, streamId, pair.Key, uint64(len(data)), maxQuerySize, version)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get value of key %x", pair.Key)
+		}
+
+		// value updated during scan, then query from scratch
+		if seg == nil || seg.Version != pair.Version {
+			// stored value, which is decrypted by Scan
+			val, err := c.getValue(ctx, streamId, pair.Key, version)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to get value of key %x", pair.Key)
+			}
+
+			if val == nil {
+				return nil, errors.Errorf("value of key %x not found", pair.Key)
+			}
+
+			return &node.KeyValue{Version: val.Version, Key: pair.Key, Data: val.Data, Size: val.Size}, nil
+		}
+
+		if len(seg.Data) == 0 {
+			return nil, errors.Errorf("unexpected empty data of key %x at offset %v", pair.Key, len(data))
+		}
+
+		data = append(data, seg.Data...)
+	}
+
+	return &node.KeyValue{Version: pair.Version, Key: pair.Key, Data: data, Size: pair.Size}, nil
+}
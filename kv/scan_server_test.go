@@ -0,0 +1,173 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectScan returns the key-values yielded by scan, and the error yielded if any.
+func collectScan(t *testing.T, seq kv.Seq2[*node.KeyValue, error]) ([]*node.KeyValue, error) {
+	var pairs []*node.KeyValue
+	var scanErr error
+
+	seq(func(pair *node.KeyValue, err error) bool {
+		// nothing yielded after error
+		assert.NoError(t, scanErr)
+
+		if err != nil {
+			assert.Nil(t, pair)
+			scanErr = err
+		} else {
+			pairs = append(pairs, pair)
+		}
+
+		return true
+	})
+
+	return pairs, scanErr
+}
+
+func scanKeys(pairs []*node.KeyValue) []string {
+	var keys []string
+	for _, pair := range pairs {
+		keys = append(keys, string(pair.Key))
+	}
+	return keys
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	// larger than a single query
+	large := bytes.Repeat([]byte("0123456789"), 100_000)
+
+	server.Set(streamId, []byte("c"), []byte("v-c"))
+	server.Set(streamId, []byte("b/2"), large)
+	server.Set(streamId, []byte("a"), []byte("v-a"))
+	version := server.Set(streamId, []byte("b/1"), []byte("v-b/1"))
+	server.Set(streamId, []byte("b"), []byte("v-b"))
+	server.Set(streamId, []byte("b0"), []byte("v-b0"))
+	server.Set(streamId, []byte("b/1"), []byte("v-b/1-new"))
+
+	// keys in order with complete values
+	pairs, err := collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "b/1", "b/2", "b0", "c"}, scanKeys(pairs))
+	assert.Equal(t, []byte("v-b/1-new"), pairs[2].Data)
+	assert.Equal(t, large, pairs[3].Data)
+	assert.Equal(t, uint64(len(large)), pairs[3].Size)
+
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Reverse: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "b0", "b/2", "b/1", "b", "a"}, scanKeys(pairs))
+
+	// prefix bounds exclude neighbouring keys
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Prefix: []byte("b/")}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/1", "b/2"}, scanKeys(pairs))
+
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Prefix: []byte("b/"), Reverse: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/2", "b/1"}, scanKeys(pairs))
+
+	// start is inclusive and end is exclusive
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Start: []byte("b"), End: []byte("b0")}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "b/1", "b/2"}, scanKeys(pairs))
+
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Start: []byte("b"), End: []byte("b0"), Reverse: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/2", "b/1", "b"}, scanKeys(pairs))
+
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Limit: 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, scanKeys(pairs))
+
+	// keys only
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Prefix: []byte("b/"), KeysOnly: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/1", "b/2"}, scanKeys(pairs))
+	for _, pair := range pairs {
+		assert.Empty(t, pair.Data)
+	}
+	assert.Equal(t, uint64(len("v-b/1-new")), pairs[0].Size)
+	assert.Equal(t, uint64(len(large)), pairs[1].Size)
+
+	// scan at history version
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Version: version}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b/1", "b/2", "c"}, scanKeys(pairs))
+	assert.Equal(t, []byte("v-b/1"), pairs[1].Data)
+
+	// empty range
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{Prefix: []byte("d")}))
+	assert.NoError(t, err)
+	assert.Empty(t, pairs)
+}
+
+func TestScanEarlyStop(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		server.Set(streamId, []byte(key), []byte("v-"+key))
+	}
+
+	var keys []string
+	client.Scan(ctx, streamId, kv.ScanOptions{Prefetch: 1})(func(pair *node.KeyValue, err error) bool {
+		assert.NoError(t, err)
+		keys = append(keys, string(pair.Key))
+		return len(keys) < 2
+	})
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestScanError(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	server.Set(streamId, []byte("a"), []byte("v-a"))
+
+	// context cancelled
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	pairs, err := collectScan(t, client.Scan(cancelled, streamId, kv.ScanOptions{}))
+	assert.Error(t, err)
+	assert.Empty(t, pairs)
+
+	// kv node unavailable
+	server.Close()
+
+	pairs, err = collectScan(t, client.Scan(ctx, streamId, kv.ScanOptions{}))
+	assert.Error(t, err)
+	assert.Empty(t, pairs)
+}
@@ -0,0 +1,33 @@
+package kv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixUpperBound(t *testing.T) {
+	assert.Equal(t, []byte("ac"), prefixUpperBound([]byte("ab")))
+	assert.Equal(t, []byte{0x02}, prefixUpperBound([]byte{0x01, 0xff}))
+	assert.Nil(t, prefixUpperBound([]byte{0xff, 0xff}))
+}
+
+func TestScanBounds(t *testing.T) {
+	lower, upper := scanBounds(ScanOptions{})
+	assert.Nil(t, lower)
+	assert.Nil(t, upper)
+
+	lower, upper = scanBounds(ScanOptions{Start: []byte("a"), End: []byte("z")})
+	assert.Equal(t, []byte("a"), lower)
+	assert.Equal(t, []byte("z"), upper)
+
+	// prefix narrows the range
+	lower, upper = scanBounds(ScanOptions{Prefix: []byte("user/"), Start: []byte("a"), End: []byte("z")})
+	assert.Equal(t, []byte("user/"), lower)
+	assert.Equal(t, []byte("user0"), upper)
+
+	// range narrows the prefix
+	lower, upper = scanBounds(ScanOptions{Prefix: []byte("user/"), Start: []byte("user/b"), End: []byte("user/c")})
+	assert.Equal(t, []byte("user/b"), lower)
+	assert.Equal(t, []byte("user/c"), upper)
+}
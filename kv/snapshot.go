@@ -0,0 +1,351 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/web3go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SnapshotFormat is the format identifier of kv stream snapshot.
+const SnapshotFormat = "0g-kv-snapshot/v1"
+
+// defaultMaxImportBatchSize is the default max size of key-values in a single import batch.
+const defaultMaxImportBatchSize = 4 * 1024 * 1024
+
+// SnapshotRecordType is the type of snapshot record.
+type SnapshotRecordType string
+
+const (
+	SnapshotRecordKeyValue   SnapshotRecordType = "kv"         // key-value
+	SnapshotRecordAdmin      SnapshotRecordType = "admin"      // account is admin of stream
+	SnapshotRecordWriter     SnapshotRecordType = "writer"     // account is writer of stream
+	SnapshotRecordSpecialKey SnapshotRecordType = "specialKey" // key has unique access control
+	SnapshotRecordKeyWriter  SnapshotRecordType = "keyWriter"  // account is writer of special key
+)
+
+// SnapshotHeader is the first JSON value of snapshot.
+type SnapshotHeader struct {
+	Format    string      `json:"format"`
+	StreamId  common.Hash `json:"streamId"`
+	Version   uint64      `json:"version"`
+	CreatedAt int64       `json:"createdAt"`
+}
+
+// SnapshotRecord is a key-value or access control fact of stream in snapshot.
+type SnapshotRecord struct {
+	Type    SnapshotRecordType `json:"type"`
+	Key     hexutil.Bytes      `json:"key,omitempty"`
+	Value   hexutil.Bytes      `json:"value,omitempty"`
+	Version uint64             `json:"version,omitempty"` // original version of key-value
+	Account *common.Address    `json:"account,omitempty"`
+}
+
+// size returns the approximate size of record in stream data.
+func (record *SnapshotRecord) size() int {
+	return common.HashLength + 3 + len(record.Key) + 8 + len(record.Value)
+}
+
+// ExportOption is the option to export kv stream.
+type ExportOption struct {
+	// Accounts to check the admin and writer roles, since accounts of stream could not be enumerated.
+	Accounts []common.Address
+
+	// Progress is called with the number of records exported once a record exported.
+	Progress func(records int)
+}
+
+// Export writes a portable snapshot of the stream at specified version into w, which is a sequence of JSON values,
+// including a SnapshotHeader followed by SnapshotRecords of key-values and then access controls. If version is 0,
+// the latest version replayed by kv node is resolved at first, so that transactions replayed during export are
+// not included.
+//
+// Note, deleted keys, i.e. keys with empty value, are not exported.
+func Export(ctx context.Context, client *Client, streamId common.Hash, version uint64, w io.Writer, option ...ExportOption) error {
+	var opt ExportOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	// key-values and access controls are queried at the same version
+	if version == 0 {
+		next, err := client.nextPendingTxSeq(ctx, 1)
+		if err != nil {
+			return errors.WithMessage(err, "failed to get the latest version")
+		}
+
+		version = next - 1
+	}
+
+	encoder := json.NewEncoder(w)
+	var records int
+
+	write := func(v interface{}) error {
+		if err := encoder.Encode(v); err != nil {
+			return errors.WithMessage(err, "failed to write snapshot")
+		}
+
+		if _, ok := v.(*SnapshotRecord); ok {
+			records++
+
+			if opt.Progress != nil {
+				opt.Progress(records)
+			}
+		}
+
+		return nil
+	}
+
+	if err := write(&SnapshotHeader{
+		Format:    SnapshotFormat,
+		StreamId:  streamId,
+		Version:   version,
+		CreatedAt: time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+
+	var specialKeys [][]byte
+
+	// key-values
+	var scanErr error
+	client.Scan(ctx, streamId, ScanOptions{Version: version})(func(pair *node.KeyValue, err error) bool {
+		if err != nil {
+			scanErr = errors.WithMessage(err, "failed to scan stream")
+			return false
+		}
+
+		if len(pair.Data) == 0 {
+			return true
+		}
+
+		if scanErr = write(&SnapshotRecord{
+			Type:    SnapshotRecordKeyValue,
+			Key:     pair.Key,
+			Value:   pair.Data,
+			Version: pair.Version,
+		}); scanErr != nil {
+			return false
+		}
+
+		isSpecial, err := client.IsSpecialKey(ctx, streamId, pair.Key, version)
+		if err != nil {
+			scanErr = errors.WithMessagef(err, "failed to check special key %x", pair.Key)
+			return false
+		}
+
+		if isSpecial {
+			specialKeys = append(specialKeys, pair.Key)
+		}
+
+		return true
+	})
+
+	if scanErr != nil {
+		return scanErr
+	}
+
+	// access controls
+	for _, account := range opt.Accounts {
+		if err := exportAccountRoles(ctx, client, streamId, version, account, write); err != nil {
+			return errors.WithMessagef(err, "failed to export roles of account %v", account)
+		}
+	}
+
+	for _, key := range specialKeys {
+		if err := write(&SnapshotRecord{Type: SnapshotRecordSpecialKey, Key: key}); err != nil {
+			return err
+		}
+
+		for i := range opt.Accounts {
+			isWriter, err := client.IsWriterOfKey(ctx, opt.Accounts[i], streamId, key, version)
+			if err != nil {
+				return errors.WithMessagef(err, "failed to check writer of key %x", key)
+			}
+
+			if !isWriter {
+				continue
+			}
+
+			if err = write(&SnapshotRecord{Type: SnapshotRecordKeyWriter, Key: key, Account: &opt.Accounts[i]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportAccountRoles(ctx context.Context, client *Client, streamId common.Hash, version uint64, account common.Address, write func(v interface{}) error) error {
+	isAdmin, err := client.IsAdmin(ctx, account, streamId, version)
+	if err != nil {
+		return err
+	}
+
+	if isAdmin {
+		if err = write(&SnapshotRecord{Type: SnapshotRecordAdmin, Account: &account}); err != nil {
+			return err
+		}
+	}
+
+	isWriter, err := client.IsWriterOfStream(ctx, account, streamId, version)
+	if err != nil {
+		return err
+	}
+
+	if isWriter {
+		if err = write(&SnapshotRecord{Type: SnapshotRecordWriter, Account: &account}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportOption is the option to import snapshot into kv stream.
+type ImportOption struct {
+	MaxBatchSize int // max size of key-values in a single batch, default 4 MB
+	Skip         int // number of records to skip, which is used to resume import
+
+	// Progress is called with the number of records imported (including skipped) once a batch executed.
+	Progress func(records int)
+
+	UploadOption transfer.UploadOption
+	LogOption    zg_common.LogOption
+}
+
+// Import replays the snapshot exported by Export into the target stream, as a sequence of size-bounded batches.
+// Returns the number of records imported (including skipped), which could be used to resume import on failure.
+//
+// Note, the access controls are replayed after all key-values, which requires the sender to be admin of the
+// target stream.
+func Import(ctx context.Context, r io.Reader, streamId common.Hash, zgsClients []*node.ZgsClient, w3Client *web3go.Client, option ...ImportOption) (int, error) {
+	var opt ImportOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	if opt.MaxBatchSize <= 0 {
+		opt.MaxBatchSize = defaultMaxImportBatchSize
+	}
+
+	logger := zg_common.NewLogger(opt.LogOption)
+	decoder := json.NewDecoder(r)
+
+	var header SnapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return 0, errors.WithMessage(err, "failed to read snapshot header")
+	}
+
+	if header.Format != SnapshotFormat {
+		return 0, errors.Errorf("unsupported snapshot format %v", header.Format)
+	}
+
+	var imported, batchRecords, batchSize int
+	var batcher *Batcher
+
+	// version is not checked, so as to overwrite keys of the target stream
+	newBatcher := func() *Batcher {
+		return NewBatcher(math.MaxUint64, zgsClients, w3Client, opt.LogOption)
+	}
+
+	execBatch := func() error {
+		if batchRecords == 0 {
+			return nil
+		}
+
+		if _, err := batcher.Exec(ctx, opt.UploadOption); err != nil {
+			return errors.WithMessagef(err, "failed to execute batch of records [%v, %v)", imported, imported+batchRecords)
+		}
+
+		imported += batchRecords
+		batchRecords, batchSize = 0, 0
+
+		logger.WithField("records", imported).Info("Snapshot batch imported")
+
+		if opt.Progress != nil {
+			opt.Progress(imported)
+		}
+
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		var record SnapshotRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return imported, errors.WithMessagef(err, "failed to read snapshot record %v", i)
+		}
+
+		if i < opt.Skip {
+			imported++
+			continue
+		}
+
+		// exceeds the size limit
+		if batchRecords > 0 && (batchSize+record.size() > opt.MaxBatchSize || batchRecords >= maxSetSize) {
+			if err := execBatch(); err != nil {
+				return imported, err
+			}
+		}
+
+		if batchRecords == 0 {
+			batcher = newBatcher()
+		}
+
+		if err := addSnapshotRecord(batcher, streamId, &record); err != nil {
+			return imported, errors.WithMessagef(err, "invalid snapshot record %v", i)
+		}
+
+		batchRecords++
+		batchSize += record.size()
+	}
+
+	if err := execBatch(); err != nil {
+		return imported, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"stream":  streamId,
+		"records": imported,
+	}).Info("Snapshot imported")
+
+	return imported, nil
+}
+
+func addSnapshotRecord(batcher *Batcher, streamId common.Hash, record *SnapshotRecord) error {
+	switch record.Type {
+	case SnapshotRecordKeyValue:
+		batcher.Set(streamId, record.Key, record.Value)
+	case SnapshotRecordSpecialKey:
+		batcher.SetKeyToSpecial(streamId, record.Key)
+	case SnapshotRecordAdmin, SnapshotRecordWriter, SnapshotRecordKeyWriter:
+		if record.Account == nil {
+			return errors.New("account is required")
+		}
+
+		switch record.Type {
+		case SnapshotRecordAdmin:
+			batcher.GrantAdminRole(streamId, *record.Account)
+		case SnapshotRecordWriter:
+			batcher.GrantWriteRole(streamId, *record.Account)
+		default:
+			batcher.GrantSpecialWriteRole(streamId, record.Key, *record.Account)
+		}
+	default:
+		return errors.Errorf("unknown record type %v", record.Type)
+	}
+
+	return nil
+}
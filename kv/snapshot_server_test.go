@@ -0,0 +1,88 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	admin := common.HexToAddress("0xa1")
+	writer := common.HexToAddress("0xa2")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.Set(streamId, []byte("a"), []byte("1"))
+	batcher.Set(streamId, []byte("b"), []byte("2"))
+	batcher.Set(streamId, []byte("c"), []byte{})
+	version := applyBatch(t, server, admin, batcher)
+
+	batcher = kv.NewBatcher(math.MaxUint64, nil, nil)
+	batcher.GrantWriteRole(streamId, writer)
+	batcher.SetKeyToSpecial(streamId, []byte("b"))
+	batcher.GrantSpecialWriteRole(streamId, []byte("b"), writer)
+	latest := applyBatch(t, server, admin, batcher)
+
+	// transaction replayed during export is not included
+	var buf bytes.Buffer
+	assert.NoError(t, kv.Export(ctx, client, streamId, 0, &buf, kv.ExportOption{
+		Accounts: []common.Address{admin, writer},
+		Progress: func(records int) {
+			if records > 1 {
+				return
+			}
+
+			batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+			batcher.Set(streamId, []byte("b0"), []byte("3"))
+			batcher.RevokeWriteRole(streamId, writer)
+			batcher.SetKeyToNormal(streamId, []byte("b"))
+			applyBatch(t, server, admin, batcher)
+		},
+	}))
+
+	decoder := json.NewDecoder(&buf)
+
+	var header kv.SnapshotHeader
+	assert.NoError(t, decoder.Decode(&header))
+	assert.Equal(t, kv.SnapshotFormat, header.Format)
+	assert.Equal(t, streamId, header.StreamId)
+	assert.Equal(t, latest, header.Version)
+
+	var records []kv.SnapshotRecord
+	for {
+		var record kv.SnapshotRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else {
+			assert.NoError(t, err)
+		}
+
+		records = append(records, record)
+	}
+
+	// deleted keys excluded, and access controls at the latest version
+	assert.Equal(t, []kv.SnapshotRecord{
+		{Type: kv.SnapshotRecordKeyValue, Key: []byte("a"), Value: []byte("1"), Version: version},
+		{Type: kv.SnapshotRecordKeyValue, Key: []byte("b"), Value: []byte("2"), Version: version},
+		{Type: kv.SnapshotRecordAdmin, Account: &admin},
+		{Type: kv.SnapshotRecordWriter, Account: &writer},
+		{Type: kv.SnapshotRecordSpecialKey, Key: []byte("b")},
+		{Type: kv.SnapshotRecordKeyWriter, Key: []byte("b"), Account: &writer},
+	}, records)
+}
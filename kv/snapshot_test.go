@@ -0,0 +1,41 @@
+package kv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRecord(t *testing.T) {
+	account := common.HexToAddress("0x02")
+	record := SnapshotRecord{Type: SnapshotRecordKeyWriter, Key: []byte("key"), Account: &account}
+
+	encoded, err := json.Marshal(&record)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"keyWriter","key":"0x6b6579","account":"0x0000000000000000000000000000000000000002"}`, string(encoded))
+
+	var decoded SnapshotRecord
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, record, decoded)
+}
+
+func TestAddSnapshotRecord(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+	account := common.HexToAddress("0x02")
+	batcher := NewBatcher(0, nil, nil)
+
+	assert.NoError(t, addSnapshotRecord(batcher, streamId, &SnapshotRecord{Type: SnapshotRecordKeyValue, Key: []byte("k"), Value: []byte("v")}))
+	assert.NoError(t, addSnapshotRecord(batcher, streamId, &SnapshotRecord{Type: SnapshotRecordWriter, Account: &account}))
+	assert.NoError(t, addSnapshotRecord(batcher, streamId, &SnapshotRecord{Type: SnapshotRecordSpecialKey, Key: []byte("k")}))
+	assert.Error(t, addSnapshotRecord(batcher, streamId, &SnapshotRecord{Type: SnapshotRecordAdmin}))
+	assert.Error(t, addSnapshotRecord(batcher, streamId, &SnapshotRecord{Type: "unknown"}))
+
+	data, err := batcher.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(data.Writes))
+	assert.Equal(t, 2, len(data.Controls))
+	assert.Equal(t, aclTypeGrantWriteRole, data.Controls[0].Type)
+	assert.Equal(t, aclTypeSetKeyToSpecial, data.Controls[1].Type)
+}
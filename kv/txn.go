@@ -0,0 +1,214 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/0glabs/0g-storage-client/transfer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/web3go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TxnOption is the option to execute an optimistic kv transaction.
+type TxnOption struct {
+	MaxRetries    int           // max number of retries on conflict, default 5
+	RetryInterval time.Duration // initial backoff interval on conflict, which is doubled for each retry, default 1 second
+	PollInterval  time.Duration // interval to poll transaction result, default 1 second
+
+	UploadOption transfer.UploadOption
+	LogOption    zg_common.LogOption
+}
+
+// Tx records reads with their versions and caches writes of an optimistic kv transaction.
+type Tx struct {
+	client  *Client
+	batcher *Batcher
+	reads   map[common.Hash]map[string]uint64 // stream id -> hex encoded key -> version
+}
+
+func newTx(client *Client, batcher *Batcher) *Tx {
+	// writes are encrypted with the same keyring that values read are decrypted
+	batcher.SetKeyring(client.option.Keyring)
+
+	return &Tx{
+		client:  client,
+		batcher: batcher,
+		reads:   make(map[common.Hash]map[string]uint64),
+	}
+}
+
+// Batcher returns the underlying batcher to cache other operations, e.g. access control or Map.Put.
+func (tx *Tx) Batcher() *Batcher {
+	return tx.batcher
+}
+
+// Get reads the latest value of given key, and adds the key into read set. It returns nil if key not found.
+func (tx *Tx) Get(ctx context.Context, streamId common.Hash, key []byte) (*node.Value, error) {
+	val, err := tx.client.GetValue(ctx, streamId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var version uint64
+	if val != nil {
+		version = val.Version
+	}
+
+	if _, ok := tx.reads[streamId]; !ok {
+		tx.reads[streamId] = make(map[string]uint64)
+	}
+	tx.reads[streamId][hexutil.Encode(key)] = version
+
+	tx.batcher.Watch(streamId, key)
+
+	return val, nil
+}
+
+// Set caches a write key operation.
+func (tx *Tx) Set(streamId common.Hash, key []byte, data []byte) {
+	tx.batcher.Set(streamId, key, data)
+}
+
+// keyVersion returns the latest version of given key, or 0 if key not found. Note, the key is resolved to the
+// stored key if key hashing enabled for the stream.
+func (tx *Tx) keyVersion(ctx context.Context, streamId common.Hash, key []byte) (uint64, error) {
+	storedKey := tx.client.option.Keyring.storedKey(streamId, key)
+
+	val, err := tx.client.get(ctx, streamId, storedKey, 0, 0)
+	if err != nil || val == nil {
+		return 0, err
+	}
+
+	return val.Version, nil
+}
+
+// prepare validates the versions of read keys, and returns the transaction version, which is the max
+// version of all read and written keys. It returns ErrTxConflict if any read key updated.
+func (tx *Tx) prepare(ctx context.Context) (uint64, error) {
+	var version uint64
+
+	for streamId, keys := range tx.batcher.reads {
+		for k := range keys {
+			latest, err := tx.keyVersion(ctx, streamId, hexutil.MustDecode(k))
+			if err != nil {
+				return 0, errors.WithMessagef(err, "failed to get version of key %v", k)
+			}
+
+			if read, ok := tx.reads[streamId][k]; ok && read != latest {
+				return 0, errors.WithMessagef(ErrTxConflict, "key %v updated after read", k)
+			}
+
+			version = max(version, latest)
+		}
+	}
+
+	for streamId, keys := range tx.batcher.writes {
+		for k := range keys {
+			latest, err := tx.keyVersion(ctx, streamId, hexutil.MustDecode(k))
+			if err != nil {
+				return 0, errors.WithMessagef(err, "failed to get version of key %v", k)
+			}
+
+			version = max(version, latest)
+		}
+	}
+
+	return version, nil
+}
+
+// Txn executes fn in an optimistic kv transaction. Keys read by Tx are validated against their versions
+// when the transaction is settled on chain, and fn will be retried with backoff on conflict.
+//
+// Note, fn may be executed for multiple times, and should not have side effects other than Tx.
+func Txn(ctx context.Context, client *Client, zgsClients []*node.ZgsClient, w3Client *web3go.Client, fn func(tx *Tx) error, option ...TxnOption) (*TxResult, error) {
+	var opt TxnOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	newBatcher := func() *Batcher {
+		return NewBatcher(0, zgsClients, w3Client, opt.LogOption)
+	}
+
+	submit := func(ctx context.Context, batcher *Batcher) (uint64, error) {
+		// always submit a new log entry to get the transaction sequence
+		uploadOpt := opt.UploadOption
+		uploadOpt.SkipTx = false
+
+		txHash, uploader, err := batcher.exec(ctx, uploadOpt)
+		if err != nil {
+			return 0, err
+		}
+
+		return batcher.txSeqOf(ctx, uploader, txHash)
+	}
+
+	return txn(ctx, client, fn, opt, newBatcher, submit)
+}
+
+// txn executes fn with retries on conflict, in which batcher is created by newBatcher for each execution,
+// and submitted by submit to get the transaction sequence.
+func txn(ctx context.Context, client *Client, fn func(tx *Tx) error, opt TxnOption, newBatcher func() *Batcher,
+	submit func(ctx context.Context, batcher *Batcher) (uint64, error)) (*TxResult, error) {
+	if opt.MaxRetries <= 0 {
+		opt.MaxRetries = 5
+	}
+
+	if opt.RetryInterval <= 0 {
+		opt.RetryInterval = time.Second
+	}
+
+	logger := zg_common.NewLogger(opt.LogOption)
+	interval := opt.RetryInterval
+
+	for i := 0; ; i++ {
+		result, err := txnOnce(ctx, client, newTx(client, newBatcher()), fn, opt, submit)
+		if !errors.Is(err, ErrTxConflict) || i >= opt.MaxRetries {
+			return result, err
+		}
+
+		logger.WithError(err).WithFields(logrus.Fields{
+			"retry":    i + 1,
+			"interval": interval,
+		}).Debug("Retry kv transaction on conflict")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+	}
+}
+
+func txnOnce(ctx context.Context, client *Client, tx *Tx, fn func(tx *Tx) error, opt TxnOption,
+	submit func(ctx context.Context, batcher *Batcher) (uint64, error)) (*TxResult, error) {
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+
+	version, err := tx.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.batcher.SetVersion(version)
+
+	txSeq, err := submit(ctx, tx.batcher)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.WaitTxResult(ctx, txSeq, opt.PollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, result.Err()
+}
@@ -0,0 +1,181 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyBatch(t *testing.T, server *kvtest.Server, sender common.Address, batcher *kv.Batcher) uint64 {
+	data, err := batcher.Build()
+	assert.NoError(t, err)
+
+	encoded, err := data.Encode()
+	assert.NoError(t, err)
+
+	txSeq, result := server.Apply(sender, encoded)
+	assert.Equal(t, "Commit", result)
+
+	return txSeq
+}
+
+func TestTxPrepareWithKeyHashing(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	admin := common.HexToAddress("0xa1")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+
+	keyring := kv.NewKeyring()
+	assert.NoError(t, keyring.AddKey(streamId, 1, bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, keyring.SetKeyHashing(streamId, []byte("secret")))
+
+	write := func(key, value string) uint64 {
+		batcher := kv.NewBatcher(math.MaxUint64, nil, nil)
+		batcher.SetKeyring(keyring)
+		batcher.Set(streamId, []byte(key), []byte(value))
+		return applyBatch(t, server, admin, batcher)
+	}
+
+	write("b", "2")
+	seqA := write("a", "1")
+	assert.NotZero(t, seqA)
+
+	client := kv.NewClient(kvClient).WithKeyring(keyring)
+
+	tx := kv.NewTestTx(client)
+	val, err := tx.Get(ctx, streamId, []byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, seqA, val.Version)
+	assert.Equal(t, []byte("1"), val.Data)
+	tx.Set(streamId, []byte("b"), []byte("3"))
+
+	// versions of hashed keys resolved
+	version, err := tx.Prepare(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, seqA, version)
+
+	// conflict if read key updated
+	write("a", "4")
+	_, err = tx.Prepare(ctx)
+	assert.True(t, errors.Is(err, kv.ErrTxConflict))
+}
+
+func TestTxnRetryOnConflict(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	sender := common.HexToAddress("0xa1")
+	key := []byte("counter")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	// sender becomes admin of new stream
+	init := kv.NewBatcher(math.MaxUint64, nil, nil)
+	init.Set(streamId, key, []byte{0})
+	applyBatch(t, server, sender, init)
+
+	// increments counter with concurrent updates
+	increment := func(conflicts *int) func(tx *kv.Tx) error {
+		return func(tx *kv.Tx) error {
+			val, err := tx.Get(ctx, streamId, key)
+			if err != nil {
+				return err
+			}
+
+			tx.Set(streamId, key, []byte{val.Data[0] + 1})
+
+			if *conflicts > 0 {
+				*conflicts--
+				server.Set(streamId, key, []byte{val.Data[0] + 10})
+			}
+
+			return nil
+		}
+	}
+
+	var submitted int
+	submit := func(encoded []byte) uint64 {
+		submitted++
+		txSeq, _ := server.Apply(sender, encoded)
+		return txSeq
+	}
+
+	opt := kv.TxnOption{MaxRetries: 3, RetryInterval: time.Millisecond, PollInterval: time.Millisecond}
+
+	// conflicts detected before submission are retried
+	conflicts := 2
+	result, err := kv.TxnWith(ctx, client, increment(&conflicts), opt, submit)
+	assert.NoError(t, err)
+	assert.Equal(t, kv.TxStatusCommitted, result.Status)
+	assert.Equal(t, 1, submitted)
+
+	val, err := client.GetValue(ctx, streamId, key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{21}, val.Data)
+
+	// conflicts on replay are resubmitted
+	submitted = 0
+	var updates int
+	result, err = kv.TxnWith(ctx, client, increment(new(int)), opt, func(encoded []byte) uint64 {
+		if updates < 2 {
+			updates++
+			server.Set(streamId, key, []byte{100})
+		}
+
+		return submit(encoded)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, kv.TxStatusCommitted, result.Status)
+	assert.Equal(t, 3, submitted)
+
+	val, err = client.GetValue(ctx, streamId, key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{101}, val.Data)
+
+	// stops after max retries
+	var calls int
+	conflicts = 100
+	fn := increment(&conflicts)
+	_, err = kv.TxnWith(ctx, client, func(tx *kv.Tx) error {
+		calls++
+		return fn(tx)
+	}, opt, submit)
+	assert.True(t, errors.Is(err, kv.ErrTxConflict))
+	assert.Equal(t, opt.MaxRetries+1, calls)
+
+	submitted = 0
+	result, err = kv.TxnWith(ctx, client, increment(new(int)), opt, func(encoded []byte) uint64 {
+		server.Set(streamId, key, []byte{100})
+		return submit(encoded)
+	})
+	assert.True(t, errors.Is(err, kv.ErrTxConflict))
+	assert.Equal(t, kv.TxStatusConflict, result.Status)
+	assert.Equal(t, opt.MaxRetries+1, submitted)
+
+	// other errors are not retried
+	calls = 0
+	fnErr := errors.New("fn failed")
+	_, err = kv.TxnWith(ctx, client, func(tx *kv.Tx) error {
+		calls++
+		return fnErr
+	}, opt, submit)
+	assert.Equal(t, fnErr, err)
+	assert.Equal(t, 1, calls)
+}
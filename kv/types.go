@@ -1,10 +1,17 @@
 package kv
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
 
+	"github.com/0glabs/0g-storage-client/core"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 )
 
 // df2ff3bb0af36c6384e6206552a4ed807f6f6a26e7d0aa6bff772ddc9d4307aa
@@ -44,6 +51,48 @@ const (
 	aclTypeRenounceSpecialWriteRole accessControlType = 0x32
 )
 
+var accessControlTypeNames = map[accessControlType]string{
+	aclTypeGrantAdminRole:           "GrantAdminRole",
+	aclTypeRenounceAdminRole:        "RenounceAdminRole",
+	aclTypeSetKeyToSpecial:          "SetKeyToSpecial",
+	aclTypeSetKeyToNormal:           "SetKeyToNormal",
+	aclTypeGrantWriteRole:           "GrantWriteRole",
+	aclTypeRevokeWriteRole:          "RevokeWriteRole",
+	aclTypeRenounceWriteRole:        "RenounceWriteRole",
+	aclTypeGrantSpecialWriteRole:    "GrantSpecialWriteRole",
+	aclTypeRevokeSpecialWriteRole:   "RevokeSpecialWriteRole",
+	aclTypeRenounceSpecialWriteRole: "RenounceSpecialWriteRole",
+}
+
+// String implements the fmt.Stringer interface.
+func (t accessControlType) String() string {
+	if name, ok := accessControlTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Unknown(%#x)", uint8(t))
+}
+
+func (t accessControlType) valid() bool {
+	_, ok := accessControlTypeNames[t]
+	return ok
+}
+
+// hasKey returns whether the access control operation is about a special key.
+func (t accessControlType) hasKey() bool {
+	return t == aclTypeSetKeyToSpecial || t == aclTypeSetKeyToNormal || t >= aclTypeGrantSpecialWriteRole
+}
+
+// hasAccount returns whether the access control operation is applied to another account.
+func (t accessControlType) hasAccount() bool {
+	switch t {
+	case aclTypeGrantAdminRole, aclTypeGrantWriteRole, aclTypeRevokeWriteRole, aclTypeGrantSpecialWriteRole, aclTypeRevokeSpecialWriteRole:
+		return true
+	default:
+		return false
+	}
+}
+
 type streamRead struct {
 	StreamId common.Hash
 	Key      []byte
@@ -53,6 +102,19 @@ type streamWrite struct {
 	StreamId common.Hash
 	Key      []byte
 	Data     []byte
+
+	// value is read from reader on demand if not nil
+	reader io.ReaderAt
+	size   int64
+}
+
+// dataSize returns the size of value to write.
+func (w *streamWrite) dataSize() int {
+	if w.reader != nil {
+		return int(w.size)
+	}
+
+	return len(w.Data)
 }
 
 type accessControl struct {
@@ -84,7 +146,7 @@ func (sd *StreamData) Size() int {
 	// writes
 	size += 4 // size
 	for _, v := range sd.Writes {
-		size += common.HashLength + 3 + len(v.Key) + 8 + len(v.Data)
+		size += common.HashLength + 3 + len(v.Key) + 8 + v.dataSize()
 	}
 
 	// acls
@@ -128,12 +190,64 @@ func (sd *StreamData) encodeSize64(size int) []byte {
 	return buf[:]
 }
 
+// Encode serializes the stream data into bytes, including values to read from readers.
 func (sd *StreamData) Encode() ([]byte, error) {
-	// pre-allocate memory and init length for version
-	encoded := make([]byte, 8, sd.Size())
+	// pre-allocate memory
+	encoded := make([]byte, 0, sd.Size())
+
+	encoded, err := sd.encodeHead(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range sd.Writes {
+		if v.reader == nil {
+			encoded = append(encoded, v.Data...)
+			continue
+		}
+
+		offset := len(encoded)
+		encoded = slices.Grow(encoded, v.dataSize())[:offset+v.dataSize()]
+		if _, err = io.ReadFull(io.NewSectionReader(v.reader, 0, v.size), encoded[offset:]); err != nil {
+			return nil, errors.WithMessagef(err, "failed to read value of key %x", v.Key)
+		}
+	}
+
+	return sd.encodeTail(encoded)
+}
+
+// encodeData serializes the stream data as IterableData, in which values of readers are read on demand
+// instead of buffered in memory.
+func (sd *StreamData) encodeData() (core.IterableData, error) {
+	head, err := sd.encodeHead(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []core.ReaderRange{{Reader: bytes.NewReader(head), Size: int64(len(head))}}
+
+	for _, v := range sd.Writes {
+		if v.reader == nil {
+			ranges = append(ranges, core.ReaderRange{Reader: bytes.NewReader(v.Data), Size: int64(len(v.Data))})
+		} else {
+			ranges = append(ranges, core.ReaderRange{Reader: v.reader, Size: v.size})
+		}
+	}
 
+	tail, err := sd.encodeTail(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges = append(ranges, core.ReaderRange{Reader: bytes.NewReader(tail), Size: int64(len(tail))})
+
+	return core.NewMultiReaderData(ranges...)
+}
+
+// encodeHead serializes the version, reads and metadata of writes.
+func (sd *StreamData) encodeHead(encoded []byte) ([]byte, error) {
 	// version
-	binary.BigEndian.PutUint64(encoded[:8], sd.Version)
+	encoded = binary.BigEndian.AppendUint64(encoded, sd.Version)
 
 	// reads
 	encoded = append(encoded, sd.encodeSize32(len(sd.Reads))...)
@@ -157,13 +271,14 @@ func (sd *StreamData) Encode() ([]byte, error) {
 		}
 		encoded = append(encoded, keySize...)
 		encoded = append(encoded, v.Key...)
-		encoded = append(encoded, sd.encodeSize64(len(v.Data))...)
+		encoded = append(encoded, sd.encodeSize64(v.dataSize())...)
 	}
 
-	for _, v := range sd.Writes {
-		encoded = append(encoded, v.Data...)
-	}
+	return encoded, nil
+}
 
+// encodeTail serializes the access controls.
+func (sd *StreamData) encodeTail(encoded []byte) ([]byte, error) {
 	// acls
 	encoded = append(encoded, sd.encodeSize32(len(sd.Controls))...)
 	for _, v := range sd.Controls {
@@ -186,3 +301,65 @@ func (sd *StreamData) Encode() ([]byte, error) {
 
 	return encoded, nil
 }
+
+// checkSize checks that reads, writes and access controls do not exceed the max set size.
+func (sd *StreamData) checkSize() error {
+	if len(sd.Reads) > maxSetSize || len(sd.Writes) > maxSetSize || len(sd.Controls) > maxSetSize {
+		return errSizeTooLarge
+	}
+
+	return nil
+}
+
+// streamIds returns the sorted stream ids of writes and access controls, which are used to build tags.
+func (sd *StreamData) streamIds() []common.Hash {
+	set := make(map[common.Hash]bool)
+	for _, v := range sd.Writes {
+		set[v.StreamId] = true
+	}
+	for _, v := range sd.Controls {
+		set[v.StreamId] = true
+	}
+
+	var ids []common.Hash
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return ids[i].Hex() < ids[j].Hex()
+	})
+
+	return ids
+}
+
+// split splits the stream data into ordered parts, each of which does not exceed the max set size. Reads
+// are only included in the first part, since the read set could not be split without breaking the version
+// check. Access controls are placed in the leading parts in order, so that roles granted take effect for
+// writes in later parts.
+func (sd *StreamData) split(maxSize int) ([]*StreamData, error) {
+	if len(sd.Reads) > maxSize {
+		return nil, ErrReadSetTooLarge
+	}
+
+	var parts []*StreamData
+	writes, controls := sd.Writes, sd.Controls
+
+	for len(parts) == 0 || len(writes) > 0 || len(controls) > 0 {
+		part := StreamData{Version: sd.Version}
+
+		if len(parts) == 0 {
+			part.Reads = sd.Reads
+		}
+
+		n := min(maxSize, len(controls))
+		part.Controls, controls = controls[:n], controls[n:]
+
+		n = min(maxSize, len(writes))
+		part.Writes, writes = writes[:n], writes[n:]
+
+		parts = append(parts, &part)
+	}
+
+	return parts, nil
+}
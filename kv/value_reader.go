@@ -0,0 +1,146 @@
+package kv
+
+import (
+	"context"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrValueNotFound       = errors.New("value not found")
+	ErrValueVersionChanged = errors.New("value version changed during read")
+)
+
+// ValueReader reads value of a key page by page on demand, which is pinned to the version when opened.
+type ValueReader struct {
+	ctx      context.Context
+	client   *Client
+	streamId common.Hash
+	key      []byte
+	version  uint64
+	latest   bool // whether opened at the latest version, so that the value may be updated during read
+	size     int64
+
+	offset int64 // current read offset
+
+	page       []byte // cached page of value
+	pageOffset int64  // offset of the cached page
+}
+
+var _ io.ReadSeekCloser = (*ValueReader)(nil)
+
+// OpenValue opens the value of given key at the latest or specified version to read lazily, which is suitable
+// for large values. It returns ErrValueNotFound if key not found. If opened at the latest version, the reader
+// fails with ErrValueVersionChanged if the value is updated during read.
+//
+// Encrypted value could only be decrypted as a whole, so it returns ErrEncryptedStream if the client has
+// keyring of the stream, use GetValue instead.
+func (c *Client) OpenValue(ctx context.Context, streamId common.Hash, key []byte, version ...uint64) (*ValueReader, error) {
+	if c.option.Keyring.Encrypted(streamId) {
+		return nil, errors.WithMessage(ErrEncryptedStream, "value could not be read lazily, use GetValue instead")
+	}
+
+	val, err := c.get(ctx, streamId, key, 0, maxQuerySize, version...)
+	if err != nil {
+		return nil, err
+	}
+
+	if val == nil {
+		return nil, ErrValueNotFound
+	}
+
+	return &ValueReader{
+		ctx:      ctx,
+		client:   c,
+		streamId: streamId,
+		key:      key,
+		version:  val.Version,
+		latest:   len(version) == 0,
+		size:     int64(val.Size),
+		page:     val.Data,
+	}, nil
+}
+
+// Version returns the version of value.
+func (r *ValueReader) Version() uint64 {
+	return r.version
+}
+
+// Size returns the total size of value.
+func (r *ValueReader) Size() int64 {
+	return r.size
+}
+
+// Read implements the io.Reader interface.
+func (r *ValueReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	// load page that covers the current offset
+	if r.offset < r.pageOffset || r.offset >= r.pageOffset+int64(len(r.page)) {
+		if err := r.loadPage(r.offset); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.page[r.offset-r.pageOffset:])
+	r.offset += int64(n)
+
+	return n, nil
+}
+
+func (r *ValueReader) loadPage(offset int64) error {
+	// query the latest version to detect value updated during read
+	var version []uint64
+	if !r.latest {
+		version = append(version, r.version)
+	}
+
+	val, err := r.client.get(r.ctx, r.streamId, r.key, uint64(offset), maxQuerySize, version...)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to read value at offset %v", offset)
+	}
+
+	if val == nil || val.Version != r.version || int64(val.Size) != r.size {
+		return ErrValueVersionChanged
+	}
+
+	if len(val.Data) == 0 {
+		return errors.Errorf("unexpected empty data at offset %v", offset)
+	}
+
+	r.page = val.Data
+	r.pageOffset = offset
+
+	return nil
+}
+
+// Seek implements the io.Seeker interface.
+func (r *ValueReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	r.offset = offset
+
+	return offset, nil
+}
+
+// Close implements the io.Closer interface.
+func (r *ValueReader) Close() error {
+	r.page = nil
+	return nil
+}
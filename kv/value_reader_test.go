@@ -0,0 +1,54 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueReaderVersionChanged(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+	key := []byte("large")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	// larger than a single query
+	value := bytes.Repeat([]byte("0123456789"), 100_000)
+	version := server.Set(streamId, key, value)
+
+	reader, err := client.OpenValue(ctx, streamId, key)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(value)), reader.Size())
+
+	pinned, err := client.OpenValue(ctx, streamId, key, version)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	_, err = io.ReadFull(reader, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, value[:1024], buf)
+
+	server.Set(streamId, key, bytes.Repeat([]byte("x"), len(value)))
+
+	// value updated during read
+	_, err = io.ReadAll(reader)
+	assert.True(t, errors.Is(err, kv.ErrValueVersionChanged))
+
+	// reader opened at the specified version is not affected
+	data, err := io.ReadAll(pinned)
+	assert.NoError(t, err)
+	assert.Equal(t, value, data)
+}
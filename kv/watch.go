@@ -0,0 +1,267 @@
+package kv
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/node"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// WatchOption is the option to watch changes of kv stream.
+type WatchOption struct {
+	Keys   [][]byte // keys to watch, which are required if keys of encrypted stream hashed
+	Prefix []byte   // watch all keys with prefix if Keys not specified, or the whole stream if empty
+
+	PollInterval time.Duration // interval to poll key versions, default 3 seconds
+	KeysOnly     bool          // only emit versions without values
+	Buffer       int           // buffer size of change channel
+
+	// FromVersion is used to resume watching after restart, e.g. the version of last handled change, and
+	// changes of keys updated after this version will be emitted at first. If nil, only changes from now
+	// on will be emitted.
+	FromVersion *uint64
+
+	// OnError is called if failed to poll or emit changes, which will be retried in the next poll.
+	OnError func(err error)
+
+	LogOption zg_common.LogOption
+}
+
+// Change is a change event of key.
+type Change struct {
+	Key        []byte
+	OldVersion uint64 // 0 if key not exists or unknown before
+	NewVersion uint64
+	Value      []byte // nil if KeysOnly
+	Deleted    bool   // whether value is set to empty
+}
+
+// keyState is the version and size of a key.
+type keyState struct {
+	key     []byte
+	version uint64
+	size    uint64
+}
+
+// Watch polls the versions of keys periodically, and emits change events of keys in ascending order of
+// versions. Changes between two polls are coalesced, i.e. only the latest value of a key is emitted, and
+// so are the changes when the receiver is slow. The returned channel is closed when the context is done.
+//
+// To avoid scanning keys in vain, keys are polled only if any new transaction replayed by kv node since
+// the last poll, which is detected by the transaction results.
+func (c *Client) Watch(ctx context.Context, streamId common.Hash, option ...WatchOption) <-chan Change {
+	var opt WatchOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = 3 * time.Second
+	}
+
+	changes := make(chan Change, opt.Buffer)
+
+	go c.watch(ctx, streamId, opt, changes)
+
+	return changes
+}
+
+func (c *Client) watch(ctx context.Context, streamId common.Hash, opt WatchOption, changes chan<- Change) {
+	defer close(changes)
+
+	logger := zg_common.NewLogger(opt.LogOption)
+	ticker := time.NewTicker(opt.PollInterval)
+	defer ticker.Stop()
+
+	onError := func(err error, msg string) {
+		logger.WithError(err).WithField("stream", streamId).Warn(msg)
+
+		if opt.OnError != nil {
+			opt.OnError(errors.WithMessage(err, msg))
+		}
+	}
+
+	// hex encoded key -> version, nil before the first poll
+	var versions map[string]uint64
+
+	// the first tx seq not replayed when keys polled last time, and tx seq 0 is skipped since versions
+	// of keys start from 1
+	var pending uint64 = 1
+
+	for {
+		// tx seq is probed before keys polled, so that transactions replayed during poll will not be missed
+		next, err := c.nextPendingTxSeq(ctx, pending)
+		if err != nil {
+			onError(err, "Failed to poll transaction results")
+		} else if versions == nil || next > pending {
+			current, err := c.pollKeyStates(ctx, streamId, opt)
+			if err != nil {
+				onError(err, "Failed to poll key versions")
+			} else if versions, err = c.emitChanges(ctx, streamId, opt, versions, current, changes); err != nil {
+				onError(err, "Failed to emit changes")
+			} else {
+				pending = next
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// nextPendingTxSeq returns the first tx seq not replayed by kv node since the specified one, which should
+// be replayed or 1. It is searched exponentially, so that O(log n) queries are required for n transactions.
+func (c *Client) nextPendingTxSeq(ctx context.Context, from uint64) (uint64, error) {
+	replayed := func(txSeq uint64) (bool, error) {
+		result, err := c.GetTxResult(ctx, txSeq)
+		if err != nil {
+			return false, errors.WithMessagef(err, "failed to get result of tx %v", txSeq)
+		}
+
+		return result.Status != TxStatusPending, nil
+	}
+
+	// transactions before lo are replayed, and hi is not replayed
+	lo, hi := from, from
+	for step := uint64(1); ; step *= 2 {
+		ok, err := replayed(hi)
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			break
+		}
+
+		lo, hi = hi+1, hi+step
+	}
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		ok, err := replayed(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if ok {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
+// pollKeyStates returns the states of watched keys, in which the not found keys are excluded.
+func (c *Client) pollKeyStates(ctx context.Context, streamId common.Hash, opt WatchOption) ([]keyState, error) {
+	var states []keyState
+
+	if len(opt.Keys) > 0 {
+		for _, key := range opt.Keys {
+			val, err := c.get(ctx, streamId, c.option.Keyring.storedKey(streamId, key), 0, 0)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to get version of key %x", key)
+			}
+
+			if val != nil {
+				states = append(states, keyState{key, val.Version, val.Size})
+			}
+		}
+
+		return states, nil
+	}
+
+	// hashed keys could not be matched by prefix, nor be recovered without values
+	if c.option.Keyring.keysHashed(streamId) {
+		return nil, errors.WithMessage(ErrEncryptedStream, "keys to watch should be specified if keys hashed")
+	}
+
+	var scanErr error
+	c.Scan(ctx, streamId, ScanOptions{Prefix: opt.Prefix, KeysOnly: true})(func(pair *node.KeyValue, err error) bool {
+		if err != nil {
+			scanErr = err
+			return false
+		}
+
+		states = append(states, keyState{pair.Key, pair.Version, pair.Size})
+
+		return true
+	})
+
+	return states, scanErr
+}
+
+// emitChanges emits changes of keys, and returns the updated versions of keys.
+func (c *Client) emitChanges(ctx context.Context, streamId common.Hash, opt WatchOption, versions map[string]uint64, current []keyState, changes chan<- Change) (map[string]uint64, error) {
+	// baseline for the first poll, which includes keys not updated after FromVersion when resuming
+	if versions == nil {
+		versions = make(map[string]uint64)
+
+		for _, state := range current {
+			if opt.FromVersion == nil || state.version <= *opt.FromVersion {
+				versions[hexutil.Encode(state.key)] = state.version
+			}
+		}
+	}
+
+	for _, change := range diffKeyStates(versions, current) {
+		if !opt.KeysOnly && !change.Deleted {
+			val, err := c.GetValue(ctx, streamId, change.Key, change.NewVersion)
+			if err != nil {
+				return versions, errors.WithMessagef(err, "failed to get value of key %x", change.Key)
+			}
+
+			// value is deleted or updated again, which will be emitted in the next poll
+			if val == nil || val.Version != change.NewVersion {
+				continue
+			}
+
+			change.Value = val.Data
+		}
+
+		select {
+		case <-ctx.Done():
+			return versions, ctx.Err()
+		case changes <- change:
+		}
+
+		versions[hexutil.Encode(change.Key)] = change.NewVersion
+	}
+
+	return versions, nil
+}
+
+// diffKeyStates returns the changes of keys in ascending order of versions.
+func diffKeyStates(versions map[string]uint64, current []keyState) []Change {
+	var changes []Change
+
+	for _, state := range current {
+		old, ok := versions[hexutil.Encode(state.key)]
+		if ok && old == state.version {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Key:        state.key,
+			OldVersion: old,
+			NewVersion: state.version,
+			Deleted:    state.size == 0,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].NewVersion < changes[j].NewVersion
+	})
+
+	return changes
+}
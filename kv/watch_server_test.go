@@ -0,0 +1,109 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/kv"
+	"github.com/0glabs/0g-storage-client/kv/kvtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextPendingTxSeq(t *testing.T) {
+	ctx := context.Background()
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	next, err := client.NextPendingTxSeq(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+
+	for i := 0; i < 10; i++ {
+		server.Set(streamId, []byte("a"), []byte("1"))
+	}
+
+	for _, from := range []uint64{1, 5, 10, 11} {
+		next, err = client.NextPendingTxSeq(ctx, from)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(11), next)
+	}
+}
+
+func TestWatchPrefix(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+	client := kv.NewClient(kvClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	from := server.Set(streamId, []byte("a/1"), []byte("1"))
+	changes := client.Watch(ctx, streamId, kv.WatchOption{
+		Prefix:       []byte("a/"),
+		PollInterval: 10 * time.Millisecond,
+		FromVersion:  &from,
+	})
+
+	server.Set(streamId, []byte("b/1"), []byte("2"))
+	version := server.Set(streamId, []byte("a/2"), []byte("3"))
+
+	change := <-changes
+	assert.Equal(t, []byte("a/2"), change.Key)
+	assert.Equal(t, version, change.NewVersion)
+	assert.Equal(t, []byte("3"), change.Value)
+
+	version = server.Set(streamId, []byte("a/1"), []byte{})
+
+	change = <-changes
+	assert.Equal(t, []byte("a/1"), change.Key)
+	assert.Equal(t, from, change.OldVersion)
+	assert.Equal(t, version, change.NewVersion)
+	assert.True(t, change.Deleted)
+}
+
+func TestWatchError(t *testing.T) {
+	streamId := common.HexToHash("0x01")
+
+	server := kvtest.NewServer()
+	defer server.Close()
+
+	kvClient := server.MustNewClient()
+	defer kvClient.Close()
+
+	keyring := kv.NewKeyring()
+	assert.NoError(t, keyring.AddKey(streamId, 1, bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, keyring.SetKeyHashing(streamId, []byte("secret")))
+	client := kv.NewClient(kvClient).WithKeyring(keyring)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// keys to watch required if keys hashed
+	errs := make(chan error, 1)
+	client.Watch(ctx, streamId, kv.WatchOption{
+		PollInterval: 10 * time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+
+	assert.True(t, errors.Is(<-errs, kv.ErrEncryptedStream))
+}
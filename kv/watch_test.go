@@ -0,0 +1,38 @@
+package kv
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffKeyStates(t *testing.T) {
+	current := []keyState{
+		{[]byte("a"), 5, 10},
+		{[]byte("b"), 3, 0},
+		{[]byte("c"), 7, 1},
+		{[]byte("d"), 2, 1},
+	}
+
+	// changed, deleted and created keys in ascending order of versions
+	versions := map[string]uint64{
+		hexutil.Encode([]byte("a")): 4,
+		hexutil.Encode([]byte("b")): 1,
+		hexutil.Encode([]byte("d")): 2,
+	}
+	changes := diffKeyStates(versions, current)
+	assert.Equal(t, []Change{
+		{Key: []byte("b"), OldVersion: 1, NewVersion: 3, Deleted: true},
+		{Key: []byte("a"), OldVersion: 4, NewVersion: 5},
+		{Key: []byte("c"), OldVersion: 0, NewVersion: 7},
+	}, changes)
+
+	// nothing changed
+	assert.Empty(t, diffKeyStates(map[string]uint64{
+		hexutil.Encode([]byte("a")): 5,
+		hexutil.Encode([]byte("b")): 3,
+		hexutil.Encode([]byte("c")): 7,
+		hexutil.Encode([]byte("d")): 2,
+	}, current))
+}
@@ -0,0 +1,403 @@
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	zg_common "github.com/0glabs/0g-storage-client/common"
+	"github.com/0glabs/0g-storage-client/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	objectsDir   = "objects"
+	tempDir      = "tmp"
+	lockFilename = ".lock"
+)
+
+var (
+	ErrRootMismatch  = errors.New("Merkle root mismatch")
+	ErrEntryTooLarge = errors.New("File size exceeds the cache capacity")
+)
+
+// Entry is a file stored in the cache.
+type Entry struct {
+	Root       common.Hash `json:"root"`
+	Size       int64       `json:"size"`
+	LastAccess time.Time   `json:"lastAccess"`
+}
+
+// Cache is a content-addressed on-disk cache of downloaded files keyed by the data root.
+//
+// Files are only admitted after their merkle root is recomputed and matched, and the least
+// recently used files are evicted once the total size exceeds the capacity. All operations
+// are guarded by a file lock, so that the same cache directory could be shared by multiple
+// processes.
+type Cache struct {
+	dir     string
+	maxSize int64 // 0 means unlimited
+
+	logger *logrus.Logger
+}
+
+// New opens the cache in the specified directory, which will be created if not exists.
+// The maxSize is the capacity in bytes, and 0 means unlimited.
+func New(dir string, maxSize int64, opts ...zg_common.LogOption) (*Cache, error) {
+	if maxSize < 0 {
+		return nil, errors.New("cache size should not be negative")
+	}
+
+	for _, subdir := range []string{objectsDir, tempDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), os.ModePerm); err != nil {
+			return nil, errors.WithMessagef(err, "Failed to create cache directory %v", dir)
+		}
+	}
+
+	return &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		logger:  zg_common.NewLogger(opts...),
+	}, nil
+}
+
+// Dir returns the cache directory.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// MaxSize returns the cache capacity in bytes, and 0 means unlimited.
+func (c *Cache) MaxSize() int64 {
+	return c.maxSize
+}
+
+func (c *Cache) path(root common.Hash) string {
+	return filepath.Join(c.dir, objectsDir, root.Hex())
+}
+
+// lock acquires a new file lock, which conflicts with locks acquired in the same process as well.
+func (c *Cache) lock(exclusive bool) (*flock.Flock, error) {
+	fileLock := flock.New(filepath.Join(c.dir, lockFilename))
+
+	var err error
+	if exclusive {
+		err = fileLock.Lock()
+	} else {
+		err = fileLock.RLock()
+	}
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to lock cache directory")
+	}
+
+	return fileLock, nil
+}
+
+// Has checks whether the file of specified root is cached.
+func (c *Cache) Has(root common.Hash) (bool, error) {
+	fileLock, err := c.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer fileLock.Unlock()
+
+	_, err = os.Stat(c.path(root))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// CopyTo copies the cached file of specified root to the given filename, and returns false if not cached.
+func (c *Cache) CopyTo(root common.Hash, filename string) (bool, error) {
+	fileLock, err := c.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer fileLock.Unlock()
+
+	path := c.path(root)
+
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errors.WithMessage(err, "Failed to open cached file")
+	}
+	defer src.Close()
+
+	// update the last access time for LRU eviction
+	now := time.Now()
+	if err = os.Chtimes(path, now, now); err != nil {
+		c.logger.WithError(err).WithField("root", root).Debug("Failed to update access time of cached file")
+	}
+
+	if err = copyFile(src, filename); err != nil {
+		return false, err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"root":     root,
+		"filename": filename,
+	}).Debug("Cache hit")
+
+	return true, nil
+}
+
+// Put adds the specified file into cache. The merkle root is recomputed and must match the given root.
+func (c *Cache) Put(root common.Hash, filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to open file")
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to stat file")
+	}
+
+	if c.maxSize > 0 && info.Size() > c.maxSize {
+		return ErrEntryTooLarge
+	}
+
+	// copy into temp file at first, so that the verified content could not be changed any more
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, tempDir), root.Hex()+".*")
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create temp file")
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return errors.WithMessage(err, "Failed to copy file into cache")
+	}
+
+	actual, err := core.MerkleRoot(tmpName)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to compute merkle root")
+	}
+
+	if actual != root {
+		return errors.WithMessagef(ErrRootMismatch, "expected = %v, actual = %v", root, actual)
+	}
+
+	fileLock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	if err = os.Rename(tmpName, c.path(root)); err != nil {
+		return errors.WithMessage(err, "Failed to move file into cache")
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"root": root,
+		"size": info.Size(),
+	}).Debug("File added into cache")
+
+	if c.maxSize > 0 {
+		if _, err = c.evict(c.maxSize, root); err != nil {
+			return errors.WithMessage(err, "Failed to evict cached files")
+		}
+	}
+
+	return nil
+}
+
+// Remove removes the file of specified root from cache if any.
+func (c *Cache) Remove(root common.Hash) error {
+	fileLock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	if err = os.Remove(c.path(root)); err != nil && !os.IsNotExist(err) {
+		return errors.WithMessage(err, "Failed to remove cached file")
+	}
+
+	return nil
+}
+
+// List returns all cached files, ordered by last access time descending.
+func (c *Cache) List() ([]Entry, error) {
+	fileLock, err := c.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock()
+
+	return c.entries()
+}
+
+// Prune evicts the least recently used files until the total size is not greater than maxSize,
+// and returns the evicted files.
+func (c *Cache) Prune(maxSize int64) ([]Entry, error) {
+	fileLock, err := c.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock()
+
+	return c.evict(maxSize)
+}
+
+// Verify recomputes the merkle root of all cached files, and removes the corrupted ones.
+func (c *Cache) Verify() ([]Entry, error) {
+	fileLock, err := c.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var corrupted []Entry
+
+	for _, entry := range entries {
+		path := c.path(entry.Root)
+
+		if actual, err := core.MerkleRoot(path); err == nil && actual == entry.Root {
+			continue
+		}
+
+		c.logger.WithField("root", entry.Root).Warn("Remove corrupted file from cache")
+
+		if err = os.Remove(path); err != nil {
+			return corrupted, errors.WithMessage(err, "Failed to remove corrupted file")
+		}
+
+		corrupted = append(corrupted, entry)
+	}
+
+	return corrupted, nil
+}
+
+// entries returns all cached files ordered by last access time descending, requires lock held.
+func (c *Cache) entries() ([]Entry, error) {
+	files, err := os.ReadDir(filepath.Join(c.dir, objectsDir))
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to read cache directory")
+	}
+
+	var entries []Entry
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || len(name) != 2*common.HashLength+2 {
+			continue
+		}
+
+		info, err := file.Info()
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Failed to stat cached file %v", name)
+		}
+
+		entries = append(entries, Entry{
+			Root:       common.HexToHash(name),
+			Size:       info.Size(),
+			LastAccess: info.ModTime(),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastAccess.After(entries[j].LastAccess)
+	})
+
+	return entries, nil
+}
+
+// evict removes the least recently used files until the total size is not greater than maxSize,
+// except the pinned ones. Requires exclusive lock held.
+func (c *Cache) evict(maxSize int64, pinned ...common.Hash) ([]Entry, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var evicted []Entry
+
+	for i := len(entries) - 1; i >= 0 && total > maxSize; i-- {
+		if isPinned(entries[i].Root, pinned) {
+			continue
+		}
+
+		if err = os.Remove(c.path(entries[i].Root)); err != nil && !os.IsNotExist(err) {
+			return evicted, errors.WithMessage(err, "Failed to remove cached file")
+		}
+
+		c.logger.WithField("root", entries[i].Root).Debug("File evicted from cache")
+
+		total -= entries[i].Size
+		evicted = append(evicted, entries[i])
+	}
+
+	return evicted, nil
+}
+
+func isPinned(root common.Hash, pinned []common.Hash) bool {
+	for _, v := range pinned {
+		if v == root {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyFile copies to a temp file in the same directory and then renames to filename,
+// so that a partially copied file will never be observed.
+func copyFile(src io.Reader, filename string) error {
+	dst, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create temp file")
+	}
+	tmpName := dst.Name()
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmpName, 0644)
+	}
+
+	if err == nil {
+		err = os.Rename(tmpName, filename)
+	}
+
+	if err != nil {
+		os.Remove(tmpName)
+		return errors.WithMessage(err, "Failed to copy cached file")
+	}
+
+	return nil
+}
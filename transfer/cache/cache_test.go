@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0glabs/0g-storage-client/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestFile(t *testing.T, dir, name string, size int) (string, common.Hash) {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	filename := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(filename, data, 0644))
+
+	root, err := core.MerkleRoot(filename)
+	assert.NoError(t, err)
+
+	return filename, root
+}
+
+func TestCachePutAndCopy(t *testing.T) {
+	tmp := t.TempDir()
+
+	c, err := New(filepath.Join(tmp, "cache"), 0)
+	assert.NoError(t, err)
+
+	filename, root := createTestFile(t, tmp, "file", 1000)
+
+	// root mismatch
+	assert.ErrorIs(t, c.Put(common.Hash{1}, filename), ErrRootMismatch)
+
+	// cache missed
+	hit, err := c.CopyTo(root, filepath.Join(tmp, "copied"))
+	assert.NoError(t, err)
+	assert.False(t, hit)
+
+	assert.NoError(t, c.Put(root, filename))
+
+	hit, err = c.CopyTo(root, filepath.Join(tmp, "copied"))
+	assert.NoError(t, err)
+	assert.True(t, hit)
+
+	copied, err := core.MerkleRoot(filepath.Join(tmp, "copied"))
+	assert.NoError(t, err)
+	assert.Equal(t, root, copied)
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, root, entries[0].Root)
+	assert.Equal(t, int64(1000), entries[0].Size)
+}
+
+func TestCacheEviction(t *testing.T) {
+	tmp := t.TempDir()
+
+	c, err := New(filepath.Join(tmp, "cache"), 2500)
+	assert.NoError(t, err)
+
+	file1, root1 := createTestFile(t, tmp, "file1", 1000)
+	file2, root2 := createTestFile(t, tmp, "file2", 1000)
+	file3, root3 := createTestFile(t, tmp, "file3", 1000)
+	file4, _ := createTestFile(t, tmp, "file4", 3000)
+
+	assert.NoError(t, c.Put(root1, file1))
+	assert.NoError(t, c.Put(root2, file2))
+
+	// make file1 the most recently used
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(c.path(root2), past, past))
+	hit, err := c.CopyTo(root1, filepath.Join(tmp, "copied"))
+	assert.NoError(t, err)
+	assert.True(t, hit)
+
+	// file2 evicted
+	assert.NoError(t, c.Put(root3, file3))
+
+	for root, expected := range map[common.Hash]bool{root1: true, root2: false, root3: true} {
+		cached, err := c.Has(root)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, cached)
+	}
+
+	// too large to cache
+	assert.ErrorIs(t, c.Put(common.Hash{}, file4), ErrEntryTooLarge)
+
+	evicted, err := c.Prune(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(evicted))
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCacheVerify(t *testing.T) {
+	tmp := t.TempDir()
+
+	c, err := New(filepath.Join(tmp, "cache"), 0)
+	assert.NoError(t, err)
+
+	file1, root1 := createTestFile(t, tmp, "file1", 1000)
+	file2, root2 := createTestFile(t, tmp, "file2", 1000)
+
+	assert.NoError(t, c.Put(root1, file1))
+	assert.NoError(t, c.Put(root2, file2))
+
+	// corrupt file2
+	assert.NoError(t, os.WriteFile(c.path(root2), []byte("corrupted"), 0644))
+
+	corrupted, err := c.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(corrupted))
+	assert.Equal(t, root2, corrupted[0].Root)
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, root1, entries[0].Root)
+}
@@ -0,0 +1,184 @@
+package dir
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// TarFileFunc is called for each regular file with content in tar archive, and returns the merkle root
+// of the file. Note, the reader is only valid before the function returns.
+type TarFileFunc func(relpath string, size int64, r io.Reader) (common.Hash, error)
+
+// BuildFileTreeFromTar builds a file tree by reading entries of a tar archive sequentially, which is
+// identical to the file tree built from the extracted directory.
+//
+// Directories implied by entry paths are created automatically, hard links are resolved as regular
+// files with the same content, and later entries override earlier ones with the same path.
+func BuildFileTreeFromTar(r io.Reader, fileFunc TarFileFunc) (*FsNode, error) {
+	root := newTarDir()
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to read tar archive")
+		}
+
+		relpath, err := cleanTarPath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(relpath) == 0 {
+			// root directory
+			continue
+		}
+
+		parent, name := path.Split(relpath)
+		var node *FsNode
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if _, err = root.mkdirAll(relpath); err != nil {
+				return nil, err
+			}
+			continue
+		case tar.TypeSymlink:
+			node = NewSymbolicFsNode(name, header.Linkname)
+		case tar.TypeReg, tar.TypeRegA:
+			if header.Size == 0 {
+				node = NewFileFsNode(name, common.Hash{}, 0)
+				break
+			}
+
+			hash, err := fileFunc(relpath, header.Size, tr)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to process file %s", relpath)
+			}
+
+			node = NewFileFsNode(name, hash, header.Size)
+		case tar.TypeLink:
+			target, err := root.lookupFile(header.Linkname)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "invalid hard link %s", relpath)
+			}
+
+			node = NewFileFsNode(name, common.HexToHash(target.Root), target.Size)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			return nil, errors.Errorf("unsupported file type of %s", relpath)
+		}
+
+		dir, err := root.mkdirAll(strings.TrimSuffix(parent, "/"))
+		if err != nil {
+			return nil, err
+		}
+
+		delete(dir.dirs, name)
+		dir.files[name] = node
+	}
+
+	return root.build("/"), nil
+}
+
+// cleanTarPath returns the normalized relative path of tar entry, and empty string for root directory.
+func cleanTarPath(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimLeft(name, "/"))
+
+	if cleaned == "." {
+		return "", nil
+	}
+
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.Errorf("invalid path %s in tar archive", name)
+	}
+
+	return cleaned, nil
+}
+
+// tarDir is a mutable directory node used to build file tree from tar archive.
+type tarDir struct {
+	dirs  map[string]*tarDir
+	files map[string]*FsNode
+}
+
+func newTarDir() *tarDir {
+	return &tarDir{
+		dirs:  make(map[string]*tarDir),
+		files: make(map[string]*FsNode),
+	}
+}
+
+// mkdirAll returns the directory of specified relative path, and creates it if not exists.
+func (dir *tarDir) mkdirAll(relpath string) (*tarDir, error) {
+	if len(relpath) == 0 {
+		return dir, nil
+	}
+
+	current := dir
+
+	for _, name := range strings.Split(relpath, "/") {
+		if _, ok := current.files[name]; ok {
+			return nil, errors.Errorf("cannot create directory %s: %s is not a directory", relpath, name)
+		}
+
+		next, ok := current.dirs[name]
+		if !ok {
+			next = newTarDir()
+			current.dirs[name] = next
+		}
+
+		current = next
+	}
+
+	return current, nil
+}
+
+// lookupFile finds the regular file of specified path in tar archive.
+func (dir *tarDir) lookupFile(name string) (*FsNode, error) {
+	relpath, err := cleanTarPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := dir
+	parts := strings.Split(relpath, "/")
+
+	for _, part := range parts[:len(parts)-1] {
+		if current = current.dirs[part]; current == nil {
+			return nil, errors.Errorf("path not found: '%s'", name)
+		}
+	}
+
+	node, ok := current.files[parts[len(parts)-1]]
+	if !ok || node.Type != FileTypeFile {
+		return nil, errors.Errorf("regular file not found: '%s'", name)
+	}
+
+	return node, nil
+}
+
+// build converts to immutable FsNode recursively.
+func (dir *tarDir) build(name string) *FsNode {
+	var entries []*FsNode
+
+	for subname, subdir := range dir.dirs {
+		entries = append(entries, subdir.build(subname))
+	}
+
+	for _, file := range dir.files {
+		entries = append(entries, file)
+	}
+
+	return NewDirFsNode(name, entries)
+}
@@ -0,0 +1,140 @@
+package dir_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/core"
+	"github.com/0glabs/0g-storage-client/transfer/dir"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTar archives the specified directory in the same way as `tar -C path -cf - .`
+func createTar(t *testing.T, root string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+
+		relpath, _ := filepath.Rel(root, path)
+		header.Name = "./" + filepath.ToSlash(relpath)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			_, err = tw.Write(data)
+			return err
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+
+	return &buf
+}
+
+func merkleRootFromReader(_ string, _ int64, r io.Reader) (common.Hash, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	iterdata, err := core.NewDataInMemory(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tree, err := core.MerkleTree(iterdata)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return tree.Root(), nil
+}
+
+func TestBuildFileTreeFromTar(t *testing.T) {
+	tempDir := t.TempDir()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(tempDir, "subdir", "empty"), os.ModePerm))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "file1.txt"), []byte("file1"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "subdir", "file2.txt"), bytes.Repeat([]byte("file2"), 100000), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "subdir", "empty.txt"), nil, 0644))
+	assert.NoError(t, os.Symlink("subdir/file2.txt", filepath.Join(tempDir, "link")))
+
+	expected, err := dir.BuildFileTree(tempDir)
+	assert.NoError(t, err)
+
+	actual, err := dir.BuildFileTreeFromTar(createTar(t, tempDir), merkleRootFromReader)
+	assert.NoError(t, err)
+	assert.True(t, expected.Equal(actual))
+
+	expectedData, err := expected.MarshalBinary()
+	assert.NoError(t, err)
+	actualData, err := actual.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData, actualData)
+}
+
+func TestBuildFileTreeFromTarWithLinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	// parent directory implied by file path
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "a/b/file.txt", Size: 5}))
+	_, err := tw.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "hardlink", Linkname: "a/b/file.txt"}))
+	assert.NoError(t, tw.Close())
+
+	tree, err := dir.BuildFileTreeFromTar(&buf, merkleRootFromReader)
+	assert.NoError(t, err)
+
+	file, err := tree.Locate("a/b/file.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), file.Size)
+
+	link, err := tree.Locate("hardlink")
+	assert.NoError(t, err)
+	assert.Equal(t, dir.FileTypeFile, link.Type)
+	assert.Equal(t, file.Root, link.Root)
+
+	// path outside of root directory
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "../outside/"}))
+	assert.NoError(t, tw.Close())
+
+	_, err = dir.BuildFileTreeFromTar(&buf, merkleRootFromReader)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package transfer
+
+import (
+	"github.com/0glabs/0g-storage-client/transfer/cache"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DownloadFromCache copies the file of specified root from local cache to the given filename,
+// and returns false if cache not enabled or missed.
+func DownloadFromCache(c *cache.Cache, root common.Hash, filename string) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	cached, err := c.Has(root)
+	if err != nil {
+		return false, errors.WithMessage(err, "Failed to check local cache")
+	}
+
+	if !cached {
+		return false, nil
+	}
+
+	// Check file existence before copying
+	if err = checkExistence(filename, root); err != nil {
+		return false, errors.WithMessage(err, "Failed to check file existence")
+	}
+
+	// file may be evicted in the meantime
+	if cached, err = c.CopyTo(root, filename); err != nil {
+		return false, errors.WithMessage(err, "Failed to copy file from local cache")
+	}
+
+	return cached, nil
+}
+
+// addToCache adds the downloaded and validated file into local cache if enabled.
+func addToCache(c *cache.Cache, root common.Hash, filename string, logger *logrus.Logger) {
+	if c == nil {
+		return
+	}
+
+	if err := c.Put(root, filename); err != nil {
+		logger.WithError(err).WithField("root", root).Warn("Failed to add downloaded file into local cache")
+	}
+}
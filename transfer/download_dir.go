@@ -13,7 +13,8 @@ import (
 
 // DownloadDir downloads files within a directory recursively from the ZeroGStorage network.
 // It first builds a file tree from the directory metadata, then downloads each file in the directory,
-// and finally seals the directory when the download is complete.
+// and finally seals the directory when the download is complete. Files available in the local cache
+// of downloader, if enabled, will not be downloaded from the network again.
 //
 // Parameters:
 //   - ctx:        Context for managing request timeouts and cancellations.
@@ -31,6 +31,8 @@ type segmentDownloader struct {
 
 	routines int
 
+	metrics *transferMetrics
+
 	logger *logrus.Logger
 }
 
@@ -59,6 +61,8 @@ func newSegmentDownloader(downloader *Downloader, info *node.FileInfo, shardConf
 
 		routines: downloader.routines,
 
+		metrics: downloader.metrics,
+
 		logger: downloader.logger,
 	}, nil
 }
@@ -85,8 +89,9 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 	root := downloader.file.Metadata().Root
 
 	var (
-		segment []byte
-		err     error
+		segment  []byte
+		err      error
+		attempts int
 	)
 
 	for i := 0; i < len(downloader.shardConfigs); i += 1 {
@@ -94,6 +99,12 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 		if (downloader.startSegmentIndex+segmentIndex)%downloader.shardConfigs[nodeIndex].NumShard != downloader.shardConfigs[nodeIndex].ShardId {
 			continue
 		}
+		// retry on the next node that holds the segment
+		if attempts > 0 {
+			downloader.metrics.addRetry()
+		}
+		attempts++
+
 		// try download from current node
 		if downloader.withProof {
 			segment, err = downloader.downloadWithProof(ctx, downloader.clients[nodeIndex], downloader.txSeq, root, startIndex, endIndex)
@@ -107,6 +118,7 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 				"segment":    fmt.Sprintf("%v/(%v-%v)", downloader.startSegmentIndex+segmentIndex, downloader.startSegmentIndex, downloader.endSegmentIndex),
 				"chunks":     fmt.Sprintf("[%v, %v)", startIndex, endIndex),
 			}).Error("Failed to download segment")
+			downloader.metrics.addNodeError(downloader.clients[nodeIndex].URL())
 			continue
 		}
 		if segment == nil {
@@ -115,6 +127,7 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 				"segment":    fmt.Sprintf("%v/(%v-%v)", downloader.startSegmentIndex+segmentIndex, downloader.startSegmentIndex, downloader.endSegmentIndex),
 				"chunks":     fmt.Sprintf("[%v, %v)", startIndex, endIndex),
 			}).Warn("segment not found")
+			downloader.metrics.addNodeError(downloader.clients[nodeIndex].URL())
 			continue
 		}
 		if len(segment)%core.DefaultChunkSize != 0 {
@@ -123,6 +136,7 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 				"segment":    fmt.Sprintf("%v/(%v-%v)", downloader.startSegmentIndex+segmentIndex, downloader.startSegmentIndex, downloader.endSegmentIndex),
 				"chunks":     fmt.Sprintf("[%v, %v)", startIndex, endIndex),
 			}).Warn("invalid segment length")
+			downloader.metrics.addNodeError(downloader.clients[nodeIndex].URL())
 			continue
 		}
 		if downloader.logger.IsLevelEnabled(logrus.DebugLevel) {
@@ -141,6 +155,7 @@ func (downloader *segmentDownloader) ParallelDo(ctx context.Context, routine, ta
 				segment = segment[0 : len(segment)-int(paddings)]
 			}
 		}
+		downloader.metrics.addBytes(len(segment))
 		return segment, nil
 	}
 	return nil, fmt.Errorf("failed to download segment %v", segmentIndex)
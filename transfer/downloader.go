@@ -6,10 +6,12 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"time"
 
 	zg_common "github.com/0glabs/0g-storage-client/common"
 	"github.com/0glabs/0g-storage-client/core"
 	"github.com/0glabs/0g-storage-client/node"
+	"github.com/0glabs/0g-storage-client/transfer/cache"
 	"github.com/0glabs/0g-storage-client/transfer/download"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -33,6 +35,10 @@ type Downloader struct {
 
 	routines int
 
+	cache *cache.Cache
+
+	metrics *transferMetrics
+
 	logger *logrus.Logger
 }
 
@@ -54,6 +60,18 @@ func (downloader *Downloader) WithRoutines(routines int) *Downloader {
 	return downloader
 }
 
+// WithCache enables the local download cache, which will be consulted before downloading from storage nodes.
+func (downloader *Downloader) WithCache(c *cache.Cache) *Downloader {
+	downloader.cache = c
+	return downloader
+}
+
+// WithMetrics enables to collect download throughput, retries and errors of storage nodes.
+func (downloader *Downloader) WithMetrics(metrics *Metrics) *Downloader {
+	downloader.metrics = metrics.downloads()
+	return downloader
+}
+
 func (downloader *Downloader) DownloadFragments(ctx context.Context, roots []string, filename string, withProof bool) error {
 	outFile, err := os.Create(filename)
 	if err != nil {
@@ -90,6 +108,11 @@ func (downloader *Downloader) DownloadFragments(ctx context.Context, roots []str
 func (downloader *Downloader) Download(ctx context.Context, root, filename string, withProof bool) error {
 	hash := common.HexToHash(root)
 
+	// Try to download from local cache at first
+	if hit, err := DownloadFromCache(downloader.cache, hash, filename); err != nil || hit {
+		return err
+	}
+
 	// Query file info from storage node
 	info, err := downloader.queryFile(ctx, hash)
 	if err != nil {
@@ -97,7 +120,7 @@ func (downloader *Downloader) Download(ctx context.Context, root, filename strin
 	}
 
 	// Check file existence before downloading
-	if err = downloader.checkExistence(filename, hash); err != nil {
+	if err = checkExistence(filename, hash); err != nil {
 		return errors.WithMessage(err, "Failed to check file existence")
 	}
 
@@ -111,6 +134,8 @@ func (downloader *Downloader) Download(ctx context.Context, root, filename strin
 		return errors.WithMessage(err, "Failed to validate downloaded file")
 	}
 
+	addToCache(downloader.cache, hash, filename, downloader.logger)
+
 	return nil
 }
 
@@ -132,7 +157,7 @@ func (downloader *Downloader) queryFile(ctx context.Context, root common.Hash) (
 	return
 }
 
-func (downloader *Downloader) checkExistence(filename string, hash common.Hash) error {
+func checkExistence(filename string, hash common.Hash) error {
 	file, err := core.Open(filename)
 	if os.IsNotExist(err) {
 		return nil
@@ -144,12 +169,12 @@ func (downloader *Downloader) checkExistence(filename string, hash common.Hash)
 
 	defer file.Close()
 
-	tree, err := core.MerkleTree(file)
+	root, err := core.MerkleRootOf(file)
 	if err != nil {
-		return errors.WithMessage(err, "Failed to create file merkle tree")
+		return errors.WithMessage(err, "Failed to compute file merkle root")
 	}
 
-	if tree.Root().Hex() == hash.Hex() {
+	if root == hash {
 		return ErrFileAlreadyExists
 	}
 
@@ -175,10 +200,14 @@ func (downloader *Downloader) downloadFile(ctx context.Context, filename string,
 		return errors.WithMessage(err, "Failed to create segment downloader")
 	}
 
+	start := time.Now()
+
 	if err = sd.Download(ctx); err != nil {
 		return errors.WithMessage(err, "Failed to download file")
 	}
 
+	downloader.metrics.observeDuration(start)
+
 	if err := file.Seal(); err != nil {
 		return errors.WithMessage(err, "Failed to seal downloading file")
 	}
@@ -199,12 +228,12 @@ func (downloader *Downloader) validateDownloadFile(root, filename string, fileSi
 		return errors.Errorf("File size mismatch: expected = %v, downloaded = %v", fileSize, file.Size())
 	}
 
-	tree, err := core.MerkleTree(file)
+	actual, err := core.MerkleRootOf(file)
 	if err != nil {
-		return errors.WithMessage(err, "Failed to create merkle tree")
+		return errors.WithMessage(err, "Failed to compute merkle root")
 	}
 
-	if rootHex := tree.Root().Hex(); rootHex != root {
+	if rootHex := actual.Hex(); rootHex != root {
 		return errors.Errorf("Merkle root mismatch, downloaded = %v", rootHex)
 	}
 
@@ -0,0 +1,227 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0glabs/0g-storage-client/transfer/dir"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ArchiveFormat is the archive format to export directory.
+type ArchiveFormat string
+
+const (
+	ArchiveFormatTar ArchiveFormat = "tar"
+	ArchiveFormatZip ArchiveFormat = "zip"
+)
+
+// Default permissions of exported archive entries, since file modes are not kept in directory metadata.
+const (
+	archiveFileMode    = 0644
+	archiveDirMode     = 0755
+	archiveSymlinkMode = 0777
+)
+
+// ParseArchiveFormat parses the archive format from string.
+func ParseArchiveFormat(format string) (ArchiveFormat, error) {
+	switch v := ArchiveFormat(strings.ToLower(format)); v {
+	case ArchiveFormatTar, ArchiveFormatZip:
+		return v, nil
+	default:
+		return "", errors.Errorf("unsupported archive format %v", format)
+	}
+}
+
+// ExportDir exports a directory from the ZeroGStorage network as a tar or zip archive stream.
+// It first builds a file tree from the directory metadata, then downloads each file in the
+// directory and writes it into the archive one by one, so only a single file is kept on local
+// disk at any time.
+//
+// Parameters:
+//   - ctx:        Context for managing request timeouts and cancellations.
+//   - downloader: The interface responsible for downloading files from the ZeroGStorage network.
+//   - root:       The root hash of the directory to be exported.
+//   - format:     The archive format, either tar or zip.
+//   - w:          The writer that archive streamed to.
+//
+// Returns:
+//   - error: An error if any part of the download or archive process fails.
+func ExportDir(ctx context.Context, downloader IDownloader, root string, format ArchiveFormat, w io.Writer) error {
+	// Build a file tree from the directory metadata stored on the network.
+	tree, err := BuildFileTree(ctx, downloader, root, false)
+	if err != nil {
+		return errors.WithMessage(err, "failed to build file tree")
+	}
+
+	return ExportFileTree(ctx, downloader, tree, format, w)
+}
+
+// ExportFileTree exports the given file tree as a tar or zip archive stream. Entries are named
+// relative to the tree node, and prefixed with the node name unless it is the root directory.
+func ExportFileTree(ctx context.Context, downloader IDownloader, tree *dir.FsNode, format ArchiveFormat, w io.Writer) error {
+	var aw archiveWriter
+	switch format {
+	case ArchiveFormatTar:
+		aw = &tarArchiveWriter{tar.NewWriter(w)}
+	case ArchiveFormatZip:
+		aw = &zipArchiveWriter{zip.NewWriter(w)}
+	default:
+		return errors.Errorf("unsupported archive format %v", format)
+	}
+
+	// Temp folder to download files one by one
+	tmpDir, err := os.MkdirTemp("", "zg_export_*")
+	if err != nil {
+		return errors.WithMessage(err, "failed to create temp folder")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = tree.Traverse(func(node *dir.FsNode, relpath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(filepath.ToSlash(relpath), "/")
+		if len(name) == 0 {
+			return nil
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"node": node,
+			"name": name,
+		}).Debug("Adding file to archive")
+
+		switch node.Type {
+		case dir.FileTypeDirectory:
+			return aw.WriteDir(name)
+		case dir.FileTypeSymbolic:
+			return aw.WriteSymlink(name, node.Link)
+		case dir.FileTypeFile:
+			return exportFile(ctx, downloader, aw, node, name, tmpDir)
+		default:
+			return errors.Errorf("unsupported file type %v of %v", node.Type, name)
+		}
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed to add files into archive")
+	}
+
+	if err = aw.Close(); err != nil {
+		return errors.WithMessage(err, "failed to close archive")
+	}
+
+	return nil
+}
+
+// exportFile downloads the file into temp folder and then writes it into archive.
+func exportFile(ctx context.Context, downloader IDownloader, aw archiveWriter, node *dir.FsNode, name, tmpDir string) error {
+	if node.Size == 0 {
+		return aw.WriteFile(name, 0, strings.NewReader(""))
+	}
+
+	tmpfile := filepath.Join(tmpDir, node.Root)
+	if err := downloader.Download(ctx, node.Root, tmpfile, false); err != nil {
+		return errors.WithMessagef(err, "failed to download file %v", name)
+	}
+	defer os.Remove(tmpfile)
+
+	file, err := os.Open(tmpfile)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to open downloaded file %v", name)
+	}
+	defer file.Close()
+
+	return aw.WriteFile(name, node.Size, file)
+}
+
+type archiveWriter interface {
+	WriteDir(name string) error
+	WriteSymlink(name, link string) error
+	WriteFile(name string, size int64, r io.Reader) error
+	Close() error
+}
+
+type tarArchiveWriter struct {
+	*tar.Writer
+}
+
+func (w *tarArchiveWriter) WriteDir(name string) error {
+	return w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     archiveDirMode,
+	})
+}
+
+func (w *tarArchiveWriter) WriteSymlink(name, link string) error {
+	return w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: link,
+		Mode:     archiveSymlinkMode,
+	})
+}
+
+func (w *tarArchiveWriter) WriteFile(name string, size int64, r io.Reader) error {
+	err := w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     archiveFileMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w.Writer, r)
+	return err
+}
+
+type zipArchiveWriter struct {
+	*zip.Writer
+}
+
+func (w *zipArchiveWriter) WriteDir(name string) error {
+	header := &zip.FileHeader{Name: name + "/"}
+	header.SetMode(os.ModeDir | archiveDirMode)
+
+	_, err := w.CreateHeader(header)
+	return err
+}
+
+func (w *zipArchiveWriter) WriteSymlink(name, link string) error {
+	header := &zip.FileHeader{Name: name}
+	header.SetMode(os.ModeSymlink | archiveSymlinkMode)
+
+	// symbolic link target is stored as file content by convention
+	writer, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, link)
+	return err
+}
+
+func (w *zipArchiveWriter) WriteFile(name string, size int64, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+	header.SetMode(archiveFileMode)
+
+	writer, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, r)
+	return err
+}
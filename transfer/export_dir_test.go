@@ -0,0 +1,162 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/0glabs/0g-storage-client/transfer/dir"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// memDownloader downloads files from memory by root.
+type memDownloader struct {
+	files map[string][]byte
+}
+
+func (d *memDownloader) Download(ctx context.Context, root, filename string, withProof bool) error {
+	data, ok := d.files[root]
+	if !ok {
+		return errors.Errorf("file %v not found", root)
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+func (d *memDownloader) DownloadFragments(ctx context.Context, roots []string, filename string, withProof bool) error {
+	return errors.New("not supported")
+}
+
+// archiveEntry is an entry read back from archive, in which data is the link target for symbolic link.
+type archiveEntry struct {
+	kind string
+	data string
+}
+
+func newTestExportDir(t *testing.T) (*memDownloader, *dir.FsNode) {
+	fileA, fileB := common.HexToHash("0xa"), common.HexToHash("0xb")
+
+	tree := dir.NewDirFsNode("", []*dir.FsNode{
+		dir.NewFileFsNode("a.txt", fileA, 5),
+		dir.NewFileFsNode("empty.txt", common.Hash{}, 0),
+		dir.NewSymbolicFsNode("link", "sub/b.txt"),
+		dir.NewDirFsNode("sub", []*dir.FsNode{
+			dir.NewFileFsNode("b.txt", fileB, 5),
+			dir.NewDirFsNode("nested", nil),
+		}),
+	})
+
+	metadata, err := tree.MarshalBinary()
+	assert.NoError(t, err)
+
+	return &memDownloader{map[string][]byte{
+		"0xmeta":    metadata,
+		fileA.Hex(): []byte("hello"),
+		fileB.Hex(): []byte("world"),
+	}}, tree
+}
+
+func readTar(t *testing.T, r io.Reader) map[string]archiveEntry {
+	entries := make(map[string]archiveEntry)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		assert.NoError(t, err)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entries[header.Name] = archiveEntry{"dir", ""}
+		case tar.TypeSymlink:
+			entries[header.Name] = archiveEntry{"symlink", header.Linkname}
+		default:
+			data, err := io.ReadAll(tr)
+			assert.NoError(t, err)
+			entries[header.Name] = archiveEntry{"file", string(data)}
+		}
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string]archiveEntry {
+	entries := make(map[string]archiveEntry)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	for _, f := range zr.File {
+		if f.Mode().IsDir() {
+			entries[f.Name] = archiveEntry{"dir", ""}
+			continue
+		}
+
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+
+		if f.Mode()&os.ModeSymlink != 0 {
+			entries[f.Name] = archiveEntry{"symlink", string(content)}
+		} else {
+			entries[f.Name] = archiveEntry{"file", string(content)}
+		}
+	}
+
+	return entries
+}
+
+func TestExportDir(t *testing.T) {
+	downloader, _ := newTestExportDir(t)
+
+	expected := map[string]archiveEntry{
+		"a.txt":       {"file", "hello"},
+		"empty.txt":   {"file", ""},
+		"link":        {"symlink", "sub/b.txt"},
+		"sub/":        {"dir", ""},
+		"sub/b.txt":   {"file", "world"},
+		"sub/nested/": {"dir", ""},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportDir(context.Background(), downloader, "0xmeta", ArchiveFormatTar, &buf))
+	assert.Equal(t, expected, readTar(t, &buf))
+
+	buf.Reset()
+	assert.NoError(t, ExportDir(context.Background(), downloader, "0xmeta", ArchiveFormatZip, &buf))
+	assert.Equal(t, expected, readZip(t, buf.Bytes()))
+
+	// file not found
+	downloader.files = map[string][]byte{"0xmeta": downloader.files["0xmeta"]}
+	assert.Error(t, ExportDir(context.Background(), downloader, "0xmeta", ArchiveFormatTar, io.Discard))
+}
+
+func TestExportFileTree(t *testing.T) {
+	downloader, tree := newTestExportDir(t)
+
+	sub, err := tree.Locate("sub")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportFileTree(context.Background(), downloader, sub, ArchiveFormatTar, &buf))
+	assert.Equal(t, map[string]archiveEntry{
+		"sub/":        {"dir", ""},
+		"sub/b.txt":   {"file", "world"},
+		"sub/nested/": {"dir", ""},
+	}, readTar(t, &buf))
+
+	assert.Error(t, ExportFileTree(context.Background(), downloader, sub, "rar", io.Discard))
+
+	_, err = ParseArchiveFormat("ZIP")
+	assert.NoError(t, err)
+	_, err = ParseArchiveFormat("rar")
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package transfer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics collects the throughput, retries and errors of storage nodes to upload and download files, which
+// could be shared by multiple uploaders and downloaders.
+type Metrics struct {
+	upload   *transferMetrics
+	download *transferMetrics
+}
+
+// transferMetrics collects metrics in single direction, i.e. upload or download.
+type transferMetrics struct {
+	bytes      prometheus.Counter
+	duration   prometheus.Histogram
+	retries    prometheus.Counter
+	nodeErrors *prometheus.CounterVec // labels: node
+}
+
+// NewMetrics creates metrics of uploading and downloading, and registers them in the specified registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	metrics := Metrics{
+		upload:   newTransferMetrics("upload"),
+		download: newTransferMetrics("download"),
+	}
+
+	for _, m := range []*transferMetrics{metrics.upload, metrics.download} {
+		for _, c := range []prometheus.Collector{m.bytes, m.duration, m.retries, m.nodeErrors} {
+			if err := registerer.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &metrics, nil
+}
+
+func newTransferMetrics(direction string) *transferMetrics {
+	return &transferMetrics{
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "zgs_client_" + direction + "_bytes_total",
+			Help: "Number of segment bytes transferred to " + direction + " files.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "zgs_client_" + direction + "_duration_seconds",
+			Help:    "Duration to " + direction + " segments of file in seconds.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "zgs_client_" + direction + "_retries_total",
+			Help: "Number of retries to " + direction + " segments.",
+		}),
+		nodeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "zgs_client_" + direction + "_node_errors_total",
+			Help: "Number of errors from storage nodes to " + direction + " segments.",
+		}, []string{"node"}),
+	}
+}
+
+func (m *Metrics) uploads() *transferMetrics {
+	if m == nil {
+		return nil
+	}
+
+	return m.upload
+}
+
+func (m *Metrics) downloads() *transferMetrics {
+	if m == nil {
+		return nil
+	}
+
+	return m.download
+}
+
+// Note, all methods below are no-op if metrics not enabled.
+
+func (m *transferMetrics) addBytes(n int) {
+	if m != nil {
+		m.bytes.Add(float64(n))
+	}
+}
+
+func (m *transferMetrics) observeDuration(start time.Time) {
+	if m != nil {
+		m.duration.Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *transferMetrics) addRetry() {
+	if m != nil {
+		m.retries.Inc()
+	}
+}
+
+func (m *transferMetrics) addNodeError(url string) {
+	if m != nil {
+		m.nodeErrors.WithLabelValues(url).Inc()
+	}
+}
@@ -0,0 +1,100 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/0glabs/0g-storage-client/core"
+	"github.com/0glabs/0g-storage-client/transfer/dir"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// tarSpoolThreshold is the max size of file in tar archive to upload from memory, otherwise,
+// the file will be spooled to a temp file on local disk.
+const tarSpoolThreshold = 16 * 1024 * 1024
+
+// UploadTar uploads a directory packed as tar archive, which reads entries sequentially and uploads
+// regular files as they are encountered. Finally, the directory metadata is uploaded, which is
+// byte-identical to uploading the extracted directory via `UploadDir`.
+func (uploader *Uploader) UploadTar(ctx context.Context, r io.Reader, option ...UploadOption) (txnHash, rootHash common.Hash, _ error) {
+	var numFiles int
+
+	// Build the file tree representation of the directory while uploading files.
+	root, err := dir.BuildFileTreeFromTar(r, func(relpath string, size int64, r io.Reader) (common.Hash, error) {
+		txhash, root, err := uploader.uploadTarFile(ctx, size, r, option...)
+		if err != nil {
+			return common.Hash{}, err
+		}
+
+		numFiles++
+
+		logrus.WithFields(logrus.Fields{
+			"txnHash": txhash,
+			"root":    root,
+			"path":    relpath,
+		}).Info("File uploaded successfully")
+
+		return root, nil
+	})
+	if err != nil {
+		return txnHash, rootHash, errors.WithMessage(err, "failed to upload files in tar archive")
+	}
+
+	logrus.Infof("Total %d files uploaded", numFiles)
+
+	tdata, err := root.MarshalBinary()
+	if err != nil {
+		return txnHash, rootHash, errors.WithMessage(err, "failed to encode file tree")
+	}
+
+	// Create an in-memory data object from the encoded file tree.
+	iterdata, err := core.NewDataInMemory(tdata)
+	if err != nil {
+		return txnHash, rootHash, errors.WithMessage(err, "failed to create `IterableData` in memory")
+	}
+
+	// Finally, upload the directory metadata
+	txnHash, rootHash, err = uploader.Upload(ctx, iterdata, option...)
+	if err != nil {
+		err = errors.WithMessage(err, "failed to upload directory metadata")
+	}
+
+	return txnHash, rootHash, err
+}
+
+// uploadTarFile uploads a regular file in tar archive from memory, or spools it to a temp file if too large.
+func (uploader *Uploader) uploadTarFile(ctx context.Context, size int64, r io.Reader, option ...UploadOption) (common.Hash, common.Hash, error) {
+	if size <= tarSpoolThreshold {
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return common.Hash{}, common.Hash{}, errors.WithMessage(err, "failed to read file content")
+		}
+
+		iterdata, err := core.NewDataInMemory(data)
+		if err != nil {
+			return common.Hash{}, common.Hash{}, errors.WithMessage(err, "failed to create `IterableData` in memory")
+		}
+
+		return uploader.Upload(ctx, iterdata, option...)
+	}
+
+	tmpfile, err := os.CreateTemp("", "zg_upload_tar_*")
+	if err != nil {
+		return common.Hash{}, common.Hash{}, errors.WithMessage(err, "failed to create temp file")
+	}
+	defer os.Remove(tmpfile.Name())
+
+	_, err = io.CopyN(tmpfile, r, size)
+	if closeErr := tmpfile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return common.Hash{}, common.Hash{}, errors.WithMessage(err, "failed to spool file content")
+	}
+
+	return uploader.UploadFile(ctx, tmpfile.Name(), option...)
+}
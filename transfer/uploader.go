@@ -94,6 +94,7 @@ type Uploader struct {
 	market   *contract.Market       // market contract instance
 	clients  []*node.ZgsClient      // 0g storage clients
 	routines int                    // number of go routines for uploading
+	metrics  *transferMetrics       // optional metrics
 	logger   *logrus.Logger         // logger
 }
 
@@ -173,6 +174,12 @@ func (uploader *Uploader) WithRoutines(routines int) *Uploader {
 	return uploader
 }
 
+// WithMetrics enables to collect upload throughput, retries and errors of storage nodes.
+func (uploader *Uploader) WithMetrics(metrics *Metrics) *Uploader {
+	uploader.metrics = metrics.uploads()
+	return uploader
+}
+
 // SplitableUpload submit data to 0g storage contract and large data will be splited to reduce padding cost.
 func (uploader *Uploader) SplitableUpload(ctx context.Context, data core.IterableData, fragmentSize int64, option ...UploadOption) ([]common.Hash, []common.Hash, error) {
 	if fragmentSize < core.DefaultChunkSize {
@@ -275,7 +282,7 @@ func (uploader *Uploader) BatchUpload(ctx context.Context, datas []core.Iterable
 			}).Info("Data prepared to upload")
 
 			// Calculate file merkle root.
-			tree, err := core.MerkleTree(data)
+			tree, err := core.MerkleTreeContext(ctx, data)
 			if err != nil {
 				errs <- errors.WithMessage(err, "Failed to create data merkle tree")
 				return
@@ -417,7 +424,7 @@ func (uploader *Uploader) Upload(ctx context.Context, data core.IterableData, op
 	}).Info("Data prepared to upload")
 
 	// Calculate file merkle root.
-	tree, err := core.MerkleTree(data)
+	tree, err := core.MerkleTreeContext(ctx, data)
 	if err != nil {
 		return common.Hash{}, common.Hash{}, errors.WithMessage(err, "Failed to create data merkle tree")
 	}
@@ -495,7 +502,7 @@ func (uploader *Uploader) UploadDir(ctx context.Context, folder string, option .
 	}
 
 	// Generate the Merkle tree from the in-memory data.
-	mtree, err := core.MerkleTree(iterdata)
+	mtree, err := core.MerkleTreeContext(ctx, iterdata)
 	if err != nil {
 		return txnHash, rootHash, errors.WithMessage(err, "failed to create merkle tree")
 	}
@@ -733,6 +740,7 @@ func (uploader *Uploader) newSegmentUploader(ctx context.Context, info *node.Fil
 		clients:  uploader.clients,
 		tasks:    tasks,
 		taskSize: taskSize,
+		metrics:  uploader.metrics,
 		logger:   uploader.logger,
 	}, nil
 }
@@ -764,6 +772,8 @@ func (uploader *Uploader) uploadFile(ctx context.Context, info *node.FileInfo, d
 		return err
 	}
 
+	uploader.metrics.observeDuration(stageTimer)
+
 	uploader.logger.WithFields(logrus.Fields{
 		"duration": time.Since(stageTimer),
 		"segNum":   data.NumSegments(),
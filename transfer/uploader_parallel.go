@@ -25,6 +25,7 @@ type segmentUploader struct {
 	clients  []*node.ZgsClient
 	tasks    []*uploadTask
 	taskSize uint
+	metrics  *transferMetrics
 	logger   *logrus.Logger
 }
 
@@ -97,8 +98,10 @@ func (uploader *segmentUploader) ParallelDo(ctx context.Context, routine int, ta
 		"to_node":        uploader.clients[uploadTask.clientIndex].URL(),
 	}).Debug("Segments uploading")
 
+	client := uploader.clients[uploadTask.clientIndex]
+
 	for i := 0; i < tooManyDataRetries; i++ {
-		_, err := uploader.clients[uploadTask.clientIndex].UploadSegmentsByTxSeq(ctx, segments, uploader.txSeq)
+		_, err := client.UploadSegmentsByTxSeq(ctx, segments, uploader.txSeq)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"taskId":      task,
@@ -108,11 +111,21 @@ func (uploader *segmentUploader) ParallelDo(ctx context.Context, routine int, ta
 			}).Error("Failed to upload segments", err)
 
 		}
-		if err == nil || isDuplicateError(err.Error()) {
+		if err == nil {
+			for _, v := range segments {
+				uploader.metrics.addBytes(len(v.Data))
+			}
+			break
+		}
+
+		if isDuplicateError(err.Error()) {
 			break
 		}
 
+		uploader.metrics.addNodeError(client.URL())
+
 		if isTooManyDataError(err.Error()) && i < tooManyDataRetries-1 {
+			uploader.metrics.addRetry()
 			time.Sleep(10 * time.Second)
 			continue
 		}